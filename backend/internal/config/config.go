@@ -75,10 +75,39 @@ func LoadConfig() error {
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", "5432")
 	viper.SetDefault("jwt.secret", "your-secret-key") // Default fallback
+	viper.SetDefault("verse_of_the_day.repeat_window_days", 30)
+	viper.SetDefault("llm.provider", "openai")
+	viper.SetDefault("llm.timeout_seconds", 30)
+	viper.SetDefault("llm.breaker_failure_threshold", 5)
+	viper.SetDefault("llm.breaker_open_seconds", 30)
+	viper.SetDefault("companion.max_context_tokens", 3000)
+	viper.SetDefault("companion.max_reply_tokens", 500)
+	viper.SetDefault("safety.llm_moderation", false)
+	viper.SetDefault("safety.default_locale", "en-US")
+	viper.SetDefault("ai_usage.daily_token_limit", 20000)
+	viper.SetDefault("ai_usage.monthly_token_limit", 300000)
+	viper.SetDefault("ai_usage.global_monthly_budget_usd", 100)
+	viper.SetDefault("privacy.default_sharing", "aggregates")
+	viper.SetDefault("ai_cache.enabled", true)
+	viper.SetDefault("ai_cache.ttl_hours", 168)
+	viper.SetDefault("ai_cache.semantic", false)
+	viper.SetDefault("ai_cache.similarity_threshold", 0.92)
+	viper.SetDefault("ai_cache.max_input_length", 200)
+	viper.SetDefault("jobs.workers", 2)
+	viper.SetDefault("jobs.poll_interval_seconds", 2)
+	viper.SetDefault("jobs.timeout_seconds", 120)
+	viper.SetDefault("export.brand_name", "ArmourUp")
+	viper.SetDefault("export.brand_tagline", "Put on the full armour of God")
+	viper.SetDefault("export.brand_color", "#1d4ed8")
 
 	// Bind environment variables
 	// Viper will automatically map ARMOURUP_JWT_SECRET to jwt.secret
 	viper.BindEnv("jwt.secret", "ARMOURUP_JWT_SECRET")
+	viper.BindEnv("llm.provider", "ARMOURUP_LLM_PROVIDER")
+	viper.BindEnv("llm.base_url", "ARMOURUP_LLM_BASE_URL")
+	viper.BindEnv("llm.model", "ARMOURUP_LLM_MODEL")
+	viper.BindEnv("llm.insights_model", "ARMOURUP_LLM_INSIGHTS_MODEL")
+	viper.BindEnv("ai_usage.global_monthly_budget_usd", "ARMOURUP_AI_GLOBAL_MONTHLY_BUDGET_USD")
 
 	// Read config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -8,14 +8,24 @@ import (
 	"os"
 	"time"
 
+	"armourup/internal/domain/aicache"
+	"armourup/internal/domain/collection"
+	"armourup/internal/domain/companion"
 	"armourup/internal/domain/encouragement"
 	"armourup/internal/domain/gratitude"
 	"armourup/internal/domain/insights"
 	"armourup/internal/domain/journal"
+	"armourup/internal/domain/memory"
 	"armourup/internal/domain/mood"
 	"armourup/internal/domain/prayer"
 	"armourup/internal/domain/prayerchain"
+	"armourup/internal/domain/privacy"
+	"armourup/internal/domain/readingplan"
+	"armourup/internal/domain/safety"
+	"armourup/internal/domain/usage"
 	"armourup/internal/domain/user"
+	"armourup/internal/domain/verseoftheday"
+	"armourup/internal/jobs"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -126,21 +136,32 @@ func InitDB() (*gorm.DB, error) {
 // AutoMigrate automatically creates or updates database tables based on the provided models.
 // It handles the following models:
 // - User
-// - Encouragement
+// - Encouragement, StruggleLog
 // - JournalEntry
 // - PrayerRequest
 // - PrayerLog
 // - PrayerChain
 // - ChainMember
 // - PrayerCommitment
-// - MoodEntry
+// - MoodEntry, MoodTerm
 // - GratitudeEntry
-// - ProgressInsight
+// - ProgressInsight, InsightVersion, InsightRating
+// - ReadingPlan, ReadingPlanDay, Enrollment, ReadingProgress
+// - MemoryVerse, Review
+// - PoolVerse, DailyVerse
+// - Favourite, Collection, Item
+// - Conversation, Message
+// - safety Event
+// - usage Record
+// - aicache Entry, Preference
+// - privacy Settings, AuditRecord
+// - jobs Job
 // Returns an error if migration fails.
 func AutoMigrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&user.User{},
 		&encouragement.Encouragement{},
+		&encouragement.StruggleLog{},
 		&journal.JournalEntry{},
 		&prayer.PrayerRequest{},
 		&prayer.PrayerLog{},
@@ -148,7 +169,30 @@ func AutoMigrate(db *gorm.DB) error {
 		&prayerchain.ChainMember{},
 		&prayerchain.PrayerCommitment{},
 		&mood.MoodEntry{},
+		&mood.MoodTerm{},
 		&gratitude.GratitudeEntry{},
 		&insights.ProgressInsight{},
+		&insights.InsightVersion{},
+		&insights.InsightRating{},
+		&readingplan.ReadingPlan{},
+		&readingplan.ReadingPlanDay{},
+		&readingplan.Enrollment{},
+		&readingplan.ReadingProgress{},
+		&memory.MemoryVerse{},
+		&memory.Review{},
+		&verseoftheday.PoolVerse{},
+		&verseoftheday.DailyVerse{},
+		&collection.Favourite{},
+		&collection.Collection{},
+		&collection.Item{},
+		&companion.Conversation{},
+		&companion.Message{},
+		&safety.Event{},
+		&usage.Record{},
+		&aicache.Entry{},
+		&aicache.Preference{},
+		&privacy.Settings{},
+		&privacy.AuditRecord{},
+		&jobs.Job{},
 	)
 }
@@ -0,0 +1,57 @@
+package aicache
+
+import (
+	"time"
+)
+
+// Entry is a cached AI encouragement, shared by everyone whose input
+// normalises to the same text, or is similar enough to it. The input itself
+// isn't stored, only its hash and embedding.
+type Entry struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CacheKey       string    `json:"-" gorm:"uniqueIndex;size:64"` // SHA-256 of the prompt version and normalised input
+	PromptVersion  string    `json:"prompt_version" gorm:"index"`
+	Verse          string    `json:"verse"`
+	Message        string    `json:"message"`
+	Embedding      string    `json:"-" gorm:"type:text"` // JSON array of floats, castable to a pgvector vector
+	EmbeddingModel string    `json:"-"`
+	Hits           int       `json:"hits"`
+	ExpiresAt      time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName matches the ai_cache_entries table created by the migrations
+func (Entry) TableName() string {
+	return "ai_cache_entries"
+}
+
+// Preference records whether a user has opted out of cached responses
+type Preference struct {
+	UserID    uint      `json:"-" gorm:"primaryKey"`
+	OptOut    bool      `json:"opt_out"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName matches the ai_cache_preferences table created by the migrations
+func (Preference) TableName() string {
+	return "ai_cache_preferences"
+}
+
+// PreferenceRequest represents the request body for updating the preference
+type PreferenceRequest struct {
+	OptOut *bool `json:"opt_out" binding:"required"`
+}
+
+// Stats reports how well the cache is doing since the server started, along
+// with the entries currently stored
+type Stats struct {
+	Lookups      int64   `json:"lookups"`
+	ExactHits    int64   `json:"exact_hits"`
+	SemanticHits int64   `json:"semantic_hits"`
+	Misses       int64   `json:"misses"`
+	Skipped      int64   `json:"skipped"` // Opted out or too long to cache
+	HitRate      float64 `json:"hit_rate"`
+	Entries      int64   `json:"entries"`
+	TotalHits    int64   `json:"total_hits"` // Hits on the stored entries, across restarts
+	Search       string  `json:"search"`     // "pgvector", "brute_force" or "exact"
+}
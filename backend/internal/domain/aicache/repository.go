@@ -0,0 +1,103 @@
+package aicache
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// HasVector reports whether the pgvector extension is installed
+func (r *Repository) HasVector() bool {
+	var installed bool
+	err := r.db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'vector')").Scan(&installed).Error
+	return err == nil && installed
+}
+
+// GetByKey retrieves an unexpired entry by its key
+func (r *Repository) GetByKey(key string, now time.Time) (*Entry, error) {
+	var entry Entry
+	err := r.db.Where("cache_key = ? AND expires_at > ?", key, now).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetCandidates retrieves the most recent unexpired entries embedded with a
+// model, for comparing in Go when pgvector isn't available
+func (r *Repository) GetCandidates(promptVersion, model string, now time.Time, limit int) ([]Entry, error) {
+	var entries []Entry
+	err := r.db.Where("prompt_version = ? AND embedding_model = ? AND expires_at > ?", promptVersion, model, now).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// GetNearest retrieves the unexpired entry closest to an embedding by
+// cosine distance, using pgvector
+func (r *Repository) GetNearest(promptVersion, model, embedding string, now time.Time) (*Entry, error) {
+	var entry Entry
+	err := r.db.Where("prompt_version = ? AND embedding_model = ? AND expires_at > ?", promptVersion, model, now).
+		Order(clause.Expr{SQL: "embedding::vector <=> ?::vector", Vars: []interface{}{embedding}}).
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Upsert stores an entry, replacing any entry with the same key
+func (r *Repository) Upsert(entry *Entry) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"verse", "message", "embedding", "embedding_model", "hits", "expires_at", "created_at"}),
+	}).Create(entry).Error
+}
+
+// IncrementHits counts a hit on an entry
+func (r *Repository) IncrementHits(id uint) error {
+	return r.db.Model(&Entry{}).Where("id = ?", id).UpdateColumn("hits", gorm.Expr("hits + 1")).Error
+}
+
+// DeleteExpired removes entries past their expiry
+func (r *Repository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at <= ?", now).Delete(&Entry{}).Error
+}
+
+// CountEntries counts the unexpired entries and their total hits
+func (r *Repository) CountEntries(now time.Time) (int64, int64, error) {
+	var totals struct {
+		Entries int64
+		Hits    int64
+	}
+	err := r.db.Model(&Entry{}).
+		Select("COUNT(*) AS entries, COALESCE(SUM(hits), 0) AS hits").
+		Where("expires_at > ?", now).
+		Scan(&totals).Error
+	return totals.Entries, totals.Hits, err
+}
+
+// GetPreference retrieves a user's preference, if they have set one
+func (r *Repository) GetPreference(userID uint) (*Preference, error) {
+	var pref Preference
+	err := r.db.Where("user_id = ?", userID).First(&pref).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// SavePreference creates or updates a user's preference
+func (r *Repository) SavePreference(pref *Preference) error {
+	return r.db.Save(pref).Error
+}
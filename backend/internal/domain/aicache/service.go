@@ -0,0 +1,291 @@
+package aicache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"armourup/internal/llm"
+
+	"gorm.io/gorm"
+)
+
+// Options configures the cache
+type Options struct {
+	// TTL is how long an entry is served for
+	TTL time.Duration
+	// Semantic enables matching similar, not just identical, inputs using
+	// embeddings from the provider
+	Semantic bool
+	// SimilarityThreshold is the cosine similarity a semantic match needs
+	SimilarityThreshold float64
+	// MaxInputLength limits the inputs that are cached, in characters. Long
+	// inputs are personal and rarely repeated, so aren't worth sharing.
+	MaxInputLength int
+	// MaxCandidates limits the entries compared without pgvector
+	MaxCandidates int
+}
+
+// Service caches AI encouragements in front of the provider
+type Service struct {
+	repo     *Repository
+	embedder llm.Embedder
+	opts     Options
+	vector   bool
+
+	lookups      atomic.Int64
+	exactHits    atomic.Int64
+	semanticHits atomic.Int64
+	misses       atomic.Int64
+	skipped      atomic.Int64
+}
+
+// NewService creates the cache. The embedder may be nil, in which case only
+// identical inputs are matched.
+func NewService(repo *Repository, embedder llm.Embedder, opts Options) *Service {
+	if opts.TTL <= 0 {
+		opts.TTL = 7 * 24 * time.Hour
+	}
+	if opts.SimilarityThreshold <= 0 {
+		opts.SimilarityThreshold = 0.92
+	}
+	if opts.MaxInputLength <= 0 {
+		opts.MaxInputLength = 200
+	}
+	if opts.MaxCandidates <= 0 {
+		opts.MaxCandidates = 500
+	}
+	if !opts.Semantic {
+		embedder = nil
+	}
+
+	s := &Service{repo: repo, embedder: embedder, opts: opts}
+	if embedder != nil {
+		s.vector = repo.HasVector()
+	}
+	return s
+}
+
+// Miss carries what Lookup worked out about an input that wasn't cached, so
+// that Store doesn't have to work it out again
+type Miss struct {
+	key            string
+	promptVersion  string
+	embedding      string
+	embeddingModel string
+}
+
+// Lookup finds a cached response for the input, generated by the same prompt
+// version. On a miss it returns a Miss to pass to Store once the response has
+// been generated. Both are nil when the input shouldn't be cached: the user
+// has opted out, or the input is too long.
+func (s *Service) Lookup(ctx context.Context, userID uint, promptVersion, input string) (*Entry, *Miss) {
+	if s == nil {
+		return nil, nil
+	}
+	s.lookups.Add(1)
+
+	normalised := Normalise(input)
+	if normalised == "" || len([]rune(normalised)) > s.opts.MaxInputLength || s.optedOut(userID) {
+		s.skipped.Add(1)
+		return nil, nil
+	}
+
+	now := time.Now()
+	miss := &Miss{key: Key(promptVersion, normalised), promptVersion: promptVersion}
+	if entry, err := s.repo.GetByKey(miss.key, now); err == nil {
+		s.exactHits.Add(1)
+		s.hit(entry)
+		return entry, nil
+	}
+
+	if s.embedder != nil {
+		if entry := s.similar(ctx, miss, normalised, now); entry != nil {
+			s.semanticHits.Add(1)
+			s.hit(entry)
+			return entry, nil
+		}
+	}
+
+	s.misses.Add(1)
+	return nil, miss
+}
+
+// similar looks for an entry whose input was embedded close to this one,
+// keeping the embedding on the miss for Store
+func (s *Service) similar(ctx context.Context, miss *Miss, normalised string, now time.Time) *Entry {
+	embeddings, err := s.embedder.Embed(ctx, []string{normalised})
+	if err != nil || len(embeddings.Vectors) != 1 {
+		if err != nil && !errors.Is(err, llm.ErrEmbeddingsUnsupported) {
+			log.Printf("Warning: failed to embed input for the AI cache: %v", err)
+		}
+		return nil
+	}
+	vector := embeddings.Vectors[0]
+	encoded, _ := json.Marshal(vector)
+	miss.embedding = string(encoded)
+	miss.embeddingModel = embeddings.Model
+
+	var candidates []Entry
+	if s.vector {
+		nearest, err := s.repo.GetNearest(miss.promptVersion, miss.embeddingModel, miss.embedding, now)
+		if err != nil {
+			return nil
+		}
+		candidates = []Entry{*nearest}
+	} else {
+		candidates, err = s.repo.GetCandidates(miss.promptVersion, miss.embeddingModel, now, s.opts.MaxCandidates)
+		if err != nil {
+			return nil
+		}
+	}
+
+	var best *Entry
+	bestScore := s.opts.SimilarityThreshold
+	for i := range candidates {
+		var candidate []float32
+		if json.Unmarshal([]byte(candidates[i].Embedding), &candidate) != nil {
+			continue
+		}
+		if score := CosineSimilarity(vector, candidate); score >= bestScore {
+			best, bestScore = &candidates[i], score
+		}
+	}
+	return best
+}
+
+func (s *Service) hit(entry *Entry) {
+	if err := s.repo.IncrementHits(entry.ID); err != nil {
+		log.Printf("Warning: failed to count AI cache hit: %v", err)
+	}
+}
+
+// Store caches a generated response for a miss. A nil miss is ignored.
+func (s *Service) Store(miss *Miss, verse, message string) {
+	if s == nil || miss == nil {
+		return
+	}
+
+	now := time.Now()
+	if err := s.repo.DeleteExpired(now); err != nil {
+		log.Printf("Warning: failed to purge the AI cache: %v", err)
+	}
+
+	entry := &Entry{
+		CacheKey:       miss.key,
+		PromptVersion:  miss.promptVersion,
+		Verse:          verse,
+		Message:        message,
+		Embedding:      miss.embedding,
+		EmbeddingModel: miss.embeddingModel,
+		ExpiresAt:      now.Add(s.opts.TTL),
+		CreatedAt:      now,
+	}
+	if err := s.repo.Upsert(entry); err != nil {
+		log.Printf("Warning: failed to store AI cache entry: %v", err)
+	}
+}
+
+func (s *Service) optedOut(userID uint) bool {
+	if userID == 0 {
+		return false
+	}
+	pref, err := s.repo.GetPreference(userID)
+	return err == nil && pref.OptOut
+}
+
+// GetPreference retrieves the user's cache preference, defaulting to opted in
+func (s *Service) GetPreference(userID uint) (*Preference, error) {
+	pref, err := s.repo.GetPreference(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &Preference{UserID: userID}, nil
+	}
+	return pref, err
+}
+
+// SetPreference opts the user in or out of cached responses
+func (s *Service) SetPreference(userID uint, optOut bool) (*Preference, error) {
+	pref := &Preference{UserID: userID, OptOut: optOut}
+	if err := s.repo.SavePreference(pref); err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+// Stats reports the hit rate since the server started and the stored entries
+func (s *Service) Stats() (*Stats, error) {
+	stats := &Stats{
+		Lookups:      s.lookups.Load(),
+		ExactHits:    s.exactHits.Load(),
+		SemanticHits: s.semanticHits.Load(),
+		Misses:       s.misses.Load(),
+		Skipped:      s.skipped.Load(),
+		Search:       "exact",
+	}
+	if cacheable := stats.ExactHits + stats.SemanticHits + stats.Misses; cacheable > 0 {
+		stats.HitRate = float64(stats.ExactHits+stats.SemanticHits) / float64(cacheable)
+	}
+	if s.embedder != nil {
+		stats.Search = "brute_force"
+		if s.vector {
+			stats.Search = "pgvector"
+		}
+	}
+
+	entries, hits, err := s.repo.CountEntries(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	stats.Entries = entries
+	stats.TotalHits = hits
+	return stats, nil
+}
+
+// Normalise reduces an input to the words that matter for matching: lower
+// case, without punctuation and with single spaces
+func Normalise(input string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(input) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '\'':
+			b.WriteRune(r)
+		case r == '’':
+			b.WriteRune('\'')
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Key identifies a normalised input answered by a prompt version
+func Key(promptVersion, normalised string) string {
+	sum := sha256.Sum256([]byte(promptVersion + "\x00" + normalised))
+	return hex.EncodeToString(sum[:])
+}
+
+// CosineSimilarity compares two embeddings, giving 0 when they can't be
+// compared
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,307 @@
+package collection
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service *Service
+}
+
+func NewController(service *Service) *Controller {
+	return &Controller{service: service}
+}
+
+// AddFavourite saves an encouragement, AI response or verse to the user's favourites
+func (c *Controller) AddFavourite(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req AddFavouriteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	favourite, err := c.service.AddFavourite(userID.(uint), req)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, favourite)
+}
+
+// GetFavourites lists the user's favourites, optionally filtered with ?type=
+func (c *Controller) GetFavourites(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	favourites, err := c.service.GetFavourites(userID.(uint), ctx.Query("type"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, favourites)
+}
+
+// DeleteFavourite removes a favourite
+func (c *Controller) DeleteFavourite(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.DeleteFavourite(id, userID); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// CreateCollection creates a named collection
+func (c *Controller) CreateCollection(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req CollectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := c.service.CreateCollection(userID.(uint), req)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, collection)
+}
+
+// GetCollections lists the user's collections
+func (c *Controller) GetCollections(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	collections, err := c.service.GetCollections(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, collections)
+}
+
+// GetCollection returns a collection with its content resolved, in order
+func (c *Controller) GetCollection(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	view, err := c.service.GetCollection(id, userID)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+// UpdateCollection renames a collection or changes its description
+func (c *Controller) UpdateCollection(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	var req CollectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := c.service.UpdateCollection(id, userID, req)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection deletes a collection, keeping its favourites
+func (c *Controller) DeleteCollection(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.DeleteCollection(id, userID); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// AddItem adds a favourite to a collection
+func (c *Controller) AddItem(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	var req AddItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := c.service.AddItem(id, userID, req)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, view)
+}
+
+// RemoveItem takes a favourite out of a collection
+func (c *Controller) RemoveItem(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	favouriteID, err := strconv.ParseUint(ctx.Param("favouriteId"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid favourite ID"})
+		return
+	}
+
+	if err := c.service.RemoveItem(id, userID, uint(favouriteID)); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ReorderItems sets the order of a collection's items
+func (c *Controller) ReorderItems(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	var req ReorderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := c.service.ReorderItems(id, userID, req.FavouriteIDs)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+// ShareCollection creates a read-only share link for a collection
+func (c *Controller) ShareCollection(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	token, err := c.service.ShareCollection(id, userID)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"share_token": token,
+		"share_path":  "/api/shared/collections/" + token,
+	})
+}
+
+// UnshareCollection revokes a collection's share link
+func (c *Controller) UnshareCollection(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.UnshareCollection(id, userID); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetSharedCollection returns a shared collection to anyone with its link
+func (c *Controller) GetSharedCollection(ctx *gin.Context) {
+	view, err := c.service.GetSharedCollection(ctx.Param("token"))
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+// userAndID reads the authenticated user and the :id path parameter,
+// writing an error response if either is missing
+func (c *Controller) userAndID(ctx *gin.Context) (uint, uint, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return 0, 0, false
+	}
+
+	return userID.(uint), uint(id), true
+}
+
+// handleError maps service errors to HTTP responses
+func (c *Controller) handleError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case "collection not found", "favourite not found", "encouragement not found", "struggle log not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "already in your favourites", "favourite already in collection":
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "source_id is required", "message is required", "verse reference is required",
+		"collection name is required", "order must list every item in the collection exactly once":
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
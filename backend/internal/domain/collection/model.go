@@ -0,0 +1,109 @@
+package collection
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of content that can be favourited
+const (
+	TypeEncouragement = "encouragement" // Entry in the curated encouragement library
+	TypeStruggle      = "struggle"      // Encouragement received for a logged struggle
+	TypeAIResponse    = "ai_response"   // Encouragement returned by the AI that wasn't logged
+	TypeVerse         = "verse"         // Scripture reference, with optional text
+)
+
+// Favourite is a piece of content a user wants to keep. The content is copied
+// at the time it is favourited so it survives the source being removed;
+// library entries and struggle logs are resolved fresh when viewed.
+type Favourite struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;index"`
+	Type      string         `json:"type" gorm:"not null"`
+	SourceID  *uint          `json:"source_id,omitempty"` // Encouragement or struggle log ID
+	Reference string         `json:"reference"`
+	Verse     string         `json:"verse"`
+	Message   string         `json:"message"`
+	Note      string         `json:"note"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// Collection is a user-defined, ordered group of favourites
+type Collection struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      uint           `json:"user_id" gorm:"not null;index"`
+	Name        string         `json:"name" gorm:"not null"`
+	Description string         `json:"description"`
+	ShareToken  *string        `json:"share_token,omitempty" gorm:"uniqueIndex"` // Set while the collection is shared
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// Item places a favourite in a collection at a position
+type Item struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CollectionID uint      `json:"collection_id" gorm:"not null;uniqueIndex:idx_collection_item"`
+	FavouriteID  uint      `json:"favourite_id" gorm:"not null;uniqueIndex:idx_collection_item"`
+	Position     int       `json:"position" gorm:"not null;default:0"`
+	Favourite    Favourite `json:"-" gorm:"foreignKey:FavouriteID"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName matches the collection_items table created by the migrations
+func (Item) TableName() string {
+	return "collection_items"
+}
+
+// AddFavouriteRequest favourites a library entry or struggle log by source_id,
+// or an AI response or verse by its content
+type AddFavouriteRequest struct {
+	Type      string `json:"type" binding:"required,oneof=encouragement struggle ai_response verse"`
+	SourceID  *uint  `json:"source_id"`
+	Reference string `json:"reference"`
+	Verse     string `json:"verse"`
+	Message   string `json:"message"`
+	Note      string `json:"note"`
+}
+
+type CollectionRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type AddItemRequest struct {
+	FavouriteID uint `json:"favourite_id" binding:"required"`
+	Position    *int `json:"position"` // Defaults to the end of the collection
+}
+
+type ReorderRequest struct {
+	FavouriteIDs []uint `json:"favourite_ids" binding:"required"`
+}
+
+// ResolvedItem is a collection entry with its content filled in
+type ResolvedItem struct {
+	FavouriteID uint   `json:"favourite_id"`
+	Position    int    `json:"position"`
+	Type        string `json:"type"`
+	Reference   string `json:"reference,omitempty"`
+	Verse       string `json:"verse,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Note        string `json:"note,omitempty"`
+	Unavailable bool   `json:"unavailable,omitempty"` // Source was removed; content is as favourited
+}
+
+// CollectionView is a collection with its resolved content, in order
+type CollectionView struct {
+	ID          uint           `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Shared      bool           `json:"shared"`
+	ShareToken  string         `json:"share_token,omitempty"`
+	Items       []ResolvedItem `json:"items"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
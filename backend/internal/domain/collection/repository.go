@@ -0,0 +1,129 @@
+package collection
+
+import (
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// CreateFavourite saves a favourite
+func (r *Repository) CreateFavourite(favourite *Favourite) error {
+	return r.db.Create(favourite).Error
+}
+
+// GetFavourite retrieves a favourite by ID
+func (r *Repository) GetFavourite(id uint) (*Favourite, error) {
+	var favourite Favourite
+	err := r.db.First(&favourite, id).Error
+	return &favourite, err
+}
+
+// GetFavouriteBySource retrieves a user's favourite of a library entry or struggle log
+func (r *Repository) GetFavouriteBySource(userID uint, favouriteType string, sourceID uint) (*Favourite, error) {
+	var favourite Favourite
+	err := r.db.Where("user_id = ? AND type = ? AND source_id = ?", userID, favouriteType, sourceID).
+		First(&favourite).Error
+	return &favourite, err
+}
+
+// GetFavourites retrieves a user's favourites, newest first, optionally of one type
+func (r *Repository) GetFavourites(userID uint, favouriteType string) ([]Favourite, error) {
+	var favourites []Favourite
+	query := r.db.Where("user_id = ?", userID)
+	if favouriteType != "" {
+		query = query.Where("type = ?", favouriteType)
+	}
+	err := query.Order("created_at DESC").Find(&favourites).Error
+	return favourites, err
+}
+
+// DeleteFavourite soft deletes a favourite and removes it from every collection
+func (r *Repository) DeleteFavourite(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("favourite_id = ?", id).Delete(&Item{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Favourite{}, id).Error
+	})
+}
+
+// CreateCollection saves a new collection
+func (r *Repository) CreateCollection(collection *Collection) error {
+	return r.db.Create(collection).Error
+}
+
+// GetCollection retrieves a collection by ID
+func (r *Repository) GetCollection(id uint) (*Collection, error) {
+	var collection Collection
+	err := r.db.First(&collection, id).Error
+	return &collection, err
+}
+
+// GetCollectionByShareToken retrieves a shared collection by its share token
+func (r *Repository) GetCollectionByShareToken(token string) (*Collection, error) {
+	var collection Collection
+	err := r.db.Where("share_token = ?", token).First(&collection).Error
+	return &collection, err
+}
+
+// GetCollections retrieves a user's collections
+func (r *Repository) GetCollections(userID uint) ([]Collection, error) {
+	var collections []Collection
+	err := r.db.Where("user_id = ?", userID).Order("name ASC").Find(&collections).Error
+	return collections, err
+}
+
+// UpdateCollection saves changes to a collection
+func (r *Repository) UpdateCollection(collection *Collection) error {
+	return r.db.Save(collection).Error
+}
+
+// DeleteCollection soft deletes a collection and removes its items
+func (r *Repository) DeleteCollection(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&Item{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Collection{}, id).Error
+	})
+}
+
+// GetItems retrieves a collection's items with their favourites, in order
+func (r *Repository) GetItems(collectionID uint) ([]Item, error) {
+	var items []Item
+	err := r.db.Preload("Favourite").
+		Where("collection_id = ?", collectionID).
+		Order("position ASC, id ASC").
+		Find(&items).Error
+	return items, err
+}
+
+// CreateItem adds a favourite to a collection
+func (r *Repository) CreateItem(item *Item) error {
+	return r.db.Create(item).Error
+}
+
+// DeleteItem removes a favourite from a collection
+func (r *Repository) DeleteItem(collectionID, favouriteID uint) error {
+	return r.db.Where("collection_id = ? AND favourite_id = ?", collectionID, favouriteID).
+		Delete(&Item{}).Error
+}
+
+// SaveItemPositions writes the positions of a collection's items in one transaction
+func (r *Repository) SaveItemPositions(items []Item) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			if err := tx.Model(&Item{}).Where("id = ?", item.ID).
+				Update("position", item.Position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
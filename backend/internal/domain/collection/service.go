@@ -0,0 +1,423 @@
+package collection
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"armourup/internal/domain/encouragement"
+	"armourup/internal/scripture"
+)
+
+// shareTokenBytes is the amount of randomness in a share token
+const shareTokenBytes = 24
+
+type Service struct {
+	repo              *Repository
+	encouragementRepo *encouragement.Repository
+}
+
+func NewService(repo *Repository, encouragementRepo *encouragement.Repository) *Service {
+	return &Service{
+		repo:              repo,
+		encouragementRepo: encouragementRepo,
+	}
+}
+
+// AddFavourite saves content the user wants to keep
+func (s *Service) AddFavourite(userID uint, req AddFavouriteRequest) (*Favourite, error) {
+	favourite := &Favourite{
+		UserID: userID,
+		Type:   req.Type,
+		Note:   strings.TrimSpace(req.Note),
+	}
+
+	switch req.Type {
+	case TypeEncouragement, TypeStruggle:
+		if req.SourceID == nil {
+			return nil, errors.New("source_id is required")
+		}
+		if existing, err := s.repo.GetFavouriteBySource(userID, req.Type, *req.SourceID); err == nil && existing.ID > 0 {
+			return nil, errors.New("already in your favourites")
+		}
+		favourite.SourceID = req.SourceID
+		if err := s.copySource(favourite); err != nil {
+			return nil, err
+		}
+	case TypeAIResponse:
+		if strings.TrimSpace(req.Message) == "" {
+			return nil, errors.New("message is required")
+		}
+		favourite.Message = strings.TrimSpace(req.Message)
+		favourite.Reference, favourite.Verse = splitReference(req.Reference, req.Verse)
+	case TypeVerse:
+		favourite.Reference, favourite.Verse = splitReference(req.Reference, req.Verse)
+		if favourite.Reference == "" {
+			return nil, errors.New("verse reference is required")
+		}
+	}
+
+	if err := s.repo.CreateFavourite(favourite); err != nil {
+		return nil, err
+	}
+	return favourite, nil
+}
+
+// copySource copies a library entry or struggle log's content into the favourite
+func (s *Service) copySource(favourite *Favourite) error {
+	if favourite.Type == TypeEncouragement {
+		enc, err := s.encouragementRepo.GetByID(*favourite.SourceID)
+		if err != nil {
+			return errors.New("encouragement not found")
+		}
+		favourite.Reference, favourite.Verse = splitReference(enc.Reference, enc.Verse)
+		favourite.Message = enc.Message
+		return nil
+	}
+
+	log, err := s.encouragementRepo.GetStruggleLog(*favourite.SourceID)
+	if err != nil || log.UserID != favourite.UserID {
+		return errors.New("struggle log not found")
+	}
+	favourite.Reference, favourite.Verse = scripture.SplitVerse(log.Verse)
+	favourite.Message = log.Message
+	return nil
+}
+
+// GetFavourites retrieves the user's favourites, optionally of one type
+func (s *Service) GetFavourites(userID uint, favouriteType string) ([]Favourite, error) {
+	return s.repo.GetFavourites(userID, favouriteType)
+}
+
+// DeleteFavourite removes a favourite, taking it out of any collections
+func (s *Service) DeleteFavourite(id, userID uint) error {
+	if _, err := s.getOwnedFavourite(id, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteFavourite(id)
+}
+
+// CreateCollection creates an empty collection
+func (s *Service) CreateCollection(userID uint, req CollectionRequest) (*Collection, error) {
+	collection := &Collection{
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+	}
+	if collection.Name == "" {
+		return nil, errors.New("collection name is required")
+	}
+	if err := s.repo.CreateCollection(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// GetCollections retrieves the user's collections
+func (s *Service) GetCollections(userID uint) ([]Collection, error) {
+	return s.repo.GetCollections(userID)
+}
+
+// GetCollection retrieves one of the user's collections with its content resolved
+func (s *Service) GetCollection(id, userID uint) (*CollectionView, error) {
+	collection, err := s.getOwnedCollection(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildView(collection, true)
+}
+
+// GetSharedCollection retrieves a shared collection by its share token
+func (s *Service) GetSharedCollection(token string) (*CollectionView, error) {
+	if token == "" {
+		return nil, errors.New("collection not found")
+	}
+	collection, err := s.repo.GetCollectionByShareToken(token)
+	if err != nil {
+		return nil, errors.New("collection not found")
+	}
+	return s.buildView(collection, false)
+}
+
+// UpdateCollection renames a collection or changes its description
+func (s *Service) UpdateCollection(id, userID uint, req CollectionRequest) (*Collection, error) {
+	collection, err := s.getOwnedCollection(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	collection.Name = strings.TrimSpace(req.Name)
+	collection.Description = strings.TrimSpace(req.Description)
+	if collection.Name == "" {
+		return nil, errors.New("collection name is required")
+	}
+	if err := s.repo.UpdateCollection(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// DeleteCollection deletes a collection. The favourites in it are kept.
+func (s *Service) DeleteCollection(id, userID uint) error {
+	if _, err := s.getOwnedCollection(id, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteCollection(id)
+}
+
+// AddItem adds one of the user's favourites to a collection, at the end
+// unless a position is given
+func (s *Service) AddItem(id, userID uint, req AddItemRequest) (*CollectionView, error) {
+	collection, err := s.getOwnedCollection(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getOwnedFavourite(req.FavouriteID, userID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetItems(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.FavouriteID == req.FavouriteID {
+			return nil, errors.New("favourite already in collection")
+		}
+	}
+
+	position := len(items)
+	if req.Position != nil {
+		position = *req.Position
+	}
+
+	item := Item{CollectionID: id, FavouriteID: req.FavouriteID, Position: position}
+	if err := s.repo.CreateItem(&item); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveItemPositions(InsertAt(items, item, position)); err != nil {
+		return nil, err
+	}
+
+	return s.buildView(collection, true)
+}
+
+// RemoveItem takes a favourite out of a collection
+func (s *Service) RemoveItem(id, userID, favouriteID uint) error {
+	if _, err := s.getOwnedCollection(id, userID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteItem(id, favouriteID); err != nil {
+		return err
+	}
+
+	items, err := s.repo.GetItems(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.SaveItemPositions(renumber(items))
+}
+
+// ReorderItems puts a collection's items in the given order of favourite IDs
+func (s *Service) ReorderItems(id, userID uint, favouriteIDs []uint) (*CollectionView, error) {
+	collection, err := s.getOwnedCollection(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetItems(id)
+	if err != nil {
+		return nil, err
+	}
+	ordered, err := Reorder(items, favouriteIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveItemPositions(ordered); err != nil {
+		return nil, err
+	}
+
+	return s.buildView(collection, true)
+}
+
+// ShareCollection makes a collection readable by anyone with its share link.
+// Sharing an already shared collection returns the existing token.
+func (s *Service) ShareCollection(id, userID uint) (string, error) {
+	collection, err := s.getOwnedCollection(id, userID)
+	if err != nil {
+		return "", err
+	}
+	if collection.ShareToken != nil {
+		return *collection.ShareToken, nil
+	}
+
+	token, err := GenerateShareToken()
+	if err != nil {
+		return "", err
+	}
+	collection.ShareToken = &token
+	if err := s.repo.UpdateCollection(collection); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// UnshareCollection revokes a collection's share link
+func (s *Service) UnshareCollection(id, userID uint) error {
+	collection, err := s.getOwnedCollection(id, userID)
+	if err != nil {
+		return err
+	}
+	collection.ShareToken = nil
+	return s.repo.UpdateCollection(collection)
+}
+
+// GenerateShareToken creates an unguessable, URL-safe token for a share link
+func GenerateShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// InsertAt places an item among a collection's items at the position,
+// clamped to the collection's bounds, and renumbers every item
+func InsertAt(items []Item, item Item, position int) []Item {
+	if position < 0 {
+		position = 0
+	}
+	if position > len(items) {
+		position = len(items)
+	}
+
+	result := make([]Item, 0, len(items)+1)
+	result = append(result, items[:position]...)
+	result = append(result, item)
+	result = append(result, items[position:]...)
+	return renumber(result)
+}
+
+// Reorder arranges a collection's items in the order of the favourite IDs,
+// which must list every item exactly once
+func Reorder(items []Item, favouriteIDs []uint) ([]Item, error) {
+	if len(favouriteIDs) != len(items) {
+		return nil, errors.New("order must list every item in the collection exactly once")
+	}
+
+	byFavourite := make(map[uint]Item, len(items))
+	for _, item := range items {
+		byFavourite[item.FavouriteID] = item
+	}
+
+	result := make([]Item, 0, len(items))
+	for _, id := range favouriteIDs {
+		item, ok := byFavourite[id]
+		if !ok {
+			return nil, errors.New("order must list every item in the collection exactly once")
+		}
+		delete(byFavourite, id)
+		result = append(result, item)
+	}
+	return renumber(result), nil
+}
+
+func renumber(items []Item) []Item {
+	for i := range items {
+		items[i].Position = i
+	}
+	return items
+}
+
+// buildView resolves a collection's items. Library entries and struggle logs
+// show their current content; the owner's view includes the share token.
+func (s *Service) buildView(collection *Collection, owner bool) (*CollectionView, error) {
+	items, err := s.repo.GetItems(collection.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &CollectionView{
+		ID:          collection.ID,
+		Name:        collection.Name,
+		Description: collection.Description,
+		Shared:      collection.ShareToken != nil,
+		Items:       make([]ResolvedItem, 0, len(items)),
+		CreatedAt:   collection.CreatedAt,
+		UpdatedAt:   collection.UpdatedAt,
+	}
+	if owner && collection.ShareToken != nil {
+		view.ShareToken = *collection.ShareToken
+	}
+
+	for _, item := range items {
+		view.Items = append(view.Items, s.resolve(item))
+	}
+	return view, nil
+}
+
+func (s *Service) resolve(item Item) ResolvedItem {
+	favourite := item.Favourite
+	resolved := ResolvedItem{
+		FavouriteID: item.FavouriteID,
+		Position:    item.Position,
+		Type:        favourite.Type,
+		Reference:   favourite.Reference,
+		Verse:       favourite.Verse,
+		Message:     favourite.Message,
+		Note:        favourite.Note,
+	}
+	if favourite.SourceID == nil {
+		return resolved
+	}
+
+	switch favourite.Type {
+	case TypeEncouragement:
+		enc, err := s.encouragementRepo.GetByID(*favourite.SourceID)
+		if err != nil {
+			resolved.Unavailable = true
+			return resolved
+		}
+		resolved.Reference, resolved.Verse = splitReference(enc.Reference, enc.Verse)
+		resolved.Message = enc.Message
+		resolved.Category = enc.Category
+	case TypeStruggle:
+		log, err := s.encouragementRepo.GetStruggleLog(*favourite.SourceID)
+		if err != nil || log.UserID != favourite.UserID {
+			resolved.Unavailable = true
+			return resolved
+		}
+		resolved.Reference, resolved.Verse = scripture.SplitVerse(log.Verse)
+		resolved.Message = log.Message
+		resolved.Category = log.Category
+	}
+	return resolved
+}
+
+func (s *Service) getOwnedCollection(id, userID uint) (*Collection, error) {
+	collection, err := s.repo.GetCollection(id)
+	if err != nil || collection.UserID != userID {
+		return nil, errors.New("collection not found")
+	}
+	return collection, nil
+}
+
+func (s *Service) getOwnedFavourite(id, userID uint) (*Favourite, error) {
+	favourite, err := s.repo.GetFavourite(id)
+	if err != nil || favourite.UserID != userID {
+		return nil, errors.New("favourite not found")
+	}
+	return favourite, nil
+}
+
+// splitReference returns a reference and verse text, splitting the reference
+// out of the text when it wasn't given separately
+func splitReference(reference, verse string) (string, string) {
+	reference = strings.TrimSpace(reference)
+	verse = strings.TrimSpace(verse)
+	if reference == "" && verse != "" {
+		return scripture.SplitVerse(verse)
+	}
+	return reference, verse
+}
@@ -0,0 +1,127 @@
+package companion
+
+import (
+	"fmt"
+	"strings"
+
+	"armourup/internal/domain/journal"
+	"armourup/internal/domain/mood"
+	"armourup/internal/domain/privacy"
+	"armourup/internal/llm"
+)
+
+// persona is the system prompt that sets the companion's voice
+const persona = `You are ArmourUp's spiritual companion: a warm, patient Christian friend who listens well.
+Respond with empathy and honesty, grounded in Scripture, and quote Bible verses with their references when they help.
+Keep replies conversational and reasonably short. Ask gentle follow-up questions when it would help the person reflect.
+You are not a replacement for a pastor, counsellor or doctor; encourage the person to seek them out when appropriate.`
+
+// messageOverhead approximates the tokens each chat message costs beyond its content
+const messageOverhead = 4
+
+// EstimateTokens gives a rough token count for text, at about four
+// characters per token, which is close enough for budgeting context
+func EstimateTokens(text string) int {
+	return (len(text)+3)/4 + messageOverhead
+}
+
+// FitHistory splits a conversation's history into the newest messages whose
+// combined tokens fit within the budget and the older messages that don't.
+// The latest message is always kept, even if it alone exceeds the budget.
+func FitHistory(history []Message, budget int) (kept, overflow []Message) {
+	if len(history) == 0 {
+		return nil, nil
+	}
+
+	used := 0
+	start := len(history)
+	for i := len(history) - 1; i >= 0; i-- {
+		tokens := history[i].Tokens
+		if tokens <= 0 {
+			tokens = EstimateTokens(history[i].Content)
+		}
+		if used+tokens > budget && i < len(history)-1 {
+			break
+		}
+		used += tokens
+		start = i
+	}
+	return history[start:], history[:start]
+}
+
+// buildSystemPrompt combines the persona with the summary of earlier turns
+// and, when the user has opted in, their recent journal and mood data
+func buildSystemPrompt(summary, grounding string) string {
+	prompt := persona
+	if summary != "" {
+		prompt += "\n\nSummary of the conversation so far:\n" + summary
+	}
+	if grounding != "" {
+		prompt += "\n\nThe person has chosen to share their recent journal and mood check-ins with you. " +
+			"Use them gently for context; don't recite them back.\n" + grounding
+	}
+	return prompt
+}
+
+// buildGrounding describes recent mood check-ins and journal entries, as far
+// as the user's privacy settings allow. Free text is redacted.
+func buildGrounding(moods []mood.MoodEntry, journals []journal.JournalEntry, disclosure *privacy.Disclosure) string {
+	var b strings.Builder
+	if len(moods) > 0 && disclosure.AllowsAggregates() {
+		b.WriteString("Recent mood check-ins:\n")
+		for _, m := range moods {
+			disclosure.Include("mood_entry", m.ID)
+			fmt.Fprintf(&b, "- %s: feeling %s, spiritually %s, energy %d/10", m.Date.Format("2006-01-02"),
+				m.EmotionalState, m.SpiritualState, m.EnergyLevel)
+			if m.Notes != "" && disclosure.AllowsText() {
+				fmt.Fprintf(&b, " (%s)", disclosure.Text(Truncate(m.Notes, 150)))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(journals) > 0 && disclosure.AllowsText() {
+		b.WriteString("Recent journal entries:\n")
+		for _, j := range journals {
+			disclosure.Include("journal_entry", j.ID)
+			fmt.Fprintf(&b, "- %s: %s\n", disclosure.Text(j.Title), disclosure.Text(Truncate(j.Content, 200)))
+		}
+	}
+	return b.String()
+}
+
+// buildSummaryRequest asks the model to fold older turns into the running summary
+func buildSummaryRequest(summary string, overflow []Message, model string) llm.Request {
+	var b strings.Builder
+	if summary != "" {
+		b.WriteString("Existing summary:\n" + summary + "\n\n")
+	}
+	b.WriteString("Earlier messages:\n")
+	for _, m := range overflow {
+		fmt.Fprintf(&b, "%s: %s\n", m.Role, m.Content)
+	}
+
+	return llm.Request{
+		Model: model,
+		Messages: []llm.Message{
+			{
+				Role: llm.RoleSystem,
+				Content: "Summarise this conversation between a person and their spiritual companion in a short paragraph. " +
+					"Keep what the person shared about their situation, feelings and prayer needs, and any verses discussed.",
+			},
+			{Role: llm.RoleUser, Content: b.String()},
+		},
+		Temperature: 0.3,
+		MaxTokens:   300,
+	}
+}
+
+// Truncate shortens text to at most max characters, adding an ellipsis when
+// it cuts anything. Characters rather than bytes are counted, so multi-byte
+// characters are never split.
+func Truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "..."
+}
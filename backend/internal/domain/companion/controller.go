@@ -0,0 +1,185 @@
+package companion
+
+import (
+	"net/http"
+	"strconv"
+
+	"armourup/internal/domain/safety"
+	"armourup/internal/domain/usage"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service *Service
+	safety  *safety.Service
+}
+
+func NewController(service *Service, safetyService *safety.Service) *Controller {
+	return &Controller{service: service, safety: safetyService}
+}
+
+// CreateConversation starts a conversation with the companion. Set grounded
+// to let the companion see the user's recent journal and mood data.
+func (c *Controller) CreateConversation(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req CreateConversationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conversation, err := c.service.CreateConversation(userID.(uint), req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, conversation)
+}
+
+// GetConversations lists the user's conversations
+func (c *Controller) GetConversations(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	conversations, err := c.service.GetConversations(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conversations)
+}
+
+// GetConversation returns a conversation with its messages
+func (c *Controller) GetConversation(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	conversation, err := c.service.GetConversation(id, userID)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conversation)
+}
+
+// UpdateConversation renames a conversation or changes its grounding opt-in
+func (c *Controller) UpdateConversation(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	var req UpdateConversationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conversation, err := c.service.UpdateConversation(id, userID, req)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conversation)
+}
+
+// DeleteConversation deletes a conversation
+func (c *Controller) DeleteConversation(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.DeleteConversation(id, userID); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// SendMessage sends a message and returns the companion's reply. High-risk
+// messages are answered with crisis resources instead of a generated reply.
+func (c *Controller) SendMessage(ctx *gin.Context) {
+	userID, id, ok := c.userAndID(ctx)
+	if !ok {
+		return
+	}
+
+	var req SendMessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	check := c.safety.CheckRequest(ctx, safety.SourceCompanion, req.Content)
+	if check.ShortCircuit {
+		reply, err := c.service.SendCrisisReply(id, userID, req.Content, check.Notice.Message)
+		if err != nil {
+			c.handleError(ctx, err)
+			return
+		}
+		reply.Safety = check.Notice
+		ctx.JSON(http.StatusCreated, reply)
+		return
+	}
+
+	reply, err := c.service.SendMessage(ctx.Request.Context(), id, userID, req.Content)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	reply.Safety = check.Notice
+
+	ctx.JSON(http.StatusCreated, reply)
+}
+
+// userAndID reads the authenticated user and the :id path parameter,
+// writing an error response if either is missing
+func (c *Controller) userAndID(ctx *gin.Context) (uint, uint, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return 0, 0, false
+	}
+
+	return userID.(uint), uint(id), true
+}
+
+// handleError maps service errors to HTTP responses
+func (c *Controller) handleError(ctx *gin.Context, err error) {
+	if usage.RespondIfQuotaExceeded(ctx, err) {
+		return
+	}
+	switch err.Error() {
+	case "conversation not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "message content is required":
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case "companion is unavailable right now, please try again":
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
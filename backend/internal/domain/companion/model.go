@@ -0,0 +1,54 @@
+package companion
+
+import (
+	"time"
+
+	"armourup/internal/domain/safety"
+
+	"gorm.io/gorm"
+)
+
+// Conversation is a multi-turn chat with the spiritual companion. Older turns
+// are folded into Summary once they no longer fit in the model's context.
+type Conversation struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	UserID         uint           `json:"user_id" gorm:"not null;index"`
+	Title          string         `json:"title"`
+	Grounded       bool           `json:"grounded" gorm:"not null;default:false"` // User opted in to sharing journal and mood data
+	Summary        string         `json:"summary,omitempty"`
+	SummarisedUpTo uint           `json:"-"` // ID of the last message folded into Summary
+	Messages       []Message      `json:"messages,omitempty" gorm:"foreignKey:ConversationID"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// Message is one turn in a conversation
+type Message struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	ConversationID uint           `json:"conversation_id" gorm:"not null;index"`
+	Role           string         `json:"role" gorm:"not null"` // user or assistant
+	Content        string         `json:"content" gorm:"not null"`
+	Tokens         int            `json:"tokens"` // Estimated or reported token count
+	CreatedAt      time.Time      `json:"created_at"`
+	Safety         *safety.Notice `json:"safety,omitempty" gorm:"-"` // Crisis resources when the user's message was flagged
+}
+
+// TableName matches the conversation_messages table created by the migrations
+func (Message) TableName() string {
+	return "conversation_messages"
+}
+
+type CreateConversationRequest struct {
+	Title    string `json:"title"`
+	Grounded bool   `json:"grounded"`
+}
+
+type UpdateConversationRequest struct {
+	Title    *string `json:"title"`
+	Grounded *bool   `json:"grounded"`
+}
+
+type SendMessageRequest struct {
+	Content string `json:"content" binding:"required"`
+}
@@ -0,0 +1,70 @@
+package companion
+
+import (
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// CreateConversation starts a conversation
+func (r *Repository) CreateConversation(conversation *Conversation) error {
+	return r.db.Create(conversation).Error
+}
+
+// GetConversation retrieves a conversation by ID without its messages
+func (r *Repository) GetConversation(id uint) (*Conversation, error) {
+	var conversation Conversation
+	err := r.db.First(&conversation, id).Error
+	return &conversation, err
+}
+
+// GetConversationWithMessages retrieves a conversation and its full message history
+func (r *Repository) GetConversationWithMessages(id uint) (*Conversation, error) {
+	var conversation Conversation
+	err := r.db.Preload("Messages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&conversation, id).Error
+	return &conversation, err
+}
+
+// GetConversations retrieves a user's conversations, most recently active first
+func (r *Repository) GetConversations(userID uint) ([]Conversation, error) {
+	var conversations []Conversation
+	err := r.db.Where("user_id = ?", userID).Order("updated_at DESC").Find(&conversations).Error
+	return conversations, err
+}
+
+// UpdateConversation saves changes to a conversation
+func (r *Repository) UpdateConversation(conversation *Conversation) error {
+	return r.db.Omit("Messages").Save(conversation).Error
+}
+
+// DeleteConversation soft deletes a conversation
+func (r *Repository) DeleteConversation(id uint) error {
+	return r.db.Delete(&Conversation{}, id).Error
+}
+
+// CreateMessage adds a message to a conversation
+func (r *Repository) CreateMessage(message *Message) error {
+	return r.db.Create(message).Error
+}
+
+// DeleteMessage removes a message
+func (r *Repository) DeleteMessage(id uint) error {
+	return r.db.Delete(&Message{}, id).Error
+}
+
+// GetMessagesAfter retrieves a conversation's messages with IDs after the given one, oldest first
+func (r *Repository) GetMessagesAfter(conversationID, afterID uint) ([]Message, error) {
+	var messages []Message
+	err := r.db.Where("conversation_id = ? AND id > ?", conversationID, afterID).
+		Order("id ASC").
+		Find(&messages).Error
+	return messages, err
+}
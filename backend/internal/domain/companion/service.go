@@ -0,0 +1,294 @@
+package companion
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"armourup/internal/domain/journal"
+	"armourup/internal/domain/mood"
+	"armourup/internal/domain/privacy"
+	"armourup/internal/domain/usage"
+	"armourup/internal/llm"
+)
+
+const (
+	defaultMaxContextTokens = 3000
+	defaultMaxReplyTokens   = 500
+	defaultTitle            = "New conversation"
+	// groundingMoods and groundingJournals limit how much personal data is shared per turn
+	groundingMoods    = 5
+	groundingJournals = 3
+)
+
+// Options configures the companion's model and context budget
+type Options struct {
+	Model            string // Empty uses the provider's default
+	MaxContextTokens int
+	MaxReplyTokens   int
+}
+
+type Service struct {
+	repo        *Repository
+	provider    llm.Provider
+	moodService *mood.Service
+	journalRepo *journal.Repository
+	privacy     *privacy.Service
+	opts        Options
+}
+
+// NewService creates the companion. Everything sent to the provider is
+// redacted, and grounding data is limited by each user's privacy settings.
+func NewService(repo *Repository, provider llm.Provider, moodService *mood.Service, journalRepo *journal.Repository, privacyService *privacy.Service, opts Options) *Service {
+	if opts.MaxContextTokens <= 0 {
+		opts.MaxContextTokens = defaultMaxContextTokens
+	}
+	if opts.MaxReplyTokens <= 0 {
+		opts.MaxReplyTokens = defaultMaxReplyTokens
+	}
+	return &Service{
+		repo:        repo,
+		provider:    provider,
+		moodService: moodService,
+		journalRepo: journalRepo,
+		privacy:     privacyService,
+		opts:        opts,
+	}
+}
+
+// CreateConversation starts a new conversation
+func (s *Service) CreateConversation(userID uint, req CreateConversationRequest) (*Conversation, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = defaultTitle
+	}
+
+	conversation := &Conversation{
+		UserID:   userID,
+		Title:    title,
+		Grounded: req.Grounded,
+	}
+	if err := s.repo.CreateConversation(conversation); err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+// GetConversations lists the user's conversations
+func (s *Service) GetConversations(userID uint) ([]Conversation, error) {
+	return s.repo.GetConversations(userID)
+}
+
+// GetConversation retrieves one of the user's conversations with its messages
+func (s *Service) GetConversation(id, userID uint) (*Conversation, error) {
+	conversation, err := s.repo.GetConversationWithMessages(id)
+	if err != nil || conversation.UserID != userID {
+		return nil, errors.New("conversation not found")
+	}
+	return conversation, nil
+}
+
+// UpdateConversation renames a conversation or changes its grounding opt-in
+func (s *Service) UpdateConversation(id, userID uint, req UpdateConversationRequest) (*Conversation, error) {
+	conversation, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil && strings.TrimSpace(*req.Title) != "" {
+		conversation.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Grounded != nil {
+		conversation.Grounded = *req.Grounded
+	}
+	if err := s.repo.UpdateConversation(conversation); err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+// DeleteConversation deletes one of the user's conversations
+func (s *Service) DeleteConversation(id, userID uint) error {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteConversation(id)
+}
+
+// SendMessage adds the user's message to the conversation and returns the
+// companion's reply. Turns that no longer fit in the context budget are
+// summarised before the reply is generated.
+func (s *Service) SendMessage(ctx context.Context, id, userID uint, content string) (*Message, error) {
+	conversation, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("message content is required")
+	}
+
+	userMessage := &Message{
+		ConversationID: id,
+		Role:           llm.RoleUser,
+		Content:        content,
+		Tokens:         EstimateTokens(content),
+	}
+	if err := s.repo.CreateMessage(userMessage); err != nil {
+		return nil, err
+	}
+
+	reply, err := s.reply(llm.WithCaller(ctx, userID, usage.FeatureCompanion), conversation)
+	if err != nil {
+		// Drop the unanswered message so the user can simply try again
+		s.repo.DeleteMessage(userMessage.ID)
+		return nil, err
+	}
+
+	if conversation.Title == defaultTitle {
+		conversation.Title = Truncate(content, 60)
+	}
+	if err := s.repo.UpdateConversation(conversation); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// SendCrisisReply records the user's message with a fixed reply instead of
+// asking the model. It is used when the message shows the user may be at
+// risk, so that they get crisis resources rather than generated text.
+func (s *Service) SendCrisisReply(id, userID uint, content, reply string) (*Message, error) {
+	conversation, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("message content is required")
+	}
+
+	userMessage := &Message{
+		ConversationID: id,
+		Role:           llm.RoleUser,
+		Content:        content,
+		Tokens:         EstimateTokens(content),
+	}
+	if err := s.repo.CreateMessage(userMessage); err != nil {
+		return nil, err
+	}
+
+	replyMessage := &Message{
+		ConversationID: id,
+		Role:           llm.RoleAssistant,
+		Content:        reply,
+		Tokens:         EstimateTokens(reply),
+	}
+	if err := s.repo.CreateMessage(replyMessage); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateConversation(conversation); err != nil {
+		return nil, err
+	}
+	return replyMessage, nil
+}
+
+// reply generates and saves the companion's next message
+func (s *Service) reply(ctx context.Context, conversation *Conversation) (*Message, error) {
+	history, err := s.repo.GetMessagesAfter(conversation.ID, conversation.SummarisedUpTo)
+	if err != nil {
+		return nil, err
+	}
+
+	disclosure := s.privacy.Begin(conversation.UserID)
+	disclosure.Include("conversation", conversation.ID)
+	grounding := ""
+	if conversation.Grounded {
+		grounding = s.grounding(conversation.UserID, disclosure)
+	}
+
+	budget := s.opts.MaxContextTokens - s.opts.MaxReplyTokens - EstimateTokens(buildSystemPrompt(conversation.Summary, grounding))
+	kept, overflow := FitHistory(history, budget)
+	if len(overflow) > 0 {
+		summary, err := s.summarise(ctx, conversation.Summary, overflow, disclosure)
+		if err == nil {
+			conversation.Summary = summary
+			conversation.SummarisedUpTo = overflow[len(overflow)-1].ID
+		}
+		// If summarising fails the older turns are simply left out this time
+		// and summarised on a later turn
+	}
+
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: buildSystemPrompt(disclosure.Text(conversation.Summary), grounding)}}
+	for _, m := range kept {
+		messages = append(messages, llm.Message{Role: m.Role, Content: disclosure.Text(m.Content)})
+	}
+
+	resp, err := s.provider.Chat(ctx, llm.Request{
+		Model:       s.opts.Model,
+		Messages:    messages,
+		Temperature: 0.7,
+		MaxTokens:   s.opts.MaxReplyTokens,
+	})
+	s.privacy.Audit(conversation.UserID, usage.FeatureCompanion, disclosure)
+	if err != nil {
+		var quotaErr *usage.QuotaError
+		if errors.As(err, &quotaErr) {
+			return nil, err
+		}
+		return nil, errors.New("companion is unavailable right now, please try again")
+	}
+
+	tokens := resp.Usage.CompletionTokens
+	if tokens <= 0 {
+		tokens = EstimateTokens(resp.Content)
+	}
+	reply := &Message{
+		ConversationID: conversation.ID,
+		Role:           llm.RoleAssistant,
+		Content:        strings.TrimSpace(resp.Content),
+		Tokens:         tokens,
+	}
+	if err := s.repo.CreateMessage(reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// summarise folds older turns into the summary, redacting them first
+func (s *Service) summarise(ctx context.Context, summary string, overflow []Message, disclosure *privacy.Disclosure) (string, error) {
+	redacted := make([]Message, len(overflow))
+	for i, m := range overflow {
+		redacted[i] = m
+		redacted[i].Content = disclosure.Text(m.Content)
+	}
+	resp, err := s.provider.Chat(ctx, buildSummaryRequest(disclosure.Text(summary), redacted, s.opts.Model))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
+
+// grounding gathers the user's recent mood and journal data. Missing data
+// isn't an error; the companion just has less context.
+func (s *Service) grounding(userID uint, disclosure *privacy.Disclosure) string {
+	var moods []mood.MoodEntry
+	if s.moodService != nil {
+		moods, _ = s.moodService.GetRecentEntries(userID, groundingMoods)
+	}
+	var journals []journal.JournalEntry
+	if s.journalRepo != nil {
+		journals, _ = s.journalRepo.GetRecentByUserID(userID, groundingJournals)
+	}
+	return buildGrounding(moods, journals, disclosure)
+}
+
+func (s *Service) getOwned(id, userID uint) (*Conversation, error) {
+	conversation, err := s.repo.GetConversation(id)
+	if err != nil || conversation.UserID != userID {
+		return nil, errors.New("conversation not found")
+	}
+	return conversation, nil
+}
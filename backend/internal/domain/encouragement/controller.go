@@ -4,15 +4,18 @@ import (
 	"net/http"
 	"strconv"
 
+	"armourup/internal/domain/safety"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Controller struct {
 	service *Service
+	safety  *safety.Service
 }
 
-func NewController(service *Service) *Controller {
-	return &Controller{service: service}
+func NewController(service *Service, safetyService *safety.Service) *Controller {
+	return &Controller{service: service, safety: safetyService}
 }
 
 func (c *Controller) CreateEncouragement(ctx *gin.Context) {
@@ -22,6 +25,11 @@ func (c *Controller) CreateEncouragement(ctx *gin.Context) {
 		return
 	}
 
+	if userID, exists := ctx.Get("user_id"); exists {
+		createdBy := userID.(uint)
+		encouragement.CreatedBy = &createdBy
+	}
+
 	if err := c.service.CreateEncouragement(&encouragement); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -73,6 +81,10 @@ func (c *Controller) UpdateEncouragement(ctx *gin.Context) {
 
 	encouragement.ID = uint(id)
 	if err := c.service.UpdateEncouragement(&encouragement); err != nil {
+		if err.Error() == "encouragement not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -96,10 +108,32 @@ func (c *Controller) DeleteEncouragement(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
-type LogStruggleRequest struct {
-	Struggle string `json:"struggle" binding:"required"`
-	Verse    string `json:"verse" binding:"required"`
-	Message  string `json:"message" binding:"required"`
+// GetLibrary lists the encouragement library, optionally filtered with ?category=
+func (c *Controller) GetLibrary(ctx *gin.Context) {
+	encouragements, err := c.service.GetLibrary(ctx.Query("category"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, encouragements)
+}
+
+// DrawRandom returns a random library entry, optionally from ?category=.
+// A category with no entries is not found.
+func (c *Controller) DrawRandom(ctx *gin.Context) {
+	encouragement, err := c.service.DrawRandom(ctx.Query("category"))
+	if err != nil {
+		switch err.Error() {
+		case "no encouragements in this category", "encouragement library is empty":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, encouragement)
 }
 
 func (c *Controller) LogStruggle(ctx *gin.Context) {
@@ -116,17 +150,58 @@ func (c *Controller) LogStruggle(ctx *gin.Context) {
 		return
 	}
 
-	encouragement := &Encouragement{
-		UserID:  userID.(uint),
-		Message: req.Message,
-		Verse:   req.Verse,
-		Type:    "struggle",
+	log, err := c.service.LogStruggle(userID.(uint), req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	log.Safety = c.safety.CheckRequest(ctx, safety.SourceStruggle, req.Struggle).Notice
+
+	ctx.JSON(http.StatusCreated, log)
+}
+
+// GetStruggleLogs returns the authenticated user's struggle history
+func (c *Controller) GetStruggleLogs(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
 	}
 
-	if err := c.service.CreateEncouragement(encouragement); err != nil {
+	logs, err := c.service.GetStruggleLogs(userID.(uint))
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, encouragement)
+	ctx.JSON(http.StatusOK, logs)
+}
+
+// DeleteStruggleLog removes an entry from the authenticated user's struggle history
+func (c *Controller) DeleteStruggleLog(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := c.service.DeleteStruggleLog(uint(id), userID.(uint)); err != nil {
+		switch err.Error() {
+		case "struggle log not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "unauthorized to delete this struggle log":
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
 }
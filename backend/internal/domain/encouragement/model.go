@@ -3,17 +3,44 @@ package encouragement
 import (
 	"time"
 
+	"armourup/internal/domain/safety"
+
 	"gorm.io/gorm"
 )
 
+// Encouragement is an entry in the admin-curated encouragement library
 type Encouragement struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
-	UserID    uint           `json:"user_id"`
 	Message   string         `json:"message" binding:"required"`
-	Type      string         `json:"type" binding:"required"`
-	Category  string         `json:"category" binding:"required"`
-	Verse     string         `json:"verse"`
+	Category  string         `json:"category" binding:"required" gorm:"index"`
+	Tags      string         `json:"tags"`      // Comma-separated, e.g. "fear,anxiety"
+	Reference string         `json:"reference"` // Verse reference, e.g. "Philippians 4:13"
+	Verse     string         `json:"verse"`     // Verse text without the reference
+	CreatedBy *uint          `json:"created_by,omitempty"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
+
+// StruggleLog records a struggle a user shared and the encouragement they received
+type StruggleLog struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	UserID        uint           `json:"user_id" gorm:"not null;index"`
+	Struggle      string         `json:"struggle"`
+	Message       string         `json:"message" gorm:"not null"`
+	Verse         string         `json:"verse"`
+	Category      string         `json:"category"`
+	PromptVersion string         `json:"prompt_version,omitempty"` // Prompt template of the AI encouragement, if any
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	Safety        *safety.Notice `json:"safety,omitempty" gorm:"-"` // Crisis resources when the struggle was flagged
+}
+
+type LogStruggleRequest struct {
+	Struggle      string `json:"struggle" binding:"required"`
+	Verse         string `json:"verse" binding:"required"`
+	Message       string `json:"message" binding:"required"`
+	Category      string `json:"category"`
+	PromptVersion string `json:"prompt_version"` // From the AI encouragement being logged
+}
@@ -28,10 +28,56 @@ func (r *Repository) GetAll() ([]Encouragement, error) {
 	return encouragements, err
 }
 
+// GetByCategory retrieves library entries in a category, or all entries if category is empty
+func (r *Repository) GetByCategory(category string) ([]Encouragement, error) {
+	var encouragements []Encouragement
+	query := r.db.Order("category ASC, id ASC")
+	if category != "" {
+		query = query.Where("LOWER(category) = LOWER(?)", category)
+	}
+	err := query.Find(&encouragements).Error
+	return encouragements, err
+}
+
+// GetRandom draws a random library entry, limited to a category if one is given
+func (r *Repository) GetRandom(category string) (*Encouragement, error) {
+	var encouragement Encouragement
+	query := r.db.Order("RANDOM()")
+	if category != "" {
+		query = query.Where("LOWER(category) = LOWER(?)", category)
+	}
+	err := query.First(&encouragement).Error
+	return &encouragement, err
+}
+
 func (r *Repository) Update(encouragement *Encouragement) error {
 	return r.db.Save(encouragement).Error
 }
 
 func (r *Repository) Delete(id uint) error {
 	return r.db.Delete(&Encouragement{}, id).Error
-} 
\ No newline at end of file
+}
+
+// CreateStruggleLog records a struggle in the user's history
+func (r *Repository) CreateStruggleLog(log *StruggleLog) error {
+	return r.db.Create(log).Error
+}
+
+// GetStruggleLogs retrieves a user's struggle history, newest first
+func (r *Repository) GetStruggleLogs(userID uint) ([]StruggleLog, error) {
+	var logs []StruggleLog
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&logs).Error
+	return logs, err
+}
+
+// GetStruggleLog retrieves a struggle log by ID
+func (r *Repository) GetStruggleLog(id uint) (*StruggleLog, error) {
+	var log StruggleLog
+	err := r.db.First(&log, id).Error
+	return &log, err
+}
+
+// DeleteStruggleLog soft deletes a struggle log
+func (r *Repository) DeleteStruggleLog(id uint) error {
+	return r.db.Delete(&StruggleLog{}, id).Error
+}
@@ -1,5 +1,16 @@
 package encouragement
 
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"unicode"
+
+	"armourup/internal/scripture"
+
+	"gorm.io/gorm"
+)
+
 type Service struct {
 	repo *Repository
 }
@@ -9,6 +20,7 @@ func NewService(repo *Repository) *Service {
 }
 
 func (s *Service) CreateEncouragement(encouragement *Encouragement) error {
+	normalise(encouragement)
 	return s.repo.Create(encouragement)
 }
 
@@ -20,10 +32,175 @@ func (s *Service) GetEncouragements() ([]Encouragement, error) {
 	return s.repo.GetAll()
 }
 
+// GetLibrary retrieves the library, optionally filtered by category
+func (s *Service) GetLibrary(category string) ([]Encouragement, error) {
+	return s.repo.GetByCategory(strings.TrimSpace(category))
+}
+
+// DrawRandom picks a random library entry, from the category if one is given
+func (s *Service) DrawRandom(category string) (*Encouragement, error) {
+	category = strings.TrimSpace(category)
+	enc, err := s.repo.GetRandom(category)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if category != "" {
+			return nil, errors.New("no encouragements in this category")
+		}
+		return nil, errors.New("encouragement library is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return enc, nil
+}
+
+// Match picks a library entry suited to a struggle, for when AI
+// encouragement is unavailable. Entries whose tags or category share the
+// most words with the struggle are preferred, within the category if one is
+// given, and ties are broken at random.
+func (s *Service) Match(struggle, category string) (*Encouragement, error) {
+	entries, err := s.repo.GetAll()
+	if err != nil || len(entries) == 0 {
+		return nil, errors.New("encouragement library is empty")
+	}
+
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category != "" {
+		var inCategory []Encouragement
+		for _, e := range entries {
+			if e.Category == category {
+				inCategory = append(inCategory, e)
+			}
+		}
+		if len(inCategory) > 0 {
+			entries = inCategory
+		}
+	}
+
+	best := BestMatches(entries, struggle)
+	if len(best) == 0 {
+		best = entries
+	}
+	return &best[rand.Intn(len(best))], nil
+}
+
+// BestMatches returns the entries sharing the most keywords with the
+// struggle, or none if no entry shares any
+func BestMatches(entries []Encouragement, struggle string) []Encouragement {
+	words := strings.FieldsFunc(strings.ToLower(struggle), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	var best []Encouragement
+	bestScore := 0
+	for _, e := range entries {
+		score := 0
+		keywords := append(strings.Split(e.Tags, ","), e.Category)
+		for _, keyword := range keywords {
+			if keyword != "" && mentions(words, keyword) {
+				score++
+			}
+		}
+		switch {
+		case score == 0 || score < bestScore:
+		case score > bestScore:
+			best, bestScore = []Encouragement{e}, score
+		default:
+			best = append(best, e)
+		}
+	}
+	return best
+}
+
+// mentions reports whether any word matches the keyword, allowing for
+// different endings of the same word, e.g. "anxious" and "anxiety"
+func mentions(words []string, keyword string) bool {
+	keyword = stem(strings.TrimSpace(keyword))
+	for _, word := range words {
+		if stem(word) == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// stemSuffixes are stripped by stem, longest first
+var stemSuffixes = []string{"iety", "ious", "ness", "ous", "ety", "ied", "ies", "ing", "ed", "es", "s", "y"}
+
+// stem crudely strips a common English ending from a word
+func stem(word string) string {
+	for _, suffix := range stemSuffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 && !strings.HasSuffix(word, "s"+suffix) {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
 func (s *Service) UpdateEncouragement(encouragement *Encouragement) error {
+	existing, err := s.repo.GetByID(encouragement.ID)
+	if err != nil {
+		return errors.New("encouragement not found")
+	}
+
+	normalise(encouragement)
+	encouragement.CreatedBy = existing.CreatedBy
+	encouragement.CreatedAt = existing.CreatedAt
 	return s.repo.Update(encouragement)
 }
 
 func (s *Service) DeleteEncouragement(id uint) error {
 	return s.repo.Delete(id)
-} 
\ No newline at end of file
+}
+
+// LogStruggle records a struggle and the encouragement received in the user's history
+func (s *Service) LogStruggle(userID uint, req LogStruggleRequest) (*StruggleLog, error) {
+	log := &StruggleLog{
+		UserID:        userID,
+		Struggle:      req.Struggle,
+		Message:       req.Message,
+		Verse:         req.Verse,
+		Category:      strings.ToLower(strings.TrimSpace(req.Category)),
+		PromptVersion: req.PromptVersion,
+	}
+	if err := s.repo.CreateStruggleLog(log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// GetStruggleLogs retrieves the user's struggle history
+func (s *Service) GetStruggleLogs(userID uint) ([]StruggleLog, error) {
+	return s.repo.GetStruggleLogs(userID)
+}
+
+// DeleteStruggleLog removes an entry from the user's struggle history
+func (s *Service) DeleteStruggleLog(id, userID uint) error {
+	log, err := s.repo.GetStruggleLog(id)
+	if err != nil {
+		return errors.New("struggle log not found")
+	}
+	if log.UserID != userID {
+		return errors.New("unauthorized to delete this struggle log")
+	}
+	return s.repo.DeleteStruggleLog(id)
+}
+
+// normalise tidies a library entry's category and tags and, when the verse
+// was entered with its reference attached, splits the reference out
+func normalise(encouragement *Encouragement) {
+	encouragement.Category = strings.ToLower(strings.TrimSpace(encouragement.Category))
+
+	var tags []string
+	for _, t := range strings.Split(encouragement.Tags, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	encouragement.Tags = strings.Join(tags, ",")
+
+	encouragement.Reference = strings.TrimSpace(encouragement.Reference)
+	encouragement.Verse = strings.TrimSpace(encouragement.Verse)
+	if encouragement.Reference == "" && encouragement.Verse != "" {
+		encouragement.Reference, encouragement.Verse = scripture.SplitVerse(encouragement.Verse)
+	}
+}
@@ -0,0 +1,122 @@
+package insights
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Chart is a bar chart of an insight's statistics, drawn in exported reports
+type Chart struct {
+	Title string
+	Bars  []Bar
+}
+
+// Bar is one bar of a chart
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// Chart dimensions, in SVG user units
+const (
+	chartWidth  = 480
+	chartHeight = 200
+	chartTop    = 20 // Room for the values above the bars
+	chartBottom = 24 // Room for the labels under the bars
+)
+
+// ReportCharts picks the charts worth drawing for a period's statistics:
+// entries by area, entries by week and the energy distribution. Charts with
+// nothing to show are left out.
+func ReportCharts(stats *InsightStats) []Chart {
+	if stats == nil {
+		return nil
+	}
+
+	var charts []Chart
+	if stats.Entries.Total() > 0 {
+		charts = append(charts, Chart{
+			Title: "Entries by area",
+			Bars: []Bar{
+				{Label: "Moods", Value: float64(stats.Entries.Moods)},
+				{Label: "Journal", Value: float64(stats.Entries.Journals)},
+				{Label: "Gratitude", Value: float64(stats.Entries.Gratitude)},
+				{Label: "Prayers", Value: float64(stats.Entries.PrayersOffered)},
+				{Label: "Answered", Value: float64(stats.Entries.PrayersAnswered)},
+				{Label: "Readings", Value: float64(stats.Entries.Readings)},
+			},
+		})
+	}
+
+	if len(stats.Weeks) > 1 {
+		weekly := Chart{Title: "Entries by week"}
+		active := false
+		for _, week := range stats.Weeks {
+			weekly.Bars = append(weekly.Bars, Bar{Label: week.Week[len(week.Week)-3:], Value: float64(week.Entries)})
+			active = active || week.Entries > 0
+		}
+		if active {
+			charts = append(charts, weekly)
+		}
+	}
+
+	energy := Chart{Title: "Energy levels"}
+	moods := 0
+	for _, level := range stats.Energy.Distribution {
+		energy.Bars = append(energy.Bars, Bar{Label: fmt.Sprint(level.Level), Value: float64(level.Count)})
+		moods += level.Count
+	}
+	if moods > 0 {
+		charts = append(charts, energy)
+	}
+	return charts
+}
+
+// Max is the value of the tallest bar, at least 1 so empty charts scale
+func (c Chart) Max() float64 {
+	tallest := 1.0
+	for _, bar := range c.Bars {
+		if bar.Value > tallest {
+			tallest = bar.Value
+		}
+	}
+	return tallest
+}
+
+// SVG draws the chart as a standalone SVG image, with bars in color
+func (c Chart) SVG(color string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%s">`,
+		chartWidth, chartHeight, chartWidth, chartHeight, html.EscapeString(c.Title))
+	fmt.Fprintf(&b, `<line x1="0" y1="%d" x2="%d" y2="%d" stroke="#d1d5db"/>`, chartHeight-chartBottom, chartWidth, chartHeight-chartBottom)
+
+	if len(c.Bars) > 0 {
+		slot := float64(chartWidth) / float64(len(c.Bars))
+		width := slot * 0.7
+		plot := float64(chartHeight - chartTop - chartBottom)
+		tallest := c.Max()
+		for i, bar := range c.Bars {
+			height := plot * bar.Value / tallest
+			x := float64(i)*slot + (slot-width)/2
+			y := float64(chartHeight-chartBottom) - height
+			center := float64(i)*slot + slot/2
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, x, y, width, height, html.EscapeString(color))
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-family="Helvetica, Arial, sans-serif" font-size="11" text-anchor="middle" fill="#374151">%s</text>`,
+				center, y-4, formatValue(bar.Value))
+			fmt.Fprintf(&b, `<text x="%.1f" y="%d" font-family="Helvetica, Arial, sans-serif" font-size="11" text-anchor="middle" fill="#6b7280">%s</text>`,
+				center, chartHeight-8, html.EscapeString(bar.Label))
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// formatValue prints whole numbers without decimals
+func formatValue(v float64) string {
+	if v == float64(int(v)) {
+		return fmt.Sprint(int(v))
+	}
+	return fmt.Sprintf("%.1f", v)
+}
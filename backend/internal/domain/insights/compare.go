@@ -0,0 +1,217 @@
+package insights
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// PeriodComparison sets two of the user's periods side by side, with the
+// changes from the first to the second
+type PeriodComparison struct {
+	From          PeriodSnapshot `json:"from"`
+	To            PeriodSnapshot `json:"to"`
+	Changes       PeriodChanges  `json:"changes"`
+	Narrative     string         `json:"narrative,omitempty"`      // What changed in words, if asked for
+	NarrativeMode string         `json:"narrative_mode,omitempty"` // ModeAI or ModeRules
+}
+
+// PeriodSnapshot is the aggregated data for one period of a comparison.
+// Gratitude and journals are also given per week, so periods of different
+// lengths compare fairly.
+type PeriodSnapshot struct {
+	Period           string         `json:"period"`
+	Label            string         `json:"label"`
+	Days             int            `json:"days"`
+	MoodEntries      int            `json:"mood_entries"`
+	AvgEnergy        float64        `json:"avg_energy"`
+	EmotionalStates  map[string]int `json:"emotional_states"`
+	SpiritualStates  map[string]int `json:"spiritual_states"`
+	Gratitude        int            `json:"gratitude"`
+	GratitudePerWeek float64        `json:"gratitude_per_week"`
+	Journals         int            `json:"journals"`
+	JournalsPerWeek  float64        `json:"journals_per_week"`
+	PrayersOffered   int            `json:"prayers_offered"`
+	PrayersAnswered  int            `json:"prayers_answered"`
+}
+
+// PeriodChanges are the differences between the two periods, second minus
+// first. Energy is only compared when both periods have mood entries.
+type PeriodChanges struct {
+	AvgEnergy        *float64      `json:"avg_energy,omitempty"`
+	EmotionalStates  []StateChange `json:"emotional_states"`
+	SpiritualStates  []StateChange `json:"spiritual_states"`
+	GratitudePerWeek float64       `json:"gratitude_per_week"`
+	JournalsPerWeek  float64       `json:"journals_per_week"`
+	PrayersOffered   int           `json:"prayers_offered"`
+	PrayersAnswered  int           `json:"prayers_answered"`
+}
+
+// StateChange is how a state's share of mood entries changed, in percent
+type StateChange struct {
+	State  string  `json:"state"`
+	From   float64 `json:"from"`
+	To     float64 `json:"to"`
+	Change float64 `json:"change"`
+}
+
+// ComparePeriods works out the changes between two periods from their
+// aggregated data
+func ComparePeriods(fromPeriod *Period, fromData *InsightData, toPeriod *Period, toData *InsightData) *PeriodComparison {
+	from := snapshot(fromPeriod, fromData)
+	to := snapshot(toPeriod, toData)
+
+	changes := PeriodChanges{
+		EmotionalStates:  stateChanges(from.EmotionalStates, to.EmotionalStates),
+		SpiritualStates:  stateChanges(from.SpiritualStates, to.SpiritualStates),
+		GratitudePerWeek: round1(to.GratitudePerWeek - from.GratitudePerWeek),
+		JournalsPerWeek:  round1(to.JournalsPerWeek - from.JournalsPerWeek),
+		PrayersOffered:   to.PrayersOffered - from.PrayersOffered,
+		PrayersAnswered:  to.PrayersAnswered - from.PrayersAnswered,
+	}
+	if from.MoodEntries > 0 && to.MoodEntries > 0 {
+		change := round1(to.AvgEnergy - from.AvgEnergy)
+		changes.AvgEnergy = &change
+	}
+
+	return &PeriodComparison{From: from, To: to, Changes: changes}
+}
+
+func snapshot(period *Period, data *InsightData) PeriodSnapshot {
+	days := int(period.End.Sub(period.Start).Hours()/24) + 1
+	weeks := float64(days) / 7
+
+	emotional := map[string]int{}
+	for state, count := range data.TopEmotions {
+		if state != "" {
+			emotional[state] = count
+		}
+	}
+	spiritual := map[string]int{}
+	for state, count := range data.TopSpiritual {
+		if state != "" {
+			spiritual[state] = count
+		}
+	}
+
+	return PeriodSnapshot{
+		Period:           period.Key,
+		Label:            period.Label(),
+		Days:             days,
+		MoodEntries:      len(data.MoodEntries),
+		AvgEnergy:        round1(data.AvgEnergyLevel),
+		EmotionalStates:  emotional,
+		SpiritualStates:  spiritual,
+		Gratitude:        data.GratitudeCount,
+		GratitudePerWeek: round1(float64(data.GratitudeCount) / weeks),
+		Journals:         len(data.JournalEntries),
+		JournalsPerWeek:  round1(float64(len(data.JournalEntries)) / weeks),
+		PrayersOffered:   data.PrayersOffered,
+		PrayersAnswered:  data.PrayersAnswered,
+	}
+}
+
+// stateChanges compares each state's share of the mood entries in the two
+// periods, biggest changes first and then by name
+func stateChanges(from, to map[string]int) []StateChange {
+	share := func(counts map[string]int) map[string]float64 {
+		total := 0
+		for _, count := range counts {
+			total += count
+		}
+		shares := map[string]float64{}
+		for state, count := range counts {
+			shares[state] = round1(100 * float64(count) / float64(total))
+		}
+		return shares
+	}
+	fromShares, toShares := share(from), share(to)
+
+	states := map[string]bool{}
+	for state := range from {
+		states[state] = true
+	}
+	for state := range to {
+		states[state] = true
+	}
+
+	changes := make([]StateChange, 0, len(states))
+	for state := range states {
+		changes = append(changes, StateChange{
+			State:  state,
+			From:   fromShares[state],
+			To:     toShares[state],
+			Change: round1(toShares[state] - fromShares[state]),
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := math.Abs(changes[i].Change), math.Abs(changes[j].Change)
+		if a != b {
+			return a > b
+		}
+		return changes[i].State < changes[j].State
+	})
+	return changes
+}
+
+// RulesNarrative describes what changed between the periods without AI
+func RulesNarrative(comparison *PeriodComparison) string {
+	from, to, changes := comparison.From, comparison.To, comparison.Changes
+	sentences := []string{fmt.Sprintf("Comparing %s with %s:", from.Label, to.Label)}
+
+	if changes.AvgEnergy != nil {
+		switch {
+		case *changes.AvgEnergy > 0:
+			sentences = append(sentences, fmt.Sprintf("your average energy rose from %.1f to %.1f.", from.AvgEnergy, to.AvgEnergy))
+		case *changes.AvgEnergy < 0:
+			sentences = append(sentences, fmt.Sprintf("your average energy fell from %.1f to %.1f.", from.AvgEnergy, to.AvgEnergy))
+		default:
+			sentences = append(sentences, fmt.Sprintf("your average energy held steady at %.1f.", to.AvgEnergy))
+		}
+	} else {
+		sentences = append(sentences, "there aren't mood entries in both periods to compare your energy.")
+	}
+
+	if len(changes.EmotionalStates) > 0 && changes.EmotionalStates[0].Change != 0 {
+		state := changes.EmotionalStates[0]
+		direction := "more"
+		if state.Change < 0 {
+			direction = "less"
+		}
+		sentences = append(sentences, fmt.Sprintf("You felt %s %s often (%.0f%% of check-ins, from %.0f%%).", state.State, direction, state.To, state.From))
+	}
+
+	sentences = append(sentences, fmt.Sprintf("You gave thanks %s and journaled %s.",
+		trend(changes.GratitudePerWeek, "per week"), trend(changes.JournalsPerWeek, "per week")))
+
+	prayers := fmt.Sprintf("You prayed %s", countTrend(changes.PrayersOffered, "time", "times"))
+	if to.PrayersAnswered > 0 {
+		prayers += fmt.Sprintf(", and saw %s answered", plural(to.PrayersAnswered, "prayer", "prayers"))
+	}
+	sentences = append(sentences, prayers+".")
+
+	return strings.Join(sentences, " ")
+}
+
+func trend(change float64, unit string) string {
+	switch {
+	case change > 0:
+		return fmt.Sprintf("%.1f more times %s", change, unit)
+	case change < 0:
+		return fmt.Sprintf("%.1f fewer times %s", -change, unit)
+	default:
+		return "as often as before"
+	}
+}
+
+func countTrend(change int, one, many string) string {
+	switch {
+	case change > 0:
+		return plural(change, one, many) + " more"
+	case change < 0:
+		return plural(-change, one, many) + " fewer"
+	default:
+		return "as often as before"
+	}
+}
@@ -1,22 +1,30 @@
 package insights
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"armourup/internal/domain/usage"
+	"armourup/internal/jobs"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Controller struct {
-	service *Service
+	service  *Service
+	exporter *Exporter
 }
 
-func NewController(service *Service) *Controller {
-	return &Controller{service: service}
+func NewController(service *Service, exporter *Exporter) *Controller {
+	return &Controller{service: service, exporter: exporter}
 }
 
-// GenerateInsight generates a new monthly progress insight
+// GenerateInsight queues generation of a progress insight for a week,
+// month, quarter, year or custom range, or
+// returns the insight if it already exists
 func (c *Controller) GenerateInsight(ctx *gin.Context) {
 	userID, exists := ctx.Get("user_id")
 	if !exists {
@@ -30,13 +38,165 @@ func (c *Controller) GenerateInsight(ctx *gin.Context) {
 		return
 	}
 
-	insight, err := c.service.GenerateInsight(userID.(uint), req.Period)
+	insight, job, err := c.service.RequestInsight(userID.(uint), req.Period)
+	c.respondToRequest(ctx, insight, job, err)
+}
+
+// respondToRequest returns an existing insight, or 202 Accepted with the job
+// generating it
+func (c *Controller) respondToRequest(ctx *gin.Context, insight *ProgressInsight, job *jobs.Job, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPeriod):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, errNoMonthlyInsights):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if insight != nil {
+		ctx.JSON(http.StatusOK, insight)
+		return
+	}
+
+	respondAccepted(ctx, job)
+}
+
+// respondAccepted returns 202 Accepted pointing at the job's status
+func respondAccepted(ctx *gin.Context, job *jobs.Job) {
+	statusURL := JobStatusURL(job.ID)
+	ctx.Header("Location", statusURL)
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"job_id":     job.ID,
+		"status":     job.Status,
+		"status_url": statusURL,
+	})
+}
+
+// RegenerateInsight queues regeneration of an insight, keeping the version
+// it replaces
+func (c *Controller) RegenerateInsight(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	job, err := c.service.RegenerateInsight(userID.(uint), uint(id))
+	if err != nil {
+		switch err.Error() {
+		case "insight not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	respondAccepted(ctx, job)
+}
+
+// GetVersions lists the earlier versions of an insight
+func (c *Controller) GetVersions(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	versions, err := c.service.GetVersions(userID.(uint), uint(id))
+	if err != nil {
+		if err.Error() == "insight not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, versions)
+}
+
+// RateInsight records whether an insight was helpful
+func (c *Controller) RateInsight(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	var req RateInsightRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rating, err := c.service.RateInsight(userID.(uint), uint(id), req)
+	if err != nil {
+		if err.Error() == "insight not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rating)
+}
+
+// GetPromptRatings summarises insight ratings by prompt version, for
+// comparing prompt versions
+func (c *Controller) GetPromptRatings(ctx *gin.Context) {
+	ratings, err := c.service.GetPromptRatings()
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, insight)
+	ctx.JSON(http.StatusOK, ratings)
+}
+
+// GetJob reports on an insight being generated
+func (c *Controller) GetJob(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	status, err := c.service.GetJob(userID.(uint), uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
 }
 
 // GetInsight retrieves a specific progress insight
@@ -57,6 +217,41 @@ func (c *Controller) GetInsight(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, insight)
 }
 
+// ExportInsight downloads one of the user's insights as a PDF (the
+// default), Markdown or HTML report
+func (c *Controller) ExportInsight(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	insight, err := c.service.GetUserInsight(userID.(uint), uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := c.exporter.Export(insight, ctx.DefaultQuery("format", FormatPDF))
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedFormat) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", report.Filename))
+	ctx.Data(http.StatusOK, report.ContentType, report.Body)
+}
+
 // GetUserInsights retrieves all progress insights for the authenticated user
 func (c *Controller) GetUserInsights(ctx *gin.Context) {
 	userID, exists := ctx.Get("user_id")
@@ -74,7 +269,8 @@ func (c *Controller) GetUserInsights(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, insights)
 }
 
-// GetInsightForPeriod retrieves or generates an insight for a specific period
+// GetInsightForPeriod retrieves the insight for a specific period, queueing
+// its generation if it doesn't exist yet
 func (c *Controller) GetInsightForPeriod(ctx *gin.Context) {
 	userID, exists := ctx.Get("user_id")
 	if !exists {
@@ -88,37 +284,87 @@ func (c *Controller) GetInsightForPeriod(ctx *gin.Context) {
 		period = time.Now().Format("2006-01")
 	}
 
-	insight, err := c.service.GetInsightForPeriod(userID.(uint), period)
+	insight, job, err := c.service.RequestInsight(userID.(uint), period)
+	c.respondToRequest(ctx, insight, job, err)
+}
+
+// GetStats returns the statistics for a period, the current month by
+// default. They are computed without AI.
+func (c *Controller) GetStats(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	period := ctx.Query("period")
+	if period == "" {
+		period = time.Now().Format("2006-01")
+	}
+
+	stats, err := c.service.GetStats(userID.(uint), period)
 	if err != nil {
+		if errors.Is(err, ErrInvalidPeriod) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, insight)
+	ctx.JSON(http.StatusOK, stats)
 }
 
-// GetAvailablePeriods returns a list of months that have data
-func (c *Controller) GetAvailablePeriods(ctx *gin.Context) {
+// Compare returns the changes between two periods, given as from and to.
+// With narrative=true what changed is also described in words.
+func (c *Controller) Compare(ctx *gin.Context) {
 	userID, exists := ctx.Get("user_id")
 	if !exists {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
 
-	insights, err := c.service.GetUserInsights(userID.(uint))
+	from, to := ctx.Query("from"), ctx.Query("to")
+	if from == "" || to == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from and to periods are required"})
+		return
+	}
+
+	comparison, err := c.service.Compare(ctx.Request.Context(), userID.(uint), from, to, ctx.Query("narrative") == "true")
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if usage.RespondIfQuotaExceeded(ctx, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, ErrInvalidPeriod), errors.Is(err, errSamePeriod):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	// Extract unique periods
-	periods := make([]string, 0, len(insights))
-	for _, insight := range insights {
-		periods = append(periods, insight.Period)
+	ctx.JSON(http.StatusOK, comparison)
+}
+
+// GetAvailablePeriods returns the weeks, months, quarters and years that
+// have data, and the periods that already have insights
+func (c *Controller) GetAvailablePeriods(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	available, generated, err := c.service.GetAvailablePeriods(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"periods": periods,
+		"periods":   generated,
+		"available": available,
 	})
 }
 
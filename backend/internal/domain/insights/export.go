@@ -0,0 +1,201 @@
+package insights
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Export formats
+const (
+	FormatPDF      = "pdf"
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+)
+
+// ErrUnsupportedFormat is returned when exporting to a format we can't render
+var ErrUnsupportedFormat = errors.New("unsupported format, use pdf, md or html")
+
+// Report templates, which can be replaced by files of the same name in the
+// exporter's template directory
+const (
+	markdownTemplate = "report.md.tmpl"
+	htmlTemplate     = "report.html.tmpl"
+)
+
+//go:embed templates
+var reportTemplates embed.FS
+
+// defaultColor is the accent colour used when the branding doesn't set a
+// valid one
+const defaultColor = "#1d4ed8"
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Branding is the name, tagline, accent colour (as #rrggbb) and footer that
+// exported reports carry
+type Branding struct {
+	Name    string
+	Tagline string
+	Color   string
+	Footer  string
+}
+
+// Exporter renders insights as reports to print or share
+type Exporter struct {
+	branding Branding
+	markdown *texttemplate.Template
+	html     *htmltemplate.Template
+}
+
+// Report is a rendered insight, ready to download
+type Report struct {
+	ContentType string
+	Filename    string
+	Body        []byte
+}
+
+// NewExporter loads the report templates. If dir is set, templates there
+// take precedence over the built-in ones with the same name.
+func NewExporter(branding Branding, dir string) (*Exporter, error) {
+	if !colorPattern.MatchString(branding.Color) {
+		branding.Color = defaultColor
+	}
+	if branding.Name == "" {
+		branding.Name = "ArmourUp"
+	}
+
+	markdownText, err := readReportTemplate(dir, markdownTemplate)
+	if err != nil {
+		return nil, err
+	}
+	markdown, err := texttemplate.New(markdownTemplate).Parse(markdownText)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlText, err := readReportTemplate(dir, htmlTemplate)
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New(htmlTemplate).Parse(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Exporter{branding: branding, markdown: markdown, html: html}, nil
+}
+
+func readReportTemplate(dir, name string) (string, error) {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	data, err := reportTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Export renders an insight, with its statistics, charts and verse, in one
+// of the export formats
+func (e *Exporter) Export(insight *ProgressInsight, format string) (*Report, error) {
+	data := e.reportData(insight)
+	report := &Report{Filename: ReportFilename(insight, format)}
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatMarkdown:
+		if err := e.markdown.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		report.ContentType = "text/markdown; charset=utf-8"
+	case FormatHTML:
+		if err := e.html.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		report.ContentType = "text/html; charset=utf-8"
+	case FormatPDF:
+		if err := renderPDF(&buf, data); err != nil {
+			return nil, err
+		}
+		report.ContentType = "application/pdf"
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	report.Body = buf.Bytes()
+	return report, nil
+}
+
+// ReportFilename is what an exported insight is saved as, e.g.
+// "insight-2025-01.pdf"
+func ReportFilename(insight *ProgressInsight, format string) string {
+	return "insight-" + strings.ReplaceAll(insight.Period, "..", "-to-") + "." + format
+}
+
+// reportData is what the report templates are rendered with
+type reportData struct {
+	Branding   Branding
+	Title      string
+	Noun       string
+	Mode       string // Who wrote the insight, in words
+	Insight    *ProgressInsight
+	Paragraphs []string
+	Stats      *InsightStats
+	Charts     []reportChart
+}
+
+// reportChart is a chart drawn for each format
+type reportChart struct {
+	Chart
+	SVG     htmltemplate.HTML // Inline, for HTML
+	DataURI string            // As an image link, for Markdown
+}
+
+func (e *Exporter) reportData(insight *ProgressInsight) reportData {
+	label, noun := insight.Period, "period"
+	if period, err := ParsePeriod(insight.Period); err == nil {
+		label, noun = period.Label(), period.Noun()
+	}
+
+	data := reportData{
+		Branding: e.branding,
+		Title:    "Your insight for " + label,
+		Noun:     noun,
+		Mode:     "Written by AI from your entries",
+		Insight:  insight,
+		Stats:    insight.Stats,
+	}
+	if insight.Mode == ModeRules {
+		data.Mode = "Written from your entries"
+	}
+	for _, paragraph := range strings.Split(insight.Summary, "\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			data.Paragraphs = append(data.Paragraphs, paragraph)
+		}
+	}
+	for _, chart := range ReportCharts(insight.Stats) {
+		svg := chart.SVG(e.branding.Color)
+		data.Charts = append(data.Charts, reportChart{
+			Chart:   chart,
+			SVG:     htmltemplate.HTML(svg), // Built from escaped values by Chart.SVG
+			DataURI: "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg)),
+		})
+	}
+	return data
+}
@@ -3,39 +3,101 @@ package insights
 import (
 	"time"
 
+	"armourup/internal/domain/mood"
+
 	"gorm.io/gorm"
 )
 
-// ProgressInsight represents an AI-generated monthly summary of spiritual growth
+// ProgressInsight represents a summary of spiritual growth over a period,
+// written by AI or, without an LLM provider, from templated rules
 type ProgressInsight struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	UserID      uint           `json:"user_id"`
-	Period      string         `json:"period"` // Format: "2024-01" for January 2024
-	Summary     string         `json:"summary"`
-	Highlights  string         `json:"highlights"`
-	Areas       string         `json:"areas" gorm:"column:areas"`
-	Verse       string         `json:"verse"`
-	MoodStats   string         `json:"mood_stats" gorm:"column:mood_stats"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	UserID        uint           `json:"user_id" gorm:"uniqueIndex:idx_progress_insights_user_period,where:deleted_at IS NULL"`
+	Period        string         `json:"period" gorm:"uniqueIndex:idx_progress_insights_user_period,where:deleted_at IS NULL"` // e.g. "2024-01", "2024-W07", "2024-Q1", "2024" or "2024-01-10..2024-02-05"
+	Summary       string         `json:"summary"`
+	Highlights    []string       `json:"highlights" gorm:"type:jsonb;serializer:json"`
+	Areas         []string       `json:"areas" gorm:"column:areas;type:jsonb;serializer:json"`
+	Verse         string         `json:"verse"`
+	Stats         *InsightStats  `json:"stats" gorm:"type:jsonb;serializer:json"`
+	PromptVersion string         `json:"prompt_version,omitempty"`                  // Prompt template that generated the insight
+	Mode          string         `json:"mode" gorm:"size:10;not null;default:'ai'"` // How it was generated: ModeAI or ModeRules
+	Version       int            `json:"version" gorm:"not null;default:1"`         // Incremented each time the insight is regenerated
+	Provisional   bool           `json:"provisional"`                               // Generated before the period ended; refreshed once it has
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// InsightVersion is an earlier version of an insight, kept when it is regenerated
+type InsightVersion struct {
+	ID            uint          `json:"id" gorm:"primaryKey"`
+	InsightID     uint          `json:"insight_id" gorm:"not null;uniqueIndex:idx_insight_versions_insight_version"`
+	Version       int           `json:"version" gorm:"not null;uniqueIndex:idx_insight_versions_insight_version"`
+	Summary       string        `json:"summary"`
+	Highlights    []string      `json:"highlights" gorm:"type:jsonb;serializer:json"`
+	Areas         []string      `json:"areas" gorm:"type:jsonb;serializer:json"`
+	Verse         string        `json:"verse"`
+	Stats         *InsightStats `json:"stats" gorm:"type:jsonb;serializer:json"`
+	PromptVersion string        `json:"prompt_version,omitempty"`
+	Mode          string        `json:"mode" gorm:"size:10;not null;default:'ai'"`
+	Provisional   bool          `json:"provisional"`
+	GeneratedAt   time.Time     `json:"generated_at"` // When this version was generated
+	CreatedAt     time.Time     `json:"created_at"`   // When it was replaced
+}
+
+func (InsightVersion) TableName() string {
+	return "progress_insight_versions"
+}
+
+// InsightRating is a user's verdict on one version of an insight, used to
+// compare prompt versions
+type InsightRating struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	InsightID     uint      `json:"insight_id" gorm:"not null;uniqueIndex:idx_insight_ratings_user_version"`
+	UserID        uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_insight_ratings_user_version"`
+	Version       int       `json:"version" gorm:"not null;uniqueIndex:idx_insight_ratings_user_version"`
+	PromptVersion string    `json:"prompt_version" gorm:"index"`
+	Helpful       bool      `json:"helpful"`
+	Comment       string    `json:"comment,omitempty" gorm:"size:500"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// RateInsightRequest represents the request to rate an insight
+type RateInsightRequest struct {
+	Helpful *bool  `json:"helpful" binding:"required"`
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+// PromptRatings summarises the ratings of insights from one prompt version
+type PromptRatings struct {
+	PromptVersion string  `json:"prompt_version"`
+	Helpful       int64   `json:"helpful"`
+	NotHelpful    int64   `json:"not_helpful"`
+	HelpfulRate   float64 `json:"helpful_rate"`
 }
 
 // InsightData represents aggregated data used to generate insights
 type InsightData struct {
-	Period          string
-	MoodEntries     []MoodSummary
-	JournalEntries  []JournalSummary
-	GratitudeCount  int
-	PrayersOffered  int
-	PrayersAnswered int
-	AvgEnergyLevel  float64
-	TopEmotions     map[string]int
-	TopSpiritual    map[string]int
+	Period            string
+	Label             string // The period in words, e.g. "Q1 2025 (January to March)"
+	Noun              string // What the period is called: "week", "month", "quarter" or "period"
+	MoodEntries       []MoodSummary
+	JournalEntries    []JournalSummary
+	GratitudeCount    int
+	PrayersOffered    int
+	PrayersAnswered   int
+	ReadingsCompleted int
+	AvgEnergyLevel    float64
+	TopEmotions       map[string]int
+	TopSpiritual      map[string]int
+	Stats             *InsightStats      // Streaks and week-by-week changes, if computed
+	MoodFindings      []mood.Correlation // For a month, the strongest links between practices and mood
 }
 
 // MoodSummary represents summarized mood data
 type MoodSummary struct {
+	ID             uint
 	Date           time.Time
 	EmotionalState string
 	SpiritualState string
@@ -45,13 +107,26 @@ type MoodSummary struct {
 
 // JournalSummary represents summarized journal data
 type JournalSummary struct {
+	ID      uint
 	Date    time.Time
 	Content string
 }
 
 // GenerateInsightRequest represents the request to generate a new insight
 type GenerateInsightRequest struct {
-	Period string `json:"period" binding:"required"` // Format: "2024-01"
+	Period string `json:"period" binding:"required"` // e.g. "2024-01", "2024-W07", "2024-Q1", "2024" or "2024-01-10..2024-02-05"
+}
+
+// InsightJob reports on an insight being generated in the background. The
+// insight is included once the job has succeeded.
+type InsightJob struct {
+	JobID     uint             `json:"job_id"`
+	Status    string           `json:"status"` // queued, running, succeeded or dead
+	Period    string           `json:"period"`
+	Attempts  int              `json:"attempts"`
+	Error     string           `json:"error,omitempty"`
+	StatusURL string           `json:"status_url"`
+	Insight   *ProgressInsight `json:"insight,omitempty"`
 }
 
 // InsightResponse represents the response with insight data
@@ -0,0 +1,178 @@
+package insights
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// PDF layout, in millimetres on A4
+const (
+	pdfMargin      = 20.0
+	pdfLineHeight  = 6.0
+	pdfChartHeight = 45.0
+)
+
+// renderPDF draws the report as an A4 PDF. The core fonts only cover
+// Windows-1252, so text is translated to it.
+func renderPDF(w io.Writer, data reportData) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.SetTitle(data.Branding.Name+": "+data.Title, true)
+	pdf.SetCreator(data.Branding.Name, true)
+	pdf.AliasNbPages("")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	r, g, b := hexColor(data.Branding.Color)
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*pdfMargin
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(107, 114, 128)
+		footer := data.Mode
+		if data.Branding.Footer != "" {
+			footer += " - " + data.Branding.Footer
+		}
+		pdf.CellFormat(contentWidth/2, 10, tr(footer), "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth/2, 10, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// Branded header
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.SetTextColor(r, g, b)
+	pdf.CellFormat(contentWidth, 8, tr(data.Branding.Name), "", 1, "L", false, 0, "")
+	if data.Branding.Tagline != "" {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.SetTextColor(107, 114, 128)
+		pdf.CellFormat(contentWidth, 6, tr(data.Branding.Tagline), "", 1, "L", false, 0, "")
+	}
+	pdf.SetDrawColor(r, g, b)
+	pdf.SetLineWidth(0.8)
+	pdf.Line(pdfMargin, pdf.GetY()+2, pageWidth-pdfMargin, pdf.GetY()+2)
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(31, 41, 55)
+	pdf.MultiCell(contentWidth, 9, tr(data.Title), "", "L", false)
+	pdf.Ln(3)
+
+	pdf.SetFont("Helvetica", "", 11)
+	for _, paragraph := range data.Paragraphs {
+		pdf.MultiCell(contentWidth, pdfLineHeight, tr(paragraph), "", "L", false)
+		pdf.Ln(2)
+	}
+
+	heading := func(text string) {
+		pdf.Ln(3)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.SetTextColor(r, g, b)
+		pdf.CellFormat(contentWidth, 8, tr(text), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.SetTextColor(31, 41, 55)
+	}
+	bullets := func(items []string) {
+		for _, item := range items {
+			pdf.CellFormat(6, pdfLineHeight, tr("•"), "", 0, "L", false, 0, "")
+			pdf.MultiCell(contentWidth-6, pdfLineHeight, tr(item), "", "L", false)
+		}
+	}
+	if len(data.Insight.Highlights) > 0 {
+		heading("Highlights")
+		bullets(data.Insight.Highlights)
+	}
+	if len(data.Insight.Areas) > 0 {
+		heading("Areas for growth")
+		bullets(data.Insight.Areas)
+	}
+
+	if stats := data.Stats; stats != nil {
+		heading("Your " + data.Noun + " in numbers")
+		rows := [][2]string{
+			{"Mood check-ins", strconv.Itoa(stats.Entries.Moods)},
+			{"Journal entries", strconv.Itoa(stats.Entries.Journals)},
+			{"Gratitude entries", strconv.Itoa(stats.Entries.Gratitude)},
+			{"Prayers offered", strconv.Itoa(stats.Entries.PrayersOffered)},
+			{"Prayers answered", strconv.Itoa(stats.Entries.PrayersAnswered)},
+			{"Bible readings", strconv.Itoa(stats.Entries.Readings)},
+			{"Active days", strconv.Itoa(stats.ActiveDays)},
+			{"Longest streak", fmt.Sprintf("%d days", stats.Streaks.Longest)},
+			{"Average energy", fmt.Sprintf("%.1f/10", stats.Energy.Average)},
+		}
+		for _, row := range rows {
+			pdf.SetFont("Helvetica", "", 11)
+			pdf.CellFormat(50, pdfLineHeight, row[0], "", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.CellFormat(30, pdfLineHeight, row[1], "", 1, "L", false, 0, "")
+		}
+	}
+
+	for _, chart := range data.Charts {
+		// Keep each chart and its title on one page
+		_, pageHeight := pdf.GetPageSize()
+		if pdf.GetY()+pdfChartHeight+20 > pageHeight-pdfMargin {
+			pdf.AddPage()
+		}
+		heading(chart.Title)
+		drawChart(pdf, chart.Chart, pdfMargin, pdf.GetY()+2, contentWidth, pdfChartHeight, r, g, b)
+	}
+
+	if data.Insight.Verse != "" {
+		pdf.Ln(6)
+		pdf.SetFillColor(249, 250, 251)
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.SetTextColor(55, 65, 81)
+		pdf.MultiCell(contentWidth, pdfLineHeight+1, tr(data.Insight.Verse), "L", "L", true)
+	}
+
+	return pdf.Output(w)
+}
+
+// drawChart draws a bar chart in the box at x, y, leaving the cursor under it
+func drawChart(pdf *fpdf.Fpdf, chart Chart, x, y, width, height float64, r, g, b int) {
+	const labelHeight, valueHeight = 5.0, 5.0
+	plot := height - labelHeight - valueHeight
+	base := y + valueHeight + plot
+
+	pdf.SetDrawColor(209, 213, 219)
+	pdf.SetLineWidth(0.2)
+	pdf.Line(x, base, x+width, base)
+
+	if len(chart.Bars) > 0 {
+		slot := width / float64(len(chart.Bars))
+		barWidth := slot * 0.7
+		tallest := chart.Max()
+		pdf.SetFont("Helvetica", "", 8)
+		for i, bar := range chart.Bars {
+			barHeight := plot * bar.Value / tallest
+			left := x + float64(i)*slot
+			pdf.SetFillColor(r, g, b)
+			if barHeight > 0 {
+				pdf.Rect(left+(slot-barWidth)/2, base-barHeight, barWidth, barHeight, "F")
+			}
+			pdf.SetTextColor(55, 65, 81)
+			pdf.SetXY(left, base-barHeight-valueHeight)
+			pdf.CellFormat(slot, valueHeight, formatValue(bar.Value), "", 0, "C", false, 0, "")
+			pdf.SetTextColor(107, 114, 128)
+			pdf.SetXY(left, base)
+			pdf.CellFormat(slot, labelHeight, bar.Label, "", 0, "C", false, 0, "")
+		}
+	}
+
+	pdf.SetXY(x, y+height+2)
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetTextColor(31, 41, 55)
+}
+
+// hexColor splits a #rrggbb colour into its components
+func hexColor(color string) (int, int, int) {
+	if !colorPattern.MatchString(color) {
+		color = defaultColor
+	}
+	value, _ := strconv.ParseUint(color[1:], 16, 32)
+	return int(value >> 16 & 0xff), int(value >> 8 & 0xff), int(value & 0xff)
+}
@@ -0,0 +1,200 @@
+package insights
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period granularities
+const (
+	GranularityWeek    = "week"
+	GranularityMonth   = "month"
+	GranularityQuarter = "quarter"
+	GranularityYear    = "year"
+	GranularityCustom  = "custom"
+)
+
+// maxCustomDays limits how long a custom period may be
+const maxCustomDays = 366
+
+// ErrInvalidPeriod is returned, possibly wrapped with more detail, for a
+// period that can't be parsed
+var ErrInvalidPeriod = errors.New("invalid period, use YYYY-MM, YYYY-Www, YYYY-Qn, YYYY or YYYY-MM-DD..YYYY-MM-DD")
+
+var (
+	weekPattern    = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+	quarterPattern = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+	yearPattern    = regexp.MustCompile(`^\d{4}$`)
+)
+
+// Period is a span of time an insight covers. Key is how it is written in
+// requests and stored on insights, e.g. "2025-W07", "2025-Q1", "2025-01",
+// "2025" or "2025-01-10..2025-02-05". End is the last second of the period.
+type Period struct {
+	Key         string
+	Granularity string
+	Start       time.Time
+	End         time.Time
+}
+
+// ParsePeriod parses a period key. Weeks are ISO weeks, starting on Monday;
+// custom ranges include both dates and can be at most a year long.
+func ParsePeriod(key string) (*Period, error) {
+	key = strings.TrimSpace(key)
+	period := &Period{Key: key}
+
+	switch {
+	case weekPattern.MatchString(key):
+		m := weekPattern.FindStringSubmatch(key)
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		if week < 1 || week > isoWeeksInYear(year) {
+			return nil, fmt.Errorf("%w: %d has no week %d", ErrInvalidPeriod, year, week)
+		}
+		period.Granularity = GranularityWeek
+		period.Start = isoWeekStart(year, week)
+		period.End = period.Start.AddDate(0, 0, 7).Add(-time.Second)
+
+	case quarterPattern.MatchString(key):
+		m := quarterPattern.FindStringSubmatch(key)
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		period.Granularity = GranularityQuarter
+		period.Start = time.Date(year, time.Month(3*(quarter-1)+1), 1, 0, 0, 0, 0, time.UTC)
+		period.End = period.Start.AddDate(0, 3, 0).Add(-time.Second)
+
+	case yearPattern.MatchString(key):
+		year, _ := strconv.Atoi(key)
+		period.Granularity = GranularityYear
+		period.Start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		period.End = period.Start.AddDate(1, 0, 0).Add(-time.Second)
+
+	case strings.Contains(key, ".."):
+		parts := strings.SplitN(key, "..", 2)
+		start, err := time.Parse("2006-01-02", parts[0])
+		if err != nil {
+			return nil, ErrInvalidPeriod
+		}
+		end, err := time.Parse("2006-01-02", parts[1])
+		if err != nil {
+			return nil, ErrInvalidPeriod
+		}
+		if end.Before(start) {
+			return nil, fmt.Errorf("%w: the range ends before it starts", ErrInvalidPeriod)
+		}
+		if end.Sub(start) >= maxCustomDays*24*time.Hour {
+			return nil, fmt.Errorf("%w: custom ranges can be at most %d days", ErrInvalidPeriod, maxCustomDays)
+		}
+		period.Granularity = GranularityCustom
+		period.Start = start
+		period.End = end.AddDate(0, 0, 1).Add(-time.Second)
+
+	default:
+		start, err := time.Parse("2006-01", key)
+		if err != nil {
+			return nil, ErrInvalidPeriod
+		}
+		period.Granularity = GranularityMonth
+		period.Start = start
+		period.End = start.AddDate(0, 1, 0).Add(-time.Second)
+	}
+
+	return period, nil
+}
+
+// Label describes the period in words for prompts, e.g. "the week of
+// 10 February 2025", "Q1 2025 (January to March)" or "January 2025"
+func (p *Period) Label() string {
+	switch p.Granularity {
+	case GranularityWeek:
+		return "the week of " + p.Start.Format("2 January 2006")
+	case GranularityQuarter:
+		quarter := (int(p.Start.Month())-1)/3 + 1
+		return fmt.Sprintf("Q%d %d (%s to %s)", quarter, p.Start.Year(), p.Start.Month(), p.End.Month())
+	case GranularityYear:
+		return strconv.Itoa(p.Start.Year())
+	case GranularityCustom:
+		return p.Start.Format("2 January 2006") + " to " + p.End.Format("2 January 2006")
+	default:
+		return p.Start.Format("January 2006")
+	}
+}
+
+// Noun is what the period is called in prompts: "week", "month", "quarter",
+// "year" or "period"
+func (p *Period) Noun() string {
+	if p.Granularity == GranularityCustom {
+		return "period"
+	}
+	return p.Granularity
+}
+
+// Months lists the keys of the calendar months the period overlaps
+func (p *Period) Months() []string {
+	var months []string
+	month := time.Date(p.Start.Year(), p.Start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for !month.After(p.End) {
+		months = append(months, month.Format("2006-01"))
+		month = month.AddDate(0, 1, 0)
+	}
+	return months
+}
+
+// isoWeekStart returns the Monday starting an ISO week. Week 1 is the week
+// containing 4 January.
+func isoWeekStart(year, week int) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan4.Weekday()) + 6) % 7 // Days since Monday
+	return jan4.AddDate(0, 0, -offset+7*(week-1))
+}
+
+// isoWeeksInYear is 53 for years whose 28 December falls in week 53, else 52
+func isoWeeksInYear(year int) int {
+	_, week := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return week
+}
+
+// AvailablePeriods lists, newest first, the periods at each granularity
+// in which the user recorded anything
+type AvailablePeriods struct {
+	Weeks    []string `json:"weeks"`
+	Months   []string `json:"months"`
+	Quarters []string `json:"quarters"`
+	Years    []string `json:"years"`
+}
+
+// PeriodsForDates works out the periods covering a set of activity dates
+func PeriodsForDates(dates []time.Time) AvailablePeriods {
+	weeks := map[string]bool{}
+	months := map[string]bool{}
+	quarters := map[string]bool{}
+	years := map[string]bool{}
+	for _, date := range dates {
+		year, week := date.ISOWeek()
+		weeks[fmt.Sprintf("%d-W%02d", year, week)] = true
+		months[date.Format("2006-01")] = true
+		quarters[fmt.Sprintf("%d-Q%d", date.Year(), (int(date.Month())-1)/3+1)] = true
+		years[strconv.Itoa(date.Year())] = true
+	}
+	return AvailablePeriods{
+		Weeks:    newestFirst(weeks),
+		Months:   newestFirst(months),
+		Quarters: newestFirst(quarters),
+		Years:    newestFirst(years),
+	}
+}
+
+// newestFirst sorts period keys of one granularity, which sort by date as text
+func newestFirst(keys map[string]bool) []string {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
+	return sorted
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository struct {
@@ -19,6 +20,20 @@ func (r *Repository) Create(insight *ProgressInsight) error {
 	return r.db.Create(insight).Error
 }
 
+// CreateOnce saves a new progress insight unless the user already has one for
+// the period, in which case that one is returned instead. This keeps
+// concurrent generations from producing duplicates.
+func (r *Repository) CreateOnce(insight *ProgressInsight) (*ProgressInsight, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(insight)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return insight, nil
+	}
+	return r.GetByUserAndPeriod(insight.UserID, insight.Period)
+}
+
 // GetByID retrieves a progress insight by ID
 func (r *Repository) GetByID(id uint) (*ProgressInsight, error) {
 	var insight ProgressInsight
@@ -40,11 +55,89 @@ func (r *Repository) GetByUserAndPeriod(userID uint, period string) (*ProgressIn
 	return &insight, err
 }
 
+// GetByUserAndPeriods retrieves a user's insights for any of the periods,
+// in period order
+func (r *Repository) GetByUserAndPeriods(userID uint, periods []string) ([]ProgressInsight, error) {
+	var insights []ProgressInsight
+	err := r.db.Where("user_id = ? AND period IN ?", userID, periods).Order("period ASC").Find(&insights).Error
+	return insights, err
+}
+
 // Update updates an existing progress insight
 func (r *Repository) Update(insight *ProgressInsight) error {
 	return r.db.Save(insight).Error
 }
 
+// Replace overwrites an insight with newly generated content, keeping what
+// it replaces as an earlier version
+func (r *Repository) Replace(insight *ProgressInsight, fresh *ProgressInsight) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		previous := &InsightVersion{
+			InsightID:     insight.ID,
+			Version:       insight.Version,
+			Summary:       insight.Summary,
+			Highlights:    insight.Highlights,
+			Areas:         insight.Areas,
+			Verse:         insight.Verse,
+			Stats:         insight.Stats,
+			PromptVersion: insight.PromptVersion,
+			Mode:          insight.Mode,
+			Provisional:   insight.Provisional,
+			GeneratedAt:   insight.UpdatedAt,
+		}
+		if err := tx.Create(previous).Error; err != nil {
+			return err
+		}
+
+		insight.Summary = fresh.Summary
+		insight.Highlights = fresh.Highlights
+		insight.Areas = fresh.Areas
+		insight.Verse = fresh.Verse
+		insight.Stats = fresh.Stats
+		insight.PromptVersion = fresh.PromptVersion
+		insight.Mode = fresh.Mode
+		insight.Provisional = fresh.Provisional
+		insight.Version++
+		return tx.Save(insight).Error
+	})
+}
+
+// GetVersions retrieves the earlier versions of an insight, newest first
+func (r *Repository) GetVersions(insightID uint) ([]InsightVersion, error) {
+	var versions []InsightVersion
+	err := r.db.Where("insight_id = ?", insightID).Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+// GetProvisional retrieves every insight generated before its period ended
+func (r *Repository) GetProvisional() ([]ProgressInsight, error) {
+	var insights []ProgressInsight
+	err := r.db.Where("provisional = ?", true).Find(&insights).Error
+	return insights, err
+}
+
+// SaveRating stores a rating, replacing the user's earlier rating of the
+// same version of the insight
+func (r *Repository) SaveRating(rating *InsightRating) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "insight_id"}, {Name: "user_id"}, {Name: "version"}},
+		DoUpdates: clause.AssignmentColumns([]string{"helpful", "comment", "updated_at"}),
+	}).Create(rating).Error
+}
+
+// GetPromptRatings counts helpful and unhelpful ratings by prompt version
+func (r *Repository) GetPromptRatings() ([]PromptRatings, error) {
+	var ratings []PromptRatings
+	err := r.db.Model(&InsightRating{}).
+		Select("prompt_version, " +
+			"COUNT(*) FILTER (WHERE helpful) AS helpful, " +
+			"COUNT(*) FILTER (WHERE NOT helpful) AS not_helpful").
+		Group("prompt_version").
+		Order("prompt_version").
+		Scan(&ratings).Error
+	return ratings, err
+}
+
 // Delete soft deletes a progress insight
 func (r *Repository) Delete(id uint) error {
 	return r.db.Delete(&ProgressInsight{}, id).Error
@@ -54,7 +147,7 @@ func (r *Repository) Delete(id uint) error {
 func (r *Repository) GetMoodDataForPeriod(userID uint, startDate, endDate time.Time) ([]MoodSummary, error) {
 	var moods []MoodSummary
 	err := r.db.Table("mood_entries").
-		Select("date, emotional_state, spiritual_state, energy_level, notes").
+		Select("id, date, emotional_state, spiritual_state, energy_level, notes").
 		Where("user_id = ? AND date >= ? AND date <= ? AND deleted_at IS NULL", userID, startDate, endDate).
 		Order("date ASC").
 		Scan(&moods).Error
@@ -65,7 +158,7 @@ func (r *Repository) GetMoodDataForPeriod(userID uint, startDate, endDate time.T
 func (r *Repository) GetJournalDataForPeriod(userID uint, startDate, endDate time.Time) ([]JournalSummary, error) {
 	var journals []JournalSummary
 	err := r.db.Table("journal_entries").
-		Select("created_at as date, content").
+		Select("id, created_at as date, content").
 		Where("user_id = ? AND created_at >= ? AND created_at <= ? AND deleted_at IS NULL", userID, startDate, endDate).
 		Order("created_at ASC").
 		Scan(&journals).Error
@@ -103,3 +196,82 @@ func (r *Repository) GetPrayerStatsForPeriod(userID uint, startDate, endDate tim
 
 	return prayersOffered, prayersAnswered, nil
 }
+
+// GetReadingCountForPeriod counts reading plan days completed in a specific period
+func (r *Repository) GetReadingCountForPeriod(userID uint, startDate, endDate time.Time) (int64, error) {
+	var count int64
+	err := r.db.Table("reading_progress").
+		Where("user_id = ? AND completed_at >= ? AND completed_at <= ?", userID, startDate, endDate).
+		Count(&count).Error
+	return count, err
+}
+
+// GetDailyActivity counts the records of each source the user made on each
+// day between two dates
+func (r *Repository) GetDailyActivity(userID uint, startDate, endDate time.Time) ([]ActivityCount, error) {
+	rows, err := r.db.Raw(`
+		SELECT day, source, COUNT(*) FROM (
+			SELECT date_trunc('day', date) AS day, ? AS source FROM mood_entries
+			WHERE user_id = ? AND date >= ? AND date <= ? AND deleted_at IS NULL
+			UNION ALL SELECT date_trunc('day', created_at), ? FROM journal_entries
+			WHERE user_id = ? AND created_at >= ? AND created_at <= ? AND deleted_at IS NULL
+			UNION ALL SELECT date_trunc('day', created_at), ? FROM gratitude_entries
+			WHERE user_id = ? AND created_at >= ? AND created_at <= ? AND deleted_at IS NULL
+			UNION ALL SELECT date_trunc('day', prayed_at), ? FROM prayer_logs
+			WHERE user_id = ? AND prayed_at >= ? AND prayed_at <= ?
+			UNION ALL SELECT date_trunc('day', answered_at), ? FROM prayer_requests
+			WHERE user_id = ? AND status = 'answered' AND answered_at >= ? AND answered_at <= ? AND deleted_at IS NULL
+			UNION ALL SELECT date_trunc('day', completed_at), ? FROM reading_progress
+			WHERE user_id = ? AND completed_at >= ? AND completed_at <= ?
+		) activity
+		GROUP BY day, source
+		ORDER BY day`,
+		SourceMood, userID, startDate, endDate,
+		SourceJournal, userID, startDate, endDate,
+		SourceGratitude, userID, startDate, endDate,
+		SourcePrayer, userID, startDate, endDate,
+		SourceAnswered, userID, startDate, endDate,
+		SourceReading, userID, startDate, endDate,
+	).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activity []ActivityCount
+	for rows.Next() {
+		var count ActivityCount
+		if err := rows.Scan(&count.Day, &count.Source, &count.Count); err != nil {
+			return nil, err
+		}
+		activity = append(activity, count)
+	}
+	return activity, rows.Err()
+}
+
+// GetActivityDates retrieves the days on which the user recorded a mood,
+// journal or gratitude entry, prayed for someone or completed a reading
+func (r *Repository) GetActivityDates(userID uint) ([]time.Time, error) {
+	rows, err := r.db.Raw(`
+		SELECT date_trunc('day', date) FROM mood_entries WHERE user_id = ? AND deleted_at IS NULL
+		UNION SELECT date_trunc('day', created_at) FROM journal_entries WHERE user_id = ? AND deleted_at IS NULL
+		UNION SELECT date_trunc('day', created_at) FROM gratitude_entries WHERE user_id = ? AND deleted_at IS NULL
+		UNION SELECT date_trunc('day', prayed_at) FROM prayer_logs WHERE user_id = ?
+		UNION SELECT date_trunc('day', completed_at) FROM reading_progress WHERE user_id = ? AND completed_at IS NOT NULL`,
+		userID, userID, userID, userID, userID,
+	).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+	return dates, rows.Err()
+}
@@ -0,0 +1,262 @@
+package insights
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ways an insight can be generated
+const (
+	ModeAI    = "ai"    // Written by the LLM provider from a prompt
+	ModeRules = "rules" // Written from templated text, without an LLM provider or for users who share nothing with AI
+)
+
+// RulesVersion is recorded as the prompt version of rules-based insights, so
+// their ratings can be compared with the AI prompts'
+const RulesVersion = "rules@v1"
+
+// maxRulesHighlights and maxRulesAreas limit how much a rules-based insight
+// lists, keeping it to the points that matter most
+const (
+	maxRulesHighlights = 5
+	maxRulesAreas      = 3
+)
+
+// Verses for rules-based insights, in the `"text" - reference` form the AI
+// is asked for
+const (
+	verseAnxious  = `"Do not be anxious about anything, but in every situation, by prayer and petition, with thanksgiving, present your requests to God. And the peace of God, which transcends all understanding, will guard your hearts and your minds in Christ Jesus." - Philippians 4:6-7`
+	verseSad      = `"The Lord is close to the brokenhearted and saves those who are crushed in spirit." - Psalm 34:18`
+	verseTired    = `"But those who hope in the Lord will renew their strength. They will soar on wings like eagles; they will run and not grow weary, they will walk and not be faint." - Isaiah 40:31`
+	verseLonely   = `"So do not fear, for I am with you; do not be dismayed, for I am your God. I will strengthen you and help you; I will uphold you with my righteous right hand." - Isaiah 41:10`
+	verseDistant  = `"Come near to God and he will come near to you." - James 4:8`
+	verseGrateful = `"Give thanks to the Lord, for he is good; his love endures forever." - Psalm 107:1`
+	verseJoyful   = `"The joy of the Lord is your strength." - Nehemiah 8:10`
+	versePeaceful = `"You will keep in perfect peace those whose minds are steadfast, because they trust in you." - Isaiah 26:3`
+	verseDefault  = `"Because of the Lord's great love we are not consumed, for his compassions never fail. They are new every morning; great is your faithfulness." - Lamentations 3:22-23`
+)
+
+// stateVerses picks a verse for the state the user recorded most
+var stateVerses = map[string]string{
+	"anxious":     verseAnxious,
+	"overwhelmed": verseAnxious,
+	"sad":         verseSad,
+	"frustrated":  verseSad,
+	"lonely":      verseLonely,
+	"distant":     verseDistant,
+	"dry":         verseDistant,
+	"doubting":    verseDistant,
+	"questioning": verseDistant,
+	"struggling":  verseTired,
+	"grateful":    verseGrateful,
+	"joyful":      verseJoyful,
+	"hopeful":     verseJoyful,
+	"inspired":    verseJoyful,
+	"peaceful":    versePeaceful,
+	"content":     versePeaceful,
+	"connected":   versePeaceful,
+}
+
+// GenerateRulesInsight writes an insight from the aggregated data without
+// AI. The same data always gives the same insight. Streaks and week-by-week
+// trends are drawn from data.Stats when it is set.
+func GenerateRulesInsight(data *InsightData) *AIInsightResponse {
+	noun := data.Noun
+	if noun == "" {
+		noun = "period"
+	}
+	label := data.Label
+	if label == "" {
+		label = data.Period
+	}
+	stats := data.Stats
+	if stats == nil {
+		stats = &InsightStats{}
+	}
+
+	moods := len(data.MoodEntries)
+	journals := len(data.JournalEntries)
+	total := moods + journals + data.GratitudeCount + data.PrayersOffered + data.PrayersAnswered + data.ReadingsCompleted
+	emotion := topState(data.TopEmotions)
+	spiritual := topState(data.TopSpiritual)
+
+	if total == 0 {
+		return &AIInsightResponse{
+			Summary: fmt.Sprintf("Nothing was recorded for %s. That's alright: every day is a fresh start, "+
+				"and a single mood check-in or a line of gratitude is a good place to begin again.", label),
+			Highlights:     []string{fmt.Sprintf("You came back to reflect on %s, and that matters.", label)},
+			AreasForGrowth: []string{fmt.Sprintf("Try checking in on your mood once a day this %s.", noun)},
+			Verse:          verseDefault,
+		}
+	}
+
+	return &AIInsightResponse{
+		Summary:        rulesSummary(data, stats, label, noun, total, emotion, spiritual),
+		Highlights:     rulesHighlights(data, stats, noun),
+		AreasForGrowth: rulesAreas(data, stats, noun),
+		Verse:          rulesVerse(emotion, spiritual, data.AvgEnergyLevel, moods),
+	}
+}
+
+func rulesSummary(data *InsightData, stats *InsightStats, label, noun string, total int, emotion, spiritual string) string {
+	var sentences []string
+
+	activity := fmt.Sprintf("During %s you made %s", label, plural(total, "entry", "entries"))
+	if stats.ActiveDays > 0 {
+		activity += fmt.Sprintf(" across %s", plural(stats.ActiveDays, "day", "days"))
+	}
+	sentences = append(sentences, activity+".")
+
+	if emotion != "" {
+		feeling := fmt.Sprintf("You most often felt %s", emotion)
+		if spiritual != "" {
+			feeling += fmt.Sprintf(", and spiritually you were most often %s", spiritual)
+		}
+		if len(data.MoodEntries) > 0 {
+			feeling += fmt.Sprintf(", with %s energy averaging %.1f out of 10", energyWord(data.AvgEnergyLevel), data.AvgEnergyLevel)
+		}
+		sentences = append(sentences, feeling+".")
+	}
+
+	if trend := rulesTrend(stats); trend != "" {
+		sentences = append(sentences, trend)
+	}
+
+	if data.PrayersAnswered > 0 {
+		sentences = append(sentences, fmt.Sprintf("God answered %s this %s; take a moment to give thanks.",
+			plural(data.PrayersAnswered, "prayer", "prayers"), noun))
+	} else {
+		sentences = append(sentences, fmt.Sprintf("Every step you took this %s is part of your walk with God.", noun))
+	}
+
+	return strings.Join(sentences, " ")
+}
+
+// rulesTrend compares the last two weeks with entries, if there are any
+func rulesTrend(stats *InsightStats) string {
+	for i := len(stats.Weeks) - 1; i >= 0; i-- {
+		week := stats.Weeks[i]
+		if week.EntriesChange == nil {
+			continue
+		}
+		switch change := *week.EntriesChange; {
+		case change > 0:
+			return fmt.Sprintf("Your activity grew by %s in the week of %s.", plural(change, "entry", "entries"), week.Start)
+		case change < 0:
+			return fmt.Sprintf("Your activity dropped by %s in the week of %s.", plural(-change, "entry", "entries"), week.Start)
+		default:
+			return fmt.Sprintf("You kept a steady pace in the week of %s.", week.Start)
+		}
+	}
+	return ""
+}
+
+func rulesHighlights(data *InsightData, stats *InsightStats, noun string) []string {
+	var highlights []string
+	if stats.Streaks.Longest >= 3 {
+		highlights = append(highlights, fmt.Sprintf("You showed up %d days in a row.", stats.Streaks.Longest))
+	}
+	for _, finding := range data.MoodFindings {
+		if finding.Difference != nil && *finding.Difference > 0 {
+			highlights = append(highlights, finding.Finding())
+			break
+		}
+	}
+	if data.PrayersAnswered > 0 {
+		highlights = append(highlights, fmt.Sprintf("You saw %s answered.", plural(data.PrayersAnswered, "prayer", "prayers")))
+	}
+	if data.PrayersOffered > 0 {
+		highlights = append(highlights, fmt.Sprintf("You prayed for others %s.", plural(data.PrayersOffered, "time", "times")))
+	}
+	if data.GratitudeCount > 0 {
+		highlights = append(highlights, fmt.Sprintf("You gave thanks for %s.", plural(data.GratitudeCount, "thing", "things")))
+	}
+	if data.ReadingsCompleted > 0 {
+		highlights = append(highlights, fmt.Sprintf("You completed %s.", plural(data.ReadingsCompleted, "Bible reading", "Bible readings")))
+	}
+	if journals := len(data.JournalEntries); journals > 0 {
+		highlights = append(highlights, fmt.Sprintf("You wrote %s.", plural(journals, "journal entry", "journal entries")))
+	}
+	if len(data.MoodEntries) > 0 && data.AvgEnergyLevel >= 7 {
+		highlights = append(highlights, "Your energy stayed high.")
+	}
+	if len(highlights) == 0 {
+		highlights = append(highlights, fmt.Sprintf("You took time to check in with yourself this %s.", noun))
+	}
+	if len(highlights) > maxRulesHighlights {
+		highlights = highlights[:maxRulesHighlights]
+	}
+	return highlights
+}
+
+func rulesAreas(data *InsightData, stats *InsightStats, noun string) []string {
+	var areas []string
+	if data.GratitudeCount == 0 {
+		areas = append(areas, "Try writing down one thing you're grateful for each day.")
+	}
+	if data.ReadingsCompleted == 0 {
+		areas = append(areas, "A short daily Bible reading could help you stay rooted in God's word.")
+	}
+	if data.PrayersOffered == 0 {
+		areas = append(areas, "Consider praying for someone else's request on the Prayer Wall.")
+	}
+	if stats.ActiveDays > 0 && stats.ActiveDays < 3 {
+		areas = append(areas, fmt.Sprintf("Checking in on a few more days this %s would help you see how you're really doing.", noun))
+	}
+	if len(data.MoodEntries) > 0 && data.AvgEnergyLevel < 4 {
+		areas = append(areas, "Your energy has been low; make room for rest, and share the load with someone you trust.")
+	}
+	if len(data.JournalEntries) == 0 {
+		areas = append(areas, "Journaling even a few lines can help you notice what God is doing.")
+	}
+	if len(areas) == 0 {
+		areas = append(areas, fmt.Sprintf("Keep going with the rhythm you've built this %s.", noun))
+	}
+	if len(areas) > maxRulesAreas {
+		areas = areas[:maxRulesAreas]
+	}
+	return areas
+}
+
+// rulesVerse picks a verse for the user's most frequent state, or for low
+// energy when the state has no verse of its own
+func rulesVerse(emotion, spiritual string, avgEnergy float64, moods int) string {
+	if verse, ok := stateVerses[emotion]; ok {
+		return verse
+	}
+	if moods > 0 && avgEnergy < 4 {
+		return verseTired
+	}
+	if verse, ok := stateVerses[spiritual]; ok {
+		return verse
+	}
+	return verseDefault
+}
+
+// topState is the most frequent state, ties going to the first by name
+func topState(counts map[string]int) string {
+	for _, state := range top(counts, len(counts)) {
+		if state.State != "" {
+			return state.State
+		}
+	}
+	return ""
+}
+
+func energyWord(level float64) string {
+	switch {
+	case level >= 7:
+		return "high"
+	case level >= 4:
+		return "moderate"
+	default:
+		return "low"
+	}
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
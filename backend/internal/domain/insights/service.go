@@ -5,67 +5,482 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
-	"github.com/sashabaranov/go-openai"
+	"armourup/internal/domain/mood"
+	"armourup/internal/domain/privacy"
+	"armourup/internal/domain/usage"
+	"armourup/internal/jobs"
+	"armourup/internal/llm"
+	"armourup/internal/prompts"
+
 	"gorm.io/gorm"
 )
 
 type Service struct {
-	repo     *Repository
-	aiClient *openai.Client
+	repo        *Repository
+	provider    llm.Provider
+	prompts     *prompts.Registry
+	privacy     *privacy.Service
+	moodService *mood.Service
+	queue       *jobs.Queue
+	model       string
 }
 
-func NewService(repo *Repository, aiClient *openai.Client) *Service {
+// JobKind identifies insight generation jobs in the job queue
+const JobKind = "insight"
+
+// NewService creates the insights service. A non-empty model overrides the
+// one set for the insights prompt, e.g. to use a local model. What the
+// prompt may include is decided by each user's privacy settings. Insights
+// requested through the API are generated by workers taking jobs from queue.
+// The provider may be nil, in which case insights are written from rules.
+// Monthly insights draw on how the user's practices relate to their mood,
+// which the mood service measures; it may be nil to leave that out.
+func NewService(repo *Repository, provider llm.Provider, registry *prompts.Registry, privacyService *privacy.Service, moodService *mood.Service, queue *jobs.Queue, model string) *Service {
 	return &Service{
-		repo:     repo,
-		aiClient: aiClient,
+		repo:        repo,
+		provider:    provider,
+		prompts:     registry,
+		privacy:     privacyService,
+		moodService: moodService,
+		queue:       queue,
+		model:       model,
+	}
+}
+
+// insightJobPayload is what an insight job is queued with. Jobs with an
+// insight ID regenerate that insight.
+type insightJobPayload struct {
+	Period    string `json:"period"`
+	InsightID uint   `json:"insight_id,omitempty"`
+}
+
+// insightJobResult is what a succeeded insight job stores
+type insightJobResult struct {
+	InsightID uint `json:"insight_id"`
+}
+
+// RequestInsight returns the user's insight for a period if it exists, or
+// queues a job to generate it. Requests for a period already being generated
+// share the same job.
+func (s *Service) RequestInsight(userID uint, key string) (*ProgressInsight, *jobs.Job, error) {
+	period, err := ParsePeriod(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing, err := s.repo.GetByUserAndPeriod(userID, period.Key)
+	if err == nil && existing.ID > 0 {
+		return existing, nil, nil
+	}
+
+	// An AI year in review needs monthly insights; fail now rather than in the worker
+	if period.Granularity == GranularityYear && s.aiDisclosure(userID) != nil {
+		months, err := s.repo.GetByUserAndPeriods(userID, period.Months())
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(months) == 0 {
+			return nil, nil, errNoMonthlyInsights
+		}
+	}
+
+	job, err := s.enqueue(userID, insightJobPayload{Period: period.Key})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, job, nil
+}
+
+// enqueue queues an insight job. Generating and regenerating a period share
+// a dedupe key, so only one job works on each of a user's periods at a time.
+func (s *Service) enqueue(userID uint, payload insightJobPayload) (*jobs.Job, error) {
+	dedupeKey := fmt.Sprintf("%s:%d:%s", JobKind, userID, payload.Period)
+	job, _, err := s.queue.Enqueue(JobKind, userID, dedupeKey, payload)
+	return job, err
+}
+
+// RegenerateInsight queues a job to regenerate one of the user's insights.
+// The version it replaces is kept.
+func (s *Service) RegenerateInsight(userID, insightID uint) (*jobs.Job, error) {
+	insight, err := s.repo.GetByID(insightID)
+	if err != nil || insight.UserID != userID {
+		return nil, errors.New("insight not found")
+	}
+	return s.enqueue(userID, insightJobPayload{Period: insight.Period, InsightID: insight.ID})
+}
+
+// refreshGrace is how long after a period ends its provisional insights are
+// refreshed, leaving time for entries about its last day
+const refreshGrace = 24 * time.Hour
+
+// DueForRefresh reports whether a provisional insight's period ended long
+// enough ago for it to be refreshed
+func DueForRefresh(insight *ProgressInsight, now time.Time) bool {
+	if !insight.Provisional {
+		return false
 	}
+	period, err := ParsePeriod(insight.Period)
+	if err != nil {
+		return false
+	}
+	return !now.Before(period.End.Add(refreshGrace))
+}
+
+// RefreshProvisional queues regeneration of provisional insights whose
+// period has ended, returning how many were queued. It is run periodically
+// by the job worker.
+func (s *Service) RefreshProvisional(now time.Time) (int, error) {
+	provisional, err := s.repo.GetProvisional()
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, insight := range provisional {
+		if !DueForRefresh(&insight, now) {
+			continue
+		}
+		// Leave the insight as it is if the user has since stopped sharing
+		if s.aiEnabled() && !s.privacy.Begin(insight.UserID).AllowsAggregates() {
+			continue
+		}
+		if _, err := s.enqueue(insight.UserID, insightJobPayload{Period: insight.Period, InsightID: insight.ID}); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// HandleJob generates the insight for a queued job. Failures that retrying
+// can't fix are marked permanent so the job is dead-lettered straight away.
+func (s *Service) HandleJob(ctx context.Context, job *jobs.Job) (string, error) {
+	var payload insightJobPayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return "", jobs.Permanent(err)
+	}
+
+	var insight *ProgressInsight
+	var err error
+	if payload.InsightID != 0 {
+		insight, err = s.regenerateInsight(ctx, job.UserID, payload.InsightID)
+	} else {
+		insight, err = s.generateInsight(ctx, job.UserID, payload.Period)
+	}
+	if err != nil {
+		var quotaErr *usage.QuotaError
+		if errors.As(err, &quotaErr) || errors.Is(err, ErrInvalidPeriod) || errors.Is(err, errNoMonthlyInsights) ||
+			err.Error() == "insight not found" {
+			return "", jobs.Permanent(err)
+		}
+		return "", err
+	}
+
+	result, err := json.Marshal(insightJobResult{InsightID: insight.ID})
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
 }
 
-// GenerateInsight creates a new monthly progress insight using AI
-func (s *Service) GenerateInsight(userID uint, period string) (*ProgressInsight, error) {
+// GetJob reports on an insight job belonging to the user, including the
+// insight once it has been generated
+func (s *Service) GetJob(userID, jobID uint) (*InsightJob, error) {
+	job, err := s.queue.Get(jobID)
+	if err != nil || job.Kind != JobKind || job.UserID != userID {
+		return nil, errors.New("job not found")
+	}
+
+	var payload insightJobPayload
+	json.Unmarshal([]byte(job.Payload), &payload)
+
+	status := &InsightJob{
+		JobID:     job.ID,
+		Status:    job.Status,
+		Period:    payload.Period,
+		Attempts:  job.Attempts,
+		Error:     job.LastError,
+		StatusURL: JobStatusURL(job.ID),
+	}
+	if job.Status == jobs.StatusSucceeded {
+		var result insightJobResult
+		if err := json.Unmarshal([]byte(job.Result), &result); err == nil {
+			insight, err := s.repo.GetByID(result.InsightID)
+			if err == nil {
+				status.Insight = insight
+			}
+		}
+	}
+	return status, nil
+}
+
+// JobStatusURL is where the progress of an insight job can be checked
+func JobStatusURL(jobID uint) string {
+	return fmt.Sprintf("/api/insights/jobs/%d", jobID)
+}
+
+func (s *Service) generateInsight(ctx context.Context, userID uint, key string) (*ProgressInsight, error) {
+	period, err := ParsePeriod(key)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if insight already exists for this period
-	existing, err := s.repo.GetByUserAndPeriod(userID, period)
+	existing, err := s.repo.GetByUserAndPeriod(userID, period.Key)
 	if err == nil && existing.ID > 0 {
 		return existing, nil // Return cached insight
 	}
 
-	// Parse period (format: "2024-01")
-	startDate, endDate, err := s.parsePeriod(period)
+	insight, err := s.compose(ctx, userID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the insight, unless a concurrent generation got there first
+	return s.repo.CreateOnce(insight)
+}
+
+// regenerateInsight replaces an insight with a newly generated version
+func (s *Service) regenerateInsight(ctx context.Context, userID, insightID uint) (*ProgressInsight, error) {
+	insight, err := s.repo.GetByID(insightID)
+	if err != nil || insight.UserID != userID {
+		return nil, errors.New("insight not found")
+	}
+	period, err := ParsePeriod(insight.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh, err := s.compose(ctx, userID, period)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.repo.Replace(insight, fresh); err != nil {
+		return nil, err
+	}
+	return insight, nil
+}
+
+// aiEnabled reports whether insights are written by AI. Without an LLM
+// provider they are written from rules, and nothing is shared with AI.
+func (s *Service) aiEnabled() bool {
+	return s.provider != nil
+}
+
+// aiDisclosure starts tracking an AI request for the user's insight. It
+// returns nil when the insight is to be written from rules instead: there is
+// no LLM provider, or the user doesn't share even aggregates with AI.
+func (s *Service) aiDisclosure(userID uint) *privacy.Disclosure {
+	if !s.aiEnabled() {
+		return nil
+	}
+	disclosure := s.privacy.Begin(userID)
+	if !disclosure.AllowsAggregates() {
+		return nil
+	}
+	return disclosure
+}
+
+// compose generates, but doesn't save, an insight for a period along with
+// its statistics. Insights for periods that haven't ended yet are provisional.
+// Users who share nothing with AI get insights written from rules.
+func (s *Service) compose(ctx context.Context, userID uint, period *Period) (*ProgressInsight, error) {
+	disclosure := s.aiDisclosure(userID)
+
+	now := time.Now()
+	stats, err := s.computeStats(userID, period, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var insight *ProgressInsight
+	switch {
+	case disclosure == nil:
+		insight, err = s.composeRules(userID, period, stats)
+	case period.Granularity == GranularityYear:
+		insight, err = s.composeYearReview(ctx, userID, period, disclosure)
+	default:
+		insight, err = s.composePeriod(ctx, userID, period, stats, disclosure)
+	}
+	if err != nil {
+		return nil, err
+	}
+	insight.Stats = stats
+	insight.Provisional = now.Before(period.End)
+	return insight, nil
+}
+
+// GetStats computes the statistics for one of the user's periods. No AI is
+// involved, so they are available whatever the user shares with AI.
+func (s *Service) GetStats(userID uint, key string) (*InsightStats, error) {
+	period, err := ParsePeriod(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.computeStats(userID, period, time.Now())
+}
+
+func (s *Service) computeStats(userID uint, period *Period, now time.Time) (*InsightStats, error) {
+	start, end := StatsWindow(period)
+	moods, err := s.repo.GetMoodDataForPeriod(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.repo.GetDailyActivity(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return ComputeStats(period, moods, activity, now), nil
+}
 
+// composePeriod generates an insight from the period's entries
+func (s *Service) composePeriod(ctx context.Context, userID uint, period *Period, stats *InsightStats, disclosure *privacy.Disclosure) (*ProgressInsight, error) {
 	// Gather data from various sources
-	data, err := s.gatherInsightData(userID, period, startDate, endDate)
+	data, err := s.gatherInsightData(userID, period)
 	if err != nil {
 		return nil, err
 	}
+	data.Stats = stats
 
 	// Generate AI insight
-	aiResponse, err := s.generateAIInsight(data)
+	aiResponse, promptVersion, err := s.generateAIInsight(ctx, userID, "insights", BuildPromptData(data, disclosure), disclosure)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create and save insight
-	insight := &ProgressInsight{
-		UserID:     userID,
-		Period:     period,
-		Summary:    aiResponse.Summary,
-		Highlights: aiResponse.Highlights,
-		Areas:      aiResponse.AreasForGrowth,
-		Verse:      aiResponse.Verse,
-		MoodStats:  fmt.Sprintf("Avg Energy: %.1f/10", data.AvgEnergyLevel),
+	return &ProgressInsight{
+		UserID:        userID,
+		Period:        period.Key,
+		Summary:       aiResponse.Summary,
+		Highlights:    aiResponse.Highlights,
+		Areas:         aiResponse.AreasForGrowth,
+		Verse:         aiResponse.Verse,
+		PromptVersion: promptVersion,
+		Mode:          ModeAI,
+	}, nil
+}
+
+// composeRules writes an insight from the period's entries without AI. A
+// year is written from its entries too, as there may be no monthly insights
+// worth reviewing.
+func (s *Service) composeRules(userID uint, period *Period, stats *InsightStats) (*ProgressInsight, error) {
+	data, err := s.gatherInsightData(userID, period)
+	if err != nil {
+		return nil, err
+	}
+	data.Stats = stats
+
+	response := GenerateRulesInsight(data)
+	return &ProgressInsight{
+		UserID:        userID,
+		Period:        period.Key,
+		Summary:       response.Summary,
+		Highlights:    response.Highlights,
+		Areas:         response.AreasForGrowth,
+		Verse:         response.Verse,
+		PromptVersion: RulesVersion,
+		Mode:          ModeRules,
+	}, nil
+}
+
+// errNoMonthlyInsights is returned for a year in review before any of the
+// year's monthly insights have been generated
+var errNoMonthlyInsights = errors.New("there are no monthly insights to review for this year yet")
+
+// composeYearReview generates a year in review from the year's monthly
+// insights, rather than going back over a year of entries
+func (s *Service) composeYearReview(ctx context.Context, userID uint, period *Period, disclosure *privacy.Disclosure) (*ProgressInsight, error) {
+	months, err := s.repo.GetByUserAndPeriods(userID, period.Months())
+	if err != nil {
+		return nil, err
+	}
+	if len(months) == 0 {
+		return nil, errNoMonthlyInsights
 	}
 
-	if err := s.repo.Create(insight); err != nil {
+	aiResponse, promptVersion, err := s.generateAIInsight(ctx, userID, "insights_year", BuildYearReviewData(period, months, disclosure), disclosure)
+	if err != nil {
 		return nil, err
 	}
 
-	return insight, nil
+	return &ProgressInsight{
+		UserID:        userID,
+		Period:        period.Key,
+		Summary:       aiResponse.Summary,
+		Highlights:    aiResponse.Highlights,
+		Areas:         aiResponse.AreasForGrowth,
+		Verse:         aiResponse.Verse,
+		PromptVersion: promptVersion,
+		Mode:          ModeAI,
+	}, nil
+}
+
+// errSamePeriod is returned when a period is compared with itself
+var errSamePeriod = errors.New("from and to must be different periods")
+
+// NarrativeSchema is the structure an AI comparison narrative must have
+var NarrativeSchema = llm.Schema{
+	Name: "comparison",
+	Fields: []llm.Field{
+		{Name: "narrative", Type: llm.TypeString, Required: true, MaxLength: 3000},
+	},
+}
+
+// Compare sets two of the user's periods side by side. With narrative, what
+// changed is also described in words, by AI if the user shares aggregates
+// with it, or otherwise from rules.
+func (s *Service) Compare(ctx context.Context, userID uint, fromKey, toKey string, narrative bool) (*PeriodComparison, error) {
+	fromPeriod, err := ParsePeriod(fromKey)
+	if err != nil {
+		return nil, err
+	}
+	toPeriod, err := ParsePeriod(toKey)
+	if err != nil {
+		return nil, err
+	}
+	if fromPeriod.Key == toPeriod.Key {
+		return nil, errSamePeriod
+	}
+
+	fromData, err := s.gatherInsightData(userID, fromPeriod)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := s.gatherInsightData(userID, toPeriod)
+	if err != nil {
+		return nil, err
+	}
+	comparison := ComparePeriods(fromPeriod, fromData, toPeriod, toData)
+	if !narrative {
+		return comparison, nil
+	}
+
+	disclosure := s.aiDisclosure(userID)
+	if disclosure == nil {
+		comparison.Narrative = RulesNarrative(comparison)
+		comparison.NarrativeMode = ModeRules
+		return comparison, nil
+	}
+	for _, data := range []*InsightData{fromData, toData} {
+		for _, mood := range data.MoodEntries {
+			disclosure.Include("mood_entry", mood.ID)
+		}
+		for _, entry := range data.JournalEntries {
+			disclosure.Include("journal_entry", entry.ID)
+		}
+	}
+
+	var response struct {
+		Narrative string `json:"narrative"`
+	}
+	if _, err := s.chatJSON(ctx, userID, "insights_compare", comparison, NarrativeSchema, &response, disclosure); err != nil {
+		return nil, fmt.Errorf("failed to generate comparison narrative: %w", err)
+	}
+	comparison.Narrative = response.Narrative
+	comparison.NarrativeMode = ModeAI
+	return comparison, nil
 }
 
 // GetInsight retrieves a specific progress insight
@@ -73,41 +488,92 @@ func (s *Service) GetInsight(id uint) (*ProgressInsight, error) {
 	return s.repo.GetByID(id)
 }
 
-// GetUserInsights retrieves all progress insights for a user
-func (s *Service) GetUserInsights(userID uint) ([]ProgressInsight, error) {
-	return s.repo.GetByUserID(userID)
+// GetUserInsight retrieves one of the user's insights
+func (s *Service) GetUserInsight(userID, insightID uint) (*ProgressInsight, error) {
+	insight, err := s.repo.GetByID(insightID)
+	if err != nil || insight.UserID != userID {
+		return nil, errors.New("insight not found")
+	}
+	return insight, nil
+}
+
+// GetVersions retrieves the earlier versions of one of the user's insights
+func (s *Service) GetVersions(userID, insightID uint) ([]InsightVersion, error) {
+	insight, err := s.repo.GetByID(insightID)
+	if err != nil || insight.UserID != userID {
+		return nil, errors.New("insight not found")
+	}
+	return s.repo.GetVersions(insight.ID)
 }
 
-// GetInsightForPeriod retrieves or generates an insight for a specific period
-func (s *Service) GetInsightForPeriod(userID uint, period string) (*ProgressInsight, error) {
-	// Try to get existing insight
-	insight, err := s.repo.GetByUserAndPeriod(userID, period)
-	if err == nil && insight.ID > 0 {
-		return insight, nil
+// RateInsight records whether the user found the current version of one of
+// their insights helpful, against the prompt version that generated it
+func (s *Service) RateInsight(userID, insightID uint, req RateInsightRequest) (*InsightRating, error) {
+	insight, err := s.repo.GetByID(insightID)
+	if err != nil || insight.UserID != userID {
+		return nil, errors.New("insight not found")
 	}
 
-	// Generate new insight if it doesn't exist
-	return s.GenerateInsight(userID, period)
+	rating := &InsightRating{
+		InsightID:     insight.ID,
+		UserID:        userID,
+		Version:       insight.Version,
+		PromptVersion: insight.PromptVersion,
+		Helpful:       *req.Helpful,
+		Comment:       req.Comment,
+	}
+	if err := s.repo.SaveRating(rating); err != nil {
+		return nil, err
+	}
+	return rating, nil
 }
 
-// parsePeriod converts a period string to start and end dates
-func (s *Service) parsePeriod(period string) (time.Time, time.Time, error) {
-	// Parse period format "2024-01"
-	startDate, err := time.Parse("2006-01", period)
+// GetPromptRatings summarises insight ratings by prompt version
+func (s *Service) GetPromptRatings() ([]PromptRatings, error) {
+	ratings, err := s.repo.GetPromptRatings()
 	if err != nil {
-		return time.Time{}, time.Time{}, errors.New("invalid period format, use YYYY-MM")
+		return nil, err
+	}
+	for i := range ratings {
+		if total := ratings[i].Helpful + ratings[i].NotHelpful; total > 0 {
+			ratings[i].HelpfulRate = float64(ratings[i].Helpful) / float64(total)
+		}
 	}
+	return ratings, nil
+}
 
-	// Calculate end of month
-	endDate := startDate.AddDate(0, 1, 0).Add(-time.Second)
+// GetUserInsights retrieves all progress insights for a user
+func (s *Service) GetUserInsights(userID uint) ([]ProgressInsight, error) {
+	return s.repo.GetByUserID(userID)
+}
+
+// GetAvailablePeriods lists the periods at each granularity that have data,
+// along with the periods that already have insights
+func (s *Service) GetAvailablePeriods(userID uint) (*AvailablePeriods, []string, error) {
+	dates, err := s.repo.GetActivityDates(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	available := PeriodsForDates(dates)
 
-	return startDate, endDate, nil
+	insights, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	generated := make([]string, 0, len(insights))
+	for _, insight := range insights {
+		generated = append(generated, insight.Period)
+	}
+	return &available, generated, nil
 }
 
 // gatherInsightData collects all relevant data for generating insights
-func (s *Service) gatherInsightData(userID uint, period string, startDate, endDate time.Time) (*InsightData, error) {
+func (s *Service) gatherInsightData(userID uint, period *Period) (*InsightData, error) {
+	startDate, endDate := period.Start, period.End
 	data := &InsightData{
-		Period:       period,
+		Period:       period.Key,
+		Label:        period.Label(),
+		Noun:         period.Noun(),
 		TopEmotions:  make(map[string]int),
 		TopSpiritual: make(map[string]int),
 	}
@@ -152,125 +618,166 @@ func (s *Service) gatherInsightData(userID uint, period string, startDate, endDa
 	data.PrayersOffered = int(prayersOffered)
 	data.PrayersAnswered = int(prayersAnswered)
 
+	// Get completed Bible readings
+	readingsCompleted, err := s.repo.GetReadingCountForPeriod(userID, startDate, endDate)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	data.ReadingsCompleted = int(readingsCompleted)
+
+	// Get the month's strongest links between practices and mood
+	if period.Granularity == GranularityMonth && s.moodService != nil {
+		correlations, err := s.moodService.GetCorrelationsInRange(userID, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		data.MoodFindings = mood.StrongestCorrelations(correlations.Correlations, mood.MaxCorrelationFindings)
+	}
+
 	return data, nil
 }
 
+// InsightSchema is the structure an AI insight must have
+var InsightSchema = llm.Schema{
+	Name: "insight",
+	Fields: []llm.Field{
+		{Name: "summary", Type: llm.TypeString, Required: true, MaxLength: 4000},
+		{Name: "highlights", Type: llm.TypeStringList, Required: true, MaxItems: 5, MaxLength: 500},
+		{Name: "areas_for_growth", Type: llm.TypeStringList, Required: true, MaxItems: 5, MaxLength: 500},
+		{Name: "verse", Type: llm.TypeString, Required: true, MaxLength: 500},
+	},
+}
+
 // AIInsightResponse represents the AI-generated insight structure
 type AIInsightResponse struct {
-	Summary        string `json:"summary"`
-	Highlights     string `json:"highlights"`
-	AreasForGrowth string `json:"areas_for_growth"`
-	Verse          string `json:"verse"`
+	Summary        string   `json:"summary"`
+	Highlights     []string `json:"highlights"`
+	AreasForGrowth []string `json:"areas_for_growth"`
+	Verse          string   `json:"verse"`
 }
 
-// generateAIInsight uses OpenAI to generate a meaningful insight
-func (s *Service) generateAIInsight(data *InsightData) (*AIInsightResponse, error) {
-	prompt := s.buildInsightPrompt(data)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	resp, err := s.aiClient.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT4TurboPreview,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: "You are a compassionate Christian spiritual advisor who provides insightful, encouraging, and biblically-grounded feedback on spiritual growth. You MUST respond ONLY with valid JSON, no other text.",
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			Temperature: 0.7,
-		},
-	)
-
+// generateAIInsight uses the LLM provider to generate a meaningful insight
+// from one of the insight prompts, returning it with the ID of the prompt
+// version used. The data included is recorded in the privacy audit log.
+func (s *Service) generateAIInsight(ctx context.Context, userID uint, prompt string, promptData interface{}, disclosure *privacy.Disclosure) (*AIInsightResponse, string, error) {
+	var aiResponse AIInsightResponse
+	versionID, err := s.chatJSON(ctx, userID, prompt, promptData, InsightSchema, &aiResponse, disclosure)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate AI insight: %v", err)
+		return nil, "", fmt.Errorf("failed to generate AI insight: %w", err)
 	}
+	return &aiResponse, versionID, nil
+}
 
-	// Get the response content
-	content := resp.Choices[0].Message.Content
+// chatJSON renders the user's version of a prompt, sends it to the LLM
+// provider and decodes the reply, which must match schema, into out. It
+// returns the ID of the prompt version used.
+func (s *Service) chatJSON(ctx context.Context, userID uint, prompt string, promptData interface{}, schema llm.Schema, out interface{}, disclosure *privacy.Disclosure) (string, error) {
+	version, err := s.prompts.Select(prompt, userID)
+	if err != nil {
+		return "", err
+	}
+	req, err := version.Request(promptData)
+	if err != nil {
+		return "", err
+	}
+	if s.model != "" {
+		req.Model = s.model
+	}
 
-	// Clean the response (remove markdown code blocks if present)
-	content = strings.TrimSpace(content)
-	content = strings.TrimPrefix(content, "```json")
-	content = strings.TrimPrefix(content, "```")
-	content = strings.TrimSuffix(content, "```")
-	content = strings.TrimSpace(content)
+	ctx, cancel := context.WithTimeout(llm.WithCaller(ctx, userID, usage.FeatureInsights), 30*time.Second)
+	defer cancel()
 
-	var aiResponse AIInsightResponse
-	if err := json.Unmarshal([]byte(content), &aiResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse AI response: %v (content: %s)", err, content)
+	_, err = llm.ChatJSON(ctx, s.provider, req, schema, out)
+	s.privacy.Audit(userID, usage.FeatureInsights, disclosure)
+	if err != nil {
+		return "", err
 	}
-
-	return &aiResponse, nil
+	return version.ID(), nil
 }
 
-// buildInsightPrompt creates a comprehensive prompt for AI insight generation
-func (s *Service) buildInsightPrompt(data *InsightData) string {
-	prompt := fmt.Sprintf(`Generate a monthly spiritual growth summary for %s based on the following data:
-
-**Mood & Emotional Data:**
-- Total mood entries: %d
-- Average energy level: %.1f/10
-- Most common emotional states: %v
-- Most common spiritual states: %v
-
-**Spiritual Activities:**
-- Gratitude entries: %d
-- Journal entries: %d
-- Prayers offered for others: %d
-- Prayers answered: %d
+// InsightPromptData is what the insights prompt is rendered with: the
+// aggregated data plus a few excerpts chosen from it
+type InsightPromptData struct {
+	*InsightData
+	JournalExcerpts []string
+	MoodNotes       []string
+}
 
-`, data.Period, len(data.MoodEntries), data.AvgEnergyLevel, data.TopEmotions, data.TopSpiritual,
-		data.GratitudeCount, len(data.JournalEntries), data.PrayersOffered, data.PrayersAnswered)
+// BuildPromptData prepares the aggregated data for the prompt, noting every
+// record it draws on. If the user shares full text, the first three journal
+// entries and mood notes are added too, truncated and redacted, to give the
+// prompt a flavour of the period.
+func BuildPromptData(data *InsightData, disclosure *privacy.Disclosure) InsightPromptData {
+	promptData := InsightPromptData{InsightData: data}
+	for _, mood := range data.MoodEntries {
+		disclosure.Include("mood_entry", mood.ID)
+	}
+	for _, entry := range data.JournalEntries {
+		disclosure.Include("journal_entry", entry.ID)
+	}
+	if !disclosure.AllowsText() {
+		return promptData
+	}
 
-	// Add sample journal excerpts if available
-	if len(data.JournalEntries) > 0 {
-		prompt += "\n**Recent Journal Themes:**\n"
-		for i, entry := range data.JournalEntries {
-			if i >= 3 { // Only include first 3 entries
-				break
-			}
-			// Truncate long entries
-			content := entry.Content
-			if len(content) > 200 {
-				content = content[:200] + "..."
-			}
-			prompt += fmt.Sprintf("- %s\n", content)
+	for i, entry := range data.JournalEntries {
+		if i >= 3 {
+			break
+		}
+		content := entry.Content
+		if len(content) > 200 {
+			content = content[:200] + "..."
 		}
+		promptData.JournalExcerpts = append(promptData.JournalExcerpts, disclosure.Text(content))
 	}
-
-	// Add mood notes if available
-	if len(data.MoodEntries) > 0 {
-		prompt += "\n**Notable Mood Notes:**\n"
-		count := 0
-		for _, mood := range data.MoodEntries {
-			if mood.Notes != "" && count < 3 {
-				notes := mood.Notes
-				if len(notes) > 150 {
-					notes = notes[:150] + "..."
-				}
-				prompt += fmt.Sprintf("- %s\n", notes)
-				count++
-			}
+	for _, mood := range data.MoodEntries {
+		if mood.Notes == "" || len(promptData.MoodNotes) >= 3 {
+			continue
+		}
+		notes := mood.Notes
+		if len(notes) > 150 {
+			notes = notes[:150] + "..."
 		}
+		promptData.MoodNotes = append(promptData.MoodNotes, disclosure.Text(notes))
 	}
+	return promptData
+}
 
-	prompt += `
-Please provide a comprehensive monthly spiritual growth summary in JSON format with the following structure:
-{
-	"summary": "A 2-3 paragraph overview of their spiritual journey this month, highlighting patterns, growth areas, and God's work in their life",
-	"highlights": "3-5 specific positive highlights or breakthrough moments from the month",
-	"areas_for_growth": "2-3 gentle, encouraging suggestions for continued spiritual growth",
-	"verse": "A relevant Bible verse with reference that speaks to their journey this month"
+// YearReviewData is what the year in review prompt is rendered with
+type YearReviewData struct {
+	Year   string
+	Months []MonthReview
 }
 
-Be encouraging, specific, and Christ-centered. Celebrate their consistency and God's faithfulness.`
+// MonthReview is one monthly insight as the year in review sees it
+type MonthReview struct {
+	Label      string
+	Summary    string
+	Highlights []string
+	Stats      string // The month's statistics in a line, if it has them
+}
 
-	return prompt
+// BuildYearReviewData prepares a year's monthly insights for the year in
+// review prompt. The insights were written from data the user shared, so
+// they are sent at the aggregates level, redacted like any other text.
+func BuildYearReviewData(period *Period, months []ProgressInsight, disclosure *privacy.Disclosure) YearReviewData {
+	data := YearReviewData{Year: period.Label()}
+	for _, month := range months {
+		disclosure.Include("progress_insight", month.ID)
+		label := month.Period
+		if monthPeriod, err := ParsePeriod(month.Period); err == nil {
+			label = monthPeriod.Label()
+		}
+		review := MonthReview{
+			Label:   label,
+			Summary: disclosure.Text(month.Summary),
+		}
+		for _, highlight := range month.Highlights {
+			review.Highlights = append(review.Highlights, disclosure.Text(highlight))
+		}
+		if month.Stats != nil {
+			review.Stats = month.Stats.Summary()
+		}
+		data.Months = append(data.Months, review)
+	}
+	return data
 }
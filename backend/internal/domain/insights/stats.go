@@ -0,0 +1,293 @@
+package insights
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Activity sources counted in the statistics
+const (
+	SourceMood      = "mood"
+	SourceJournal   = "journal"
+	SourceGratitude = "gratitude"
+	SourcePrayer    = "prayer"
+	SourceAnswered  = "answered"
+	SourceReading   = "reading"
+)
+
+// topStates is how many emotional and spiritual states are listed
+const topStates = 5
+
+// ActivityCount is how many records of one source the user made on a day
+type ActivityCount struct {
+	Day    time.Time
+	Source string
+	Count  int
+}
+
+// InsightStats are the statistics for a period, computed from the user's
+// records without AI so they are available even when AI is not
+type InsightStats struct {
+	Period       string       `json:"period"`
+	Start        time.Time    `json:"start"`
+	End          time.Time    `json:"end"`
+	Entries      EntryCounts  `json:"entries"`
+	ActiveDays   int          `json:"active_days"`
+	Streaks      Streaks      `json:"streaks"`
+	Energy       EnergyStats  `json:"energy"`
+	TopEmotional []StateCount `json:"top_emotional"`
+	TopSpiritual []StateCount `json:"top_spiritual"`
+	Weeks        []WeekStats  `json:"weeks"`
+}
+
+// EntryCounts counts the user's records in each area of the app
+type EntryCounts struct {
+	Moods           int `json:"moods"`
+	Journals        int `json:"journals"`
+	Gratitude       int `json:"gratitude"`
+	PrayersOffered  int `json:"prayers_offered"`
+	PrayersAnswered int `json:"prayers_answered"`
+	Readings        int `json:"readings"`
+}
+
+// Total is the number of records across every area
+func (c EntryCounts) Total() int {
+	return c.Moods + c.Journals + c.Gratitude + c.PrayersOffered + c.PrayersAnswered + c.Readings
+}
+
+func (c *EntryCounts) add(source string, count int) {
+	switch source {
+	case SourceMood:
+		c.Moods += count
+	case SourceJournal:
+		c.Journals += count
+	case SourceGratitude:
+		c.Gratitude += count
+	case SourcePrayer:
+		c.PrayersOffered += count
+	case SourceAnswered:
+		c.PrayersAnswered += count
+	case SourceReading:
+		c.Readings += count
+	}
+}
+
+// Streaks are runs of consecutive days with any activity. The current streak
+// is the one running up to the end of the period, or up to today while the
+// period is ongoing; a streak isn't broken until a whole day is missed.
+type Streaks struct {
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
+
+// EnergyStats summarise the energy levels of mood entries
+type EnergyStats struct {
+	Average      float64       `json:"average"`
+	Distribution []EnergyCount `json:"distribution"` // Every level from 1 to 10
+}
+
+// EnergyCount is how many mood entries had an energy level
+type EnergyCount struct {
+	Level int `json:"level"`
+	Count int `json:"count"`
+}
+
+// StateCount is how many mood entries recorded a state
+type StateCount struct {
+	State string `json:"state"`
+	Count int    `json:"count"`
+}
+
+// WeekStats are the totals for one ISO week overlapping the period. Weeks are
+// whole, so the first and last can include days either side of the period.
+// Changes are from the week before and are left out when there is nothing
+// to compare with.
+type WeekStats struct {
+	Week          string   `json:"week"`
+	Start         string   `json:"start"`
+	Entries       int      `json:"entries"`
+	AvgEnergy     float64  `json:"avg_energy"`
+	EntriesChange *int     `json:"entries_change,omitempty"`
+	EnergyChange  *float64 `json:"energy_change,omitempty"`
+}
+
+// StatsWindow is the span of records a period's statistics are computed
+// from: the whole weeks overlapping the period, and the week before it for
+// week-over-week changes
+func StatsWindow(period *Period) (time.Time, time.Time) {
+	return weekStart(period.Start).AddDate(0, 0, -7), weekStart(period.End).AddDate(0, 0, 7).Add(-time.Second)
+}
+
+// ComputeStats works out a period's statistics from the user's mood entries
+// and daily activity in its StatsWindow. now decides the current streak of
+// a period that hasn't ended.
+func ComputeStats(period *Period, moods []MoodSummary, activity []ActivityCount, now time.Time) *InsightStats {
+	stats := &InsightStats{
+		Period:       period.Key,
+		Start:        period.Start,
+		End:          period.End,
+		TopEmotional: []StateCount{},
+		TopSpiritual: []StateCount{},
+	}
+	inPeriod := func(t time.Time) bool {
+		return !t.Before(period.Start) && !t.After(period.End)
+	}
+
+	// Entries and active days
+	activeDays := map[string]bool{}
+	weekEntries := map[string]int{}
+	for _, a := range activity {
+		weekEntries[weekKey(a.Day)] += a.Count
+		if !inPeriod(a.Day) {
+			continue
+		}
+		stats.Entries.add(a.Source, a.Count)
+		if a.Count > 0 {
+			activeDays[a.Day.Format("2006-01-02")] = true
+		}
+	}
+	stats.ActiveDays = len(activeDays)
+	stats.Streaks = streaks(activeDays, period, now)
+
+	// Energy and states
+	distribution := make([]EnergyCount, 10)
+	for i := range distribution {
+		distribution[i].Level = i + 1
+	}
+	emotional := map[string]int{}
+	spiritual := map[string]int{}
+	weekEnergy := map[string][]int{}
+	totalEnergy, energyCount := 0, 0
+	for _, mood := range moods {
+		weekEnergy[weekKey(mood.Date)] = append(weekEnergy[weekKey(mood.Date)], mood.EnergyLevel)
+		if !inPeriod(mood.Date) {
+			continue
+		}
+		if mood.EnergyLevel >= 1 && mood.EnergyLevel <= 10 {
+			distribution[mood.EnergyLevel-1].Count++
+			totalEnergy += mood.EnergyLevel
+			energyCount++
+		}
+		if mood.EmotionalState != "" {
+			emotional[mood.EmotionalState]++
+		}
+		if mood.SpiritualState != "" {
+			spiritual[mood.SpiritualState]++
+		}
+	}
+	stats.Energy.Distribution = distribution
+	if energyCount > 0 {
+		stats.Energy.Average = round1(float64(totalEnergy) / float64(energyCount))
+	}
+	stats.TopEmotional = top(emotional, topStates)
+	stats.TopSpiritual = top(spiritual, topStates)
+
+	// Week by week, compared with the week before
+	previous := weekStart(period.Start).AddDate(0, 0, -7)
+	for start := weekStart(period.Start); !start.After(period.End); start = start.AddDate(0, 0, 7) {
+		key, previousKey := weekKey(start), weekKey(previous)
+		week := WeekStats{
+			Week:      key,
+			Start:     start.Format("2006-01-02"),
+			Entries:   weekEntries[key],
+			AvgEnergy: average(weekEnergy[key]),
+		}
+		if weekEntries[previousKey] > 0 || weekEntries[key] > 0 {
+			change := weekEntries[key] - weekEntries[previousKey]
+			week.EntriesChange = &change
+		}
+		if len(weekEnergy[key]) > 0 && len(weekEnergy[previousKey]) > 0 {
+			change := round1(week.AvgEnergy - average(weekEnergy[previousKey]))
+			week.EnergyChange = &change
+		}
+		stats.Weeks = append(stats.Weeks, week)
+		previous = start
+	}
+
+	return stats
+}
+
+// Summary describes the statistics in a line, for prompts and the like
+func (s *InsightStats) Summary() string {
+	return fmt.Sprintf("%d entries on %d days, longest streak %d days, average energy %.1f/10",
+		s.Entries.Total(), s.ActiveDays, s.Streaks.Longest, s.Energy.Average)
+}
+
+// streaks finds the longest run of active days in the period and the run
+// ending at its last day, or today if it hasn't ended
+func streaks(activeDays map[string]bool, period *Period, now time.Time) Streaks {
+	var result Streaks
+	run := 0
+	for day := period.Start; !day.After(period.End); day = day.AddDate(0, 0, 1) {
+		if activeDays[day.Format("2006-01-02")] {
+			run++
+			if run > result.Longest {
+				result.Longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	last := period.End
+	if now.Before(period.End) {
+		last = now
+	}
+	day := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+	// Today doesn't break the streak until it's over
+	if !activeDays[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for !day.Before(period.Start) && activeDays[day.Format("2006-01-02")] {
+		result.Current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return result
+}
+
+// weekStart is the Monday starting the ISO week containing t
+func weekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// weekKey is the ISO week containing t, e.g. "2025-W07"
+func weekKey(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// top lists the most frequent states, most frequent first and then by name
+func top(counts map[string]int, limit int) []StateCount {
+	result := make([]StateCount, 0, len(counts))
+	for state, count := range counts {
+		result = append(result, StateCount{State: state, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].State < result[j].State
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func average(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return round1(float64(total) / float64(len(values)))
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -4,15 +4,18 @@ import (
 	"net/http"
 	"strconv"
 
+	"armourup/internal/domain/safety"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Controller struct {
 	service *Service
+	safety  *safety.Service
 }
 
-func NewController(service *Service) *Controller {
-	return &Controller{service: service}
+func NewController(service *Service, safetyService *safety.Service) *Controller {
+	return &Controller{service: service, safety: safetyService}
 }
 
 func (c *Controller) CreateEntry(ctx *gin.Context) {
@@ -26,6 +29,7 @@ func (c *Controller) CreateEntry(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	entry.Safety = c.safety.CheckRequest(ctx, safety.SourceJournal, entry.Title+"\n"+entry.Content).Notice
 
 	ctx.JSON(http.StatusCreated, entry)
 }
@@ -3,6 +3,8 @@ package journal
 import (
 	"time"
 
+	"armourup/internal/domain/safety"
+
 	"gorm.io/gorm"
 )
 
@@ -15,4 +17,5 @@ type JournalEntry struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	Safety    *safety.Notice `json:"safety,omitempty" gorm:"-"` // Crisis resources when the entry was flagged
 } 
\ No newline at end of file
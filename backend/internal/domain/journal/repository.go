@@ -28,6 +28,13 @@ func (r *Repository) GetAll() ([]JournalEntry, error) {
 	return entries, err
 }
 
+// GetRecentByUserID retrieves a user's most recent journal entries
+func (r *Repository) GetRecentByUserID(userID uint, limit int) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
 func (r *Repository) Update(entry *JournalEntry) error {
 	return r.db.Save(entry).Error
 }
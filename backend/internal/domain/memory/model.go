@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryVerse is a verse in a user's memorisation deck, scheduled with SM-2
+type MemoryVerse struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UserID          uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_memory_verses_user_reference"`
+	Reference       string         `json:"reference" gorm:"not null;uniqueIndex:idx_memory_verses_user_reference"`
+	Text            string         `json:"text" gorm:"not null"`
+	EncouragementID *uint          `json:"encouragement_id,omitempty"`
+	EaseFactor      float64        `json:"ease_factor" gorm:"default:2.5"`
+	IntervalDays    int            `json:"interval_days" gorm:"default:0"`
+	Repetitions     int            `json:"repetitions" gorm:"default:0"`
+	Lapses          int            `json:"lapses" gorm:"default:0"`
+	DueAt           time.Time      `json:"due_at" gorm:"index"`
+	LastReviewedAt  *time.Time     `json:"last_reviewed_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// Review records a single review of a memory verse
+type Review struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	VerseID      uint      `json:"verse_id" gorm:"not null;index"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	Quality      int       `json:"quality"`
+	IntervalDays int       `json:"interval_days"`
+	EaseFactor   float64   `json:"ease_factor"`
+	ReviewedAt   time.Time `json:"reviewed_at" gorm:"index"`
+}
+
+// TableName keeps reviews grouped with the memory verse tables
+func (Review) TableName() string {
+	return "memory_reviews"
+}
+
+// AddVerseRequest represents the request to add a verse to the deck.
+// Either Reference and Text, or a combined Verse string, must be provided.
+type AddVerseRequest struct {
+	Reference string `json:"reference"`
+	Text      string `json:"text"`
+	Verse     string `json:"verse"`
+}
+
+// ReviewRequest represents a self-graded review on the SM-2 0-5 scale:
+// 5 perfect recall, 3 recalled with difficulty, below 3 forgotten
+type ReviewRequest struct {
+	Quality *int `json:"quality" binding:"required,min=0,max=5"`
+}
+
+// ClozeBlank is a word removed from the verse text in a quiz
+type ClozeBlank struct {
+	Position int    `json:"position"` // Index of the word within the verse
+	Answer   string `json:"answer"`
+}
+
+// ClozeQuiz is a fill-in-the-blank quiz generated from a verse
+type ClozeQuiz struct {
+	VerseID   uint         `json:"verse_id"`
+	Reference string       `json:"reference"`
+	Prompt    string       `json:"prompt"`
+	Blanks    []ClozeBlank `json:"blanks"`
+}
+
+// DeckStats summarises a user's memorisation progress
+type DeckStats struct {
+	TotalVerses   int     `json:"total_verses"`
+	DueNow        int     `json:"due_now"`
+	Learning      int     `json:"learning"`
+	Mature        int     `json:"mature"`
+	ReviewsLast30 int     `json:"reviews_last_30_days"`
+	RetentionRate float64 `json:"retention_rate"`
+	AverageEase   float64 `json:"average_ease"`
+	TotalLapses   int     `json:"total_lapses"`
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create adds a verse to a user's deck
+func (r *Repository) Create(verse *MemoryVerse) error {
+	return r.db.Create(verse).Error
+}
+
+// GetByID retrieves a memory verse by ID
+func (r *Repository) GetByID(id uint) (*MemoryVerse, error) {
+	var verse MemoryVerse
+	err := r.db.First(&verse, id).Error
+	return &verse, err
+}
+
+// GetByUserAndReference retrieves a verse in a user's deck by its reference
+func (r *Repository) GetByUserAndReference(userID uint, reference string) (*MemoryVerse, error) {
+	var verse MemoryVerse
+	err := r.db.Where("user_id = ? AND reference = ?", userID, reference).First(&verse).Error
+	return &verse, err
+}
+
+// GetByUserID retrieves a user's whole deck
+func (r *Repository) GetByUserID(userID uint) ([]MemoryVerse, error) {
+	var verses []MemoryVerse
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&verses).Error
+	return verses, err
+}
+
+// GetDue retrieves the verses due for review at the given time, most overdue first
+func (r *Repository) GetDue(userID uint, now time.Time, limit int) ([]MemoryVerse, error) {
+	var verses []MemoryVerse
+	err := r.db.Where("user_id = ? AND due_at <= ?", userID, now).
+		Order("due_at ASC").
+		Limit(limit).
+		Find(&verses).Error
+	return verses, err
+}
+
+// Update updates an existing memory verse
+func (r *Repository) Update(verse *MemoryVerse) error {
+	return r.db.Save(verse).Error
+}
+
+// Delete removes a verse from the deck. The delete is permanent so that the
+// verse can be added again later.
+func (r *Repository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("verse_id = ?", id).Delete(&Review{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&MemoryVerse{}, id).Error
+	})
+}
+
+// SaveReview updates the verse's schedule and records the review in one transaction
+func (r *Repository) SaveReview(verse *MemoryVerse, review *Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(verse).Error; err != nil {
+			return err
+		}
+		return tx.Create(review).Error
+	})
+}
+
+// GetReviewsSince retrieves a user's reviews since the given time
+func (r *Repository) GetReviewsSince(userID uint, since time.Time) ([]Review, error) {
+	var reviews []Review
+	err := r.db.Where("user_id = ? AND reviewed_at >= ?", userID, since).
+		Order("reviewed_at ASC").
+		Find(&reviews).Error
+	return reviews, err
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// minEaseFactor is the lowest ease SM-2 allows, so hard verses still progress
+	minEaseFactor = 1.3
+	// defaultEaseFactor is the starting ease for a new verse
+	defaultEaseFactor = 2.5
+	// matureInterval is the interval in days after which a verse counts as learned
+	matureInterval = 21
+	// passingQuality is the lowest grade that counts as a successful recall
+	passingQuality = 3
+)
+
+// Schedule applies an SM-2 review with the given quality (0-5) to the verse,
+// updating its ease factor, repetition count, interval and next due date
+func Schedule(verse *MemoryVerse, quality int, now time.Time) {
+	if verse.EaseFactor == 0 {
+		verse.EaseFactor = defaultEaseFactor
+	}
+
+	if quality < passingQuality {
+		// Forgotten: start the repetitions again but keep the ease history
+		verse.Repetitions = 0
+		verse.IntervalDays = 1
+		verse.Lapses++
+	} else {
+		verse.Repetitions++
+		switch verse.Repetitions {
+		case 1:
+			verse.IntervalDays = 1
+		case 2:
+			verse.IntervalDays = 6
+		default:
+			verse.IntervalDays = int(math.Round(float64(verse.IntervalDays) * verse.EaseFactor))
+		}
+	}
+
+	q := float64(5 - quality)
+	verse.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if verse.EaseFactor < minEaseFactor {
+		verse.EaseFactor = minEaseFactor
+	}
+
+	reviewedAt := now
+	verse.LastReviewedAt = &reviewedAt
+	verse.DueAt = now.AddDate(0, 0, verse.IntervalDays)
+}
+
+// stopWords are never blanked out in a cloze quiz as they give little practice
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "that": true, "with": true,
+	"you": true, "your": true, "are": true, "was": true, "his": true,
+	"him": true, "her": true, "not": true, "but": true, "all": true,
+	"who": true, "will": true, "shall": true, "from": true, "unto": true,
+	"into": true, "have": true, "has": true, "this": true, "they": true,
+	"them": true, "their": true, "upon": true,
+}
+
+// GenerateCloze builds a fill-in-the-blank quiz from verse text. The share of
+// words hidden grows with the number of successful repetitions so that the
+// quiz gets harder as the verse is learned. The same seed always produces the
+// same quiz.
+func GenerateCloze(text string, repetitions int, seed int64) (string, []ClozeBlank) {
+	words := strings.Fields(text)
+
+	var candidates []int
+	for i, w := range words {
+		core := strings.ToLower(trimPunctuation(w))
+		if len([]rune(core)) > 2 && !stopWords[core] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return text, []ClozeBlank{}
+	}
+
+	ratio := 0.2 + 0.15*float64(repetitions)
+	if ratio > 0.8 {
+		ratio = 0.8
+	}
+	count := int(math.Ceil(float64(len(candidates)) * ratio))
+
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	hidden := candidates[:count]
+	sort.Ints(hidden)
+
+	blanks := make([]ClozeBlank, 0, len(hidden))
+	for _, i := range hidden {
+		answer := trimPunctuation(words[i])
+		blanks = append(blanks, ClozeBlank{Position: i, Answer: answer})
+		words[i] = strings.Replace(words[i], answer, strings.Repeat("_", 5), 1)
+	}
+
+	return strings.Join(words, " "), blanks
+}
+
+// trimPunctuation removes leading and trailing punctuation from a word
+func trimPunctuation(word string) string {
+	return strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
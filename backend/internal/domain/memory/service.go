@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"armourup/internal/domain/encouragement"
+	"armourup/internal/scripture"
+)
+
+// defaultDueLimit caps how many verses a single review session returns
+const defaultDueLimit = 20
+
+type Service struct {
+	repo              *Repository
+	encouragementRepo *encouragement.Repository
+}
+
+func NewService(repo *Repository, encouragementRepo *encouragement.Repository) *Service {
+	return &Service{
+		repo:              repo,
+		encouragementRepo: encouragementRepo,
+	}
+}
+
+// AddVerse adds a verse to the user's deck, due for its first review immediately
+func (s *Service) AddVerse(userID uint, req AddVerseRequest) (*MemoryVerse, error) {
+	reference := strings.TrimSpace(req.Reference)
+	text := strings.TrimSpace(req.Text)
+	if req.Verse != "" && (reference == "" || text == "") {
+		reference, text = scripture.SplitVerse(req.Verse)
+	}
+	if reference == "" || text == "" {
+		return nil, errors.New("verse reference and text are required")
+	}
+
+	return s.addVerse(userID, reference, text, nil)
+}
+
+// MemoriseEncouragement adds the verse attached to an encouragement to the user's deck
+func (s *Service) MemoriseEncouragement(userID, encouragementID uint) (*MemoryVerse, error) {
+	enc, err := s.encouragementRepo.GetByID(encouragementID)
+	if err != nil {
+		return nil, errors.New("encouragement not found")
+	}
+
+	reference, text := enc.Reference, enc.Verse
+	if reference == "" {
+		reference, text = scripture.SplitVerse(enc.Verse)
+	}
+	if reference == "" || text == "" {
+		return nil, errors.New("encouragement has no verse to memorise")
+	}
+
+	return s.addVerse(userID, reference, text, &enc.ID)
+}
+
+func (s *Service) addVerse(userID uint, reference, text string, encouragementID *uint) (*MemoryVerse, error) {
+	existing, err := s.repo.GetByUserAndReference(userID, reference)
+	if err == nil && existing.ID > 0 {
+		return nil, errors.New("verse already in your memory deck")
+	}
+
+	verse := &MemoryVerse{
+		UserID:          userID,
+		Reference:       reference,
+		Text:            text,
+		EncouragementID: encouragementID,
+		EaseFactor:      defaultEaseFactor,
+		DueAt:           time.Now(),
+	}
+	if err := s.repo.Create(verse); err != nil {
+		return nil, err
+	}
+
+	return verse, nil
+}
+
+// GetDeck retrieves every verse in the user's deck
+func (s *Service) GetDeck(userID uint) ([]MemoryVerse, error) {
+	return s.repo.GetByUserID(userID)
+}
+
+// GetVerse retrieves a verse from the user's deck
+func (s *Service) GetVerse(id, userID uint) (*MemoryVerse, error) {
+	verse, err := s.repo.GetByID(id)
+	if err != nil || verse.UserID != userID {
+		return nil, errors.New("memory verse not found")
+	}
+	return verse, nil
+}
+
+// GetDueVerses retrieves the verses the user should review now
+func (s *Service) GetDueVerses(userID uint, limit int) ([]MemoryVerse, error) {
+	if limit < 1 {
+		limit = defaultDueLimit
+	}
+	return s.repo.GetDue(userID, time.Now(), limit)
+}
+
+// ReviewVerse grades a review of a verse and reschedules it using SM-2
+func (s *Service) ReviewVerse(id, userID uint, quality int) (*MemoryVerse, error) {
+	verse, err := s.GetVerse(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	Schedule(verse, quality, now)
+
+	review := &Review{
+		VerseID:      verse.ID,
+		UserID:       userID,
+		Quality:      quality,
+		IntervalDays: verse.IntervalDays,
+		EaseFactor:   verse.EaseFactor,
+		ReviewedAt:   now,
+	}
+	if err := s.repo.SaveReview(verse, review); err != nil {
+		return nil, err
+	}
+
+	return verse, nil
+}
+
+// GetQuiz generates a cloze-deletion quiz for a verse in the user's deck
+func (s *Service) GetQuiz(id, userID uint) (*ClozeQuiz, error) {
+	verse, err := s.GetVerse(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Seed with the review count so each review session gets a fresh quiz
+	seed := int64(verse.ID)*1000 + int64(verse.Repetitions+verse.Lapses)
+	prompt, blanks := GenerateCloze(verse.Text, verse.Repetitions, seed)
+
+	return &ClozeQuiz{
+		VerseID:   verse.ID,
+		Reference: verse.Reference,
+		Prompt:    prompt,
+		Blanks:    blanks,
+	}, nil
+}
+
+// DeleteVerse removes a verse from the user's deck
+func (s *Service) DeleteVerse(id, userID uint) error {
+	if _, err := s.GetVerse(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// GetStats summarises the user's deck and review retention over the last 30 days
+func (s *Service) GetStats(userID uint) (*DeckStats, error) {
+	verses, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	reviews, err := s.repo.GetReviewsSince(userID, now.AddDate(0, 0, -30))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &DeckStats{TotalVerses: len(verses)}
+	var totalEase float64
+	for _, v := range verses {
+		if !v.DueAt.After(now) {
+			stats.DueNow++
+		}
+		if v.IntervalDays >= matureInterval {
+			stats.Mature++
+		} else {
+			stats.Learning++
+		}
+		stats.TotalLapses += v.Lapses
+		totalEase += v.EaseFactor
+	}
+	if len(verses) > 0 {
+		stats.AverageEase = totalEase / float64(len(verses))
+	}
+
+	stats.ReviewsLast30 = len(reviews)
+	if len(reviews) > 0 {
+		passed := 0
+		for _, r := range reviews {
+			if r.Quality >= passingQuality {
+				passed++
+			}
+		}
+		stats.RetentionRate = float64(passed) / float64(len(reviews))
+	}
+
+	return stats, nil
+}
@@ -1,19 +1,23 @@
 package mood
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"armourup/internal/domain/safety"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Controller struct {
 	service *Service
+	safety  *safety.Service
 }
 
-func NewController(service *Service) *Controller {
-	return &Controller{service: service}
+func NewController(service *Service, safetyService *safety.Service) *Controller {
+	return &Controller{service: service, safety: safetyService}
 }
 
 // CreateEntry creates a new mood entry for the authenticated user
@@ -36,9 +40,14 @@ func (c *Controller) CreateEntry(ctx *gin.Context) {
 			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, ErrUnknownState) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	entry.Safety = c.safety.CheckRequest(ctx, safety.SourceMood, entry.Notes).Notice
 
 	ctx.JSON(http.StatusCreated, entry)
 }
@@ -167,9 +176,14 @@ func (c *Controller) UpdateEntry(ctx *gin.Context) {
 			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, ErrUnknownState) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	entry.Safety = c.safety.CheckRequest(ctx, safety.SourceMood, entry.Notes).Notice
 
 	ctx.JSON(http.StatusOK, entry)
 }
@@ -251,5 +265,156 @@ func (c *Controller) GetMoodTrends(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, trends)
 }
 
+// GetCorrelations reports how the user's energy and valence differ on days
+// they journal, give thanks, pray or read the Bible, over the last 90 days by
+// default
+func (c *Controller) GetCorrelations(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", strconv.Itoa(defaultCorrelationDays)))
+	if err != nil || days < 1 {
+		days = defaultCorrelationDays
+	}
+
+	report, err := c.service.GetCorrelations(userID.(uint), days)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetHeatmap retrieves a year of check-ins for a calendar heatmap, the
+// current year by default
+func (c *Controller) GetHeatmap(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	yearStr := ctx.DefaultQuery("year", strconv.Itoa(time.Now().UTC().Year()))
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+		return
+	}
+
+	heatmap, err := c.service.GetHeatmap(userID.(uint), year)
+	if err != nil {
+		if err.Error() == "invalid year" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, heatmap)
+}
+
+// GetVocabulary lists the emotional and spiritual states users can choose,
+// labelled for the ?locale or Accept-Language locale
+func (c *Controller) GetVocabulary(ctx *gin.Context) {
+	locale := ctx.Query("locale")
+	if locale == "" {
+		locale = safety.LocaleFromHeader(ctx.GetHeader("Accept-Language"))
+	}
+
+	vocabulary, err := c.service.GetVocabulary(locale)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, vocabulary)
+}
+
+// GetTerms lists every term in the mood vocabulary
+func (c *Controller) GetTerms(ctx *gin.Context) {
+	terms, err := c.service.GetTerms()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, terms)
+}
+
+// AddTerm adds a term to the mood vocabulary
+func (c *Controller) AddTerm(ctx *gin.Context) {
+	var term MoodTerm
+	if err := ctx.ShouldBindJSON(&term); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.service.AddTerm(&term); err != nil {
+		if err.Error() == "mood term already exists" {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, term)
+}
+
+// UpdateTerm updates a term in the mood vocabulary
+func (c *Controller) UpdateTerm(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	var update MoodTerm
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	term, err := c.service.UpdateTerm(uint(id), &update)
+	if err != nil {
+		switch err.Error() {
+		case "mood term not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "mood term already exists":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, term)
+}
+
+// DeleteTerm removes a term from the mood vocabulary
+func (c *Controller) DeleteTerm(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	if err := c.service.DeleteTerm(uint(id)); err != nil {
+		if err.Error() == "mood term not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
 
 
@@ -0,0 +1,293 @@
+package mood
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Practices whose link with mood is measured
+const (
+	PracticeJournal   = "journal"
+	PracticeGratitude = "gratitude"
+	PracticePrayer    = "prayer"
+	PracticeReading   = "reading"
+)
+
+// Measures of mood that practices are compared on
+const (
+	MeasureEnergy  = "energy"
+	MeasureValence = "valence"
+)
+
+// How sure we are that a practice and a measure are linked
+const (
+	ConfidenceInsufficient = "insufficient" // Too few days either way to say
+	ConfidenceLow          = "low"          // The difference could well be chance
+	ConfidenceModerate     = "moderate"     // The 95% interval excludes no difference
+	ConfidenceHigh         = "high"         // As moderate, with plenty of days either way
+)
+
+// MinCorrelationSamples is the fewest days with, and without, a practice
+// before a difference is reported. ConfidenceHighSamples is how many of each
+// a difference needs to be reported with high confidence, and
+// MaxCorrelationFindings how many of the strongest are described.
+const (
+	MinCorrelationSamples  = 5
+	ConfidenceHighSamples  = 20
+	MaxCorrelationFindings = 3
+)
+
+// defaultCorrelationDays is how far back correlations look by default
+const defaultCorrelationDays = 90
+
+var practices = []string{PracticeJournal, PracticeGratitude, PracticePrayer, PracticeReading}
+
+// PracticeDay is a day with a mood check-in and the practices done that day
+type PracticeDay struct {
+	Date        time.Time
+	EnergyLevel int
+	Valence     *float64 // Null when the day's states aren't in the vocabulary
+	Journal     bool
+	Gratitude   bool
+	Prayer      bool
+	Reading     bool
+}
+
+func (d PracticeDay) did(practice string) bool {
+	switch practice {
+	case PracticeJournal:
+		return d.Journal
+	case PracticeGratitude:
+		return d.Gratitude
+	case PracticePrayer:
+		return d.Prayer
+	case PracticeReading:
+		return d.Reading
+	}
+	return false
+}
+
+// Correlation compares a mood measure on days with and without a practice.
+// The means, difference and effect size are left out when there are fewer
+// than MinCorrelationSamples days either way.
+type Correlation struct {
+	Practice    string   `json:"practice"`
+	Measure     string   `json:"measure"`
+	DaysWith    int      `json:"days_with"`
+	DaysWithout int      `json:"days_without"`
+	MeanWith    *float64 `json:"mean_with"`
+	MeanWithout *float64 `json:"mean_without"`
+	Difference  *float64 `json:"difference"`  // MeanWith less MeanWithout
+	EffectSize  *float64 `json:"effect_size"` // Cohen's d
+	CILow       *float64 `json:"ci_low"`      // 95% confidence interval of the difference
+	CIHigh      *float64 `json:"ci_high"`
+	Confidence  string   `json:"confidence"`
+}
+
+// CorrelationReport is how each practice relates to the user's mood over a
+// period, with the strongest links in words
+type CorrelationReport struct {
+	Period       string        `json:"period"`
+	Days         int           `json:"days"` // Days with a check-in
+	MinSamples   int           `json:"min_samples"`
+	Correlations []Correlation `json:"correlations"`
+	Findings     []string      `json:"findings"`
+}
+
+// Correlate compares energy and valence on days with and without each
+// practice. Differences are tested with Welch's t-test, which doesn't assume
+// both groups of days vary as much.
+func Correlate(days []PracticeDay) []Correlation {
+	correlations := make([]Correlation, 0, 2*len(practices))
+	for _, practice := range practices {
+		var energyWith, energyWithout, valenceWith, valenceWithout []float64
+		for _, day := range days {
+			if day.did(practice) {
+				energyWith = append(energyWith, float64(day.EnergyLevel))
+				if day.Valence != nil {
+					valenceWith = append(valenceWith, *day.Valence)
+				}
+			} else {
+				energyWithout = append(energyWithout, float64(day.EnergyLevel))
+				if day.Valence != nil {
+					valenceWithout = append(valenceWithout, *day.Valence)
+				}
+			}
+		}
+		correlations = append(correlations,
+			compare(practice, MeasureEnergy, energyWith, energyWithout),
+			compare(practice, MeasureValence, valenceWith, valenceWithout),
+		)
+	}
+	return correlations
+}
+
+func compare(practice, measure string, with, without []float64) Correlation {
+	c := Correlation{
+		Practice:    practice,
+		Measure:     measure,
+		DaysWith:    len(with),
+		DaysWithout: len(without),
+		Confidence:  ConfidenceInsufficient,
+	}
+	if len(with) < MinCorrelationSamples || len(without) < MinCorrelationSamples {
+		return c
+	}
+
+	n1, n2 := float64(len(with)), float64(len(without))
+	mean1, var1 := meanVariance(with)
+	mean2, var2 := meanVariance(without)
+	diff := mean1 - mean2
+
+	c.MeanWith = rounded(mean1)
+	c.MeanWithout = rounded(mean2)
+	c.Difference = rounded(diff)
+
+	pooled := math.Sqrt(((n1-1)*var1 + (n2-1)*var2) / (n1 + n2 - 2))
+	switch {
+	case pooled > 0:
+		c.EffectSize = rounded(diff / pooled)
+	case diff == 0:
+		c.EffectSize = rounded(0)
+	}
+
+	// Welch's standard error and degrees of freedom
+	se1, se2 := var1/n1, var2/n2
+	se := math.Sqrt(se1 + se2)
+	margin := 0.0
+	if se > 0 {
+		df := (se1 + se2) * (se1 + se2) / (se1*se1/(n1-1) + se2*se2/(n2-1))
+		margin = tCritical(df) * se
+	}
+	low, high := diff-margin, diff+margin
+	c.CILow = rounded(low)
+	c.CIHigh = rounded(high)
+
+	switch {
+	case low <= 0 && high >= 0:
+		c.Confidence = ConfidenceLow
+	case len(with) >= ConfidenceHighSamples && len(without) >= ConfidenceHighSamples:
+		c.Confidence = ConfidenceHigh
+	default:
+		c.Confidence = ConfidenceModerate
+	}
+	return c
+}
+
+// meanVariance returns the mean and sample variance of at least two values
+func meanVariance(values []float64) (float64, float64) {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	squares := 0.0
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, squares / float64(len(values)-1)
+}
+
+// tTable holds the two-sided 95% critical values of Student's t for 1 to 30
+// degrees of freedom
+var tTable = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tCritical is the two-sided 95% critical value of t, rounding the degrees
+// of freedom down so the interval errs on the wide side
+func tCritical(df float64) float64 {
+	switch {
+	case df < 1:
+		return tTable[0]
+	case df <= float64(len(tTable)):
+		return tTable[int(df)-1]
+	case df < 40:
+		return 2.042
+	case df < 60:
+		return 2.021
+	case df < 120:
+		return 2.000
+	default:
+		return 1.980
+	}
+}
+
+func rounded(v float64) *float64 {
+	r := math.Round(v*100) / 100
+	return &r
+}
+
+// StrongestCorrelations picks up to limit of the correlations held with
+// moderate or high confidence, largest effect first
+func StrongestCorrelations(correlations []Correlation, limit int) []Correlation {
+	var strong []Correlation
+	for _, c := range correlations {
+		if (c.Confidence == ConfidenceModerate || c.Confidence == ConfidenceHigh) && c.Difference != nil {
+			strong = append(strong, c)
+		}
+	}
+	sort.SliceStable(strong, func(i, j int) bool {
+		return effect(strong[i]) > effect(strong[j])
+	})
+	if len(strong) > limit {
+		strong = strong[:limit]
+	}
+	return strong
+}
+
+// StrongestFindings describes the strongest correlations in words
+func StrongestFindings(correlations []Correlation, limit int) []string {
+	findings := []string{}
+	for _, c := range StrongestCorrelations(correlations, limit) {
+		findings = append(findings, c.Finding())
+	}
+	return findings
+}
+
+// effect is a correlation's absolute effect size, the largest when its
+// groups didn't vary at all
+func effect(c Correlation) float64 {
+	if c.EffectSize == nil {
+		return math.Inf(1)
+	}
+	return math.Abs(*c.EffectSize)
+}
+
+// practicePhrases are how each practice is written after "days you"
+var practicePhrases = map[string]string{
+	PracticeJournal:   "journaled",
+	PracticeGratitude: "gave thanks",
+	PracticePrayer:    "prayed",
+	PracticeReading:   "read the Bible",
+}
+
+// Finding describes a correlation in a sentence, e.g. "On days you prayed,
+// your energy averaged 7.2, 1.3 higher than on days you didn't (12 and 18
+// days)."
+func (c Correlation) Finding() string {
+	if c.Difference == nil {
+		return ""
+	}
+	phrase := practicePhrases[c.Practice]
+	days := fmt.Sprintf("(%d and %d days)", c.DaysWith, c.DaysWithout)
+
+	if c.Measure == MeasureValence {
+		direction := "more positive"
+		if *c.Difference < 0 {
+			direction = "less positive"
+		}
+		return fmt.Sprintf("On days you %s, your mood was %s than on days you didn't %s.", phrase, direction, days)
+	}
+
+	direction := "higher"
+	if *c.Difference < 0 {
+		direction = "lower"
+	}
+	return fmt.Sprintf("On days you %s, your energy averaged %.1f, %.1f %s than on days you didn't %s.",
+		phrase, *c.MeanWith, math.Abs(*c.Difference), direction, days)
+}
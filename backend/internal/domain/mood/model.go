@@ -3,6 +3,8 @@ package mood
 import (
 	"time"
 
+	"armourup/internal/domain/safety"
+
 	"gorm.io/gorm"
 )
 
@@ -19,6 +21,7 @@ type MoodEntry struct {
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	Safety          *safety.Notice `json:"safety,omitempty" gorm:"-"` // Crisis resources when the notes were flagged
 }
 
 // CreateMoodEntryRequest represents the request to create a new mood entry
@@ -42,11 +45,65 @@ type UpdateMoodEntryRequest struct {
 
 // MoodTrendStats represents aggregated mood statistics for trends
 type MoodTrendStats struct {
-	Period          string  `json:"period"`
-	AvgEnergyLevel  float64 `json:"avg_energy_level"`
-	EmotionalStates map[string]int `json:"emotional_states"`
-	SpiritualStates map[string]int `json:"spiritual_states"`
-	TotalEntries    int     `json:"total_entries"`
+	Period          string           `json:"period"`
+	AvgEnergyLevel  float64          `json:"avg_energy_level"`
+	AvgValence      *float64         `json:"avg_valence"` // Null when no entry's states are in the vocabulary
+	EmotionalStates map[string]int   `json:"emotional_states"`
+	SpiritualStates map[string]int   `json:"spiritual_states"`
+	TotalEntries    int              `json:"total_entries"`
+	Streaks         Streaks          `json:"streaks"`
+	Rolling         []RollingAverage `json:"rolling"`     // Each day with an entry, with the averages up to it
+	DayOfWeek       []DayOfWeekStats `json:"day_of_week"` // Monday first
+	Volatility      Volatility       `json:"volatility"`
+}
+
+// Streaks are runs of consecutive days with a mood check-in. Today doesn't
+// break the current streak until it's over.
+type Streaks struct {
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
+
+// RollingAverage is a day's energy level and valence with their 7 and
+// 30-day averages, which include earlier days outside the trend's period
+type RollingAverage struct {
+	Date         string   `json:"date"`
+	EnergyLevel  int      `json:"energy_level"`
+	Avg7         float64  `json:"avg_7"`
+	Avg30        float64  `json:"avg_30"`
+	Valence      *float64 `json:"valence"`
+	ValenceAvg7  *float64 `json:"valence_avg_7"`
+	ValenceAvg30 *float64 `json:"valence_avg_30"`
+}
+
+// DayOfWeekStats are the check-ins made on one day of the week
+type DayOfWeekStats struct {
+	Day            string   `json:"day"`
+	Entries        int      `json:"entries"`
+	AvgEnergyLevel float64  `json:"avg_energy_level"`
+	AvgValence     *float64 `json:"avg_valence"`
+}
+
+// Volatility is how much energy levels vary: their standard deviation, and
+// the average change from one check-in to the next
+type Volatility struct {
+	StdDev         float64 `json:"std_dev"`
+	AvgDailyChange float64 `json:"avg_daily_change"`
+}
+
+// HeatmapDay is one day's check-in on the calendar heatmap
+type HeatmapDay struct {
+	Date           string   `json:"date"`
+	EnergyLevel    int      `json:"energy_level"`
+	EmotionalState string   `json:"emotional_state"`
+	SpiritualState string   `json:"spiritual_state"`
+	Valence        *float64 `json:"valence"`
+}
+
+// Heatmap is a year of check-ins for a calendar heatmap
+type Heatmap struct {
+	Year int          `json:"year"`
+	Days []HeatmapDay `json:"days"`
 }
 
 
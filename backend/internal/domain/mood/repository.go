@@ -69,5 +69,288 @@ func (r *Repository) GetRecentEntries(userID uint, limit int) ([]MoodEntry, erro
 	return entries, err
 }
 
+// valenceJoin joins each entry to the vocabulary terms for its states, as
+// et and st
+const valenceJoin = `
+	LEFT JOIN mood_terms et ON et.kind = 'emotional' AND et.key = mood_entries.emotional_state AND et.deleted_at IS NULL
+	LEFT JOIN mood_terms st ON st.kind = 'spiritual' AND st.key = mood_entries.spiritual_state AND st.deleted_at IS NULL`
+
+// entryValence is an entry's valence: the mean of its two states' valence,
+// the one state's when only one is in the vocabulary, or NULL
+const entryValence = `(COALESCE(et.valence, st.valence) + COALESCE(st.valence, et.valence)) / 2`
+
+// trendSummary is a count of entries with their average energy and valence
+type trendSummary struct {
+	Total      int
+	AvgEnergy  float64
+	AvgValence *float64
+}
+
+// GetTrendSummary counts a user's mood entries within a date range and
+// averages their energy and valence
+func (r *Repository) GetTrendSummary(userID uint, startDate, endDate time.Time) (trendSummary, error) {
+	var summary trendSummary
+	err := r.db.Raw(`
+		SELECT COUNT(*) AS total, COALESCE(AVG(energy_level), 0)::float8 AS avg_energy,
+			ROUND(AVG(`+entryValence+`)::numeric, 2)::float8 AS avg_valence
+		FROM mood_entries`+valenceJoin+`
+		WHERE mood_entries.user_id = ? AND date >= ? AND date <= ? AND mood_entries.deleted_at IS NULL`,
+		userID, startDate, endDate,
+	).Scan(&summary).Error
+	return summary, err
+}
+
+// GetStateCounts counts a user's mood entries within a date range by the
+// value of a state column, emotional_state or spiritual_state
+func (r *Repository) GetStateCounts(userID uint, column string, startDate, endDate time.Time) (map[string]int, error) {
+	var rows []struct {
+		State string
+		Count int
+	}
+	err := r.db.Model(&MoodEntry{}).
+		Select(column+" AS state, COUNT(*) AS count").
+		Where("user_id = ? AND date >= ? AND date <= ?", userID, startDate, endDate).
+		Group(column).
+		Scan(&rows).Error
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.State] = row.Count
+	}
+	return counts, err
+}
+
+// GetStreaks finds a user's longest run of consecutive days with a mood
+// entry, and the run still going on since, if it ended on or after.
+// Consecutive days are grouped by their date less their row number, which is
+// the same for every day of a run.
+func (r *Repository) GetStreaks(userID uint, since time.Time) (Streaks, error) {
+	var streaks Streaks
+	err := r.db.Raw(`
+		WITH runs AS (
+			SELECT MAX(date) AS last_day, COUNT(*) AS length FROM (
+				SELECT date, date - (ROW_NUMBER() OVER (ORDER BY date))::int AS run
+				FROM (SELECT DISTINCT date FROM mood_entries WHERE user_id = ? AND deleted_at IS NULL) days
+			) numbered
+			GROUP BY run
+		)
+		SELECT
+			COALESCE((SELECT length FROM runs WHERE last_day >= ? ORDER BY last_day DESC LIMIT 1), 0) AS current,
+			COALESCE(MAX(length), 0) AS longest
+		FROM runs`,
+		userID, since,
+	).Scan(&streaks).Error
+	return streaks, err
+}
+
+// GetRollingAverages lists a user's entries within a date range with the
+// average energy and valence of the 7 and 30 days up to each, which look
+// back before the start of the range
+func (r *Repository) GetRollingAverages(userID uint, startDate, endDate time.Time) ([]RollingAverage, error) {
+	var averages []RollingAverage
+	err := r.db.Raw(`
+		SELECT to_char(date, 'YYYY-MM-DD') AS date, energy_level, avg7, avg30,
+			ROUND(valence::numeric, 2)::float8 AS valence, valence_avg7, valence_avg30
+		FROM (
+			SELECT date, energy_level, valence,
+				ROUND(AVG(energy_level) OVER last7, 2)::float8 AS avg7,
+				ROUND(AVG(energy_level) OVER last30, 2)::float8 AS avg30,
+				ROUND(AVG(valence::numeric) OVER last7, 2)::float8 AS valence_avg7,
+				ROUND(AVG(valence::numeric) OVER last30, 2)::float8 AS valence_avg30
+			FROM (
+				SELECT date, energy_level, `+entryValence+` AS valence
+				FROM mood_entries`+valenceJoin+`
+				WHERE mood_entries.user_id = ? AND date >= ?::date - 29 AND date <= ? AND mood_entries.deleted_at IS NULL
+			) scored
+			WINDOW last7 AS (ORDER BY date RANGE BETWEEN INTERVAL '6 days' PRECEDING AND CURRENT ROW),
+				last30 AS (ORDER BY date RANGE BETWEEN INTERVAL '29 days' PRECEDING AND CURRENT ROW)
+		) windowed
+		WHERE date >= ?
+		ORDER BY date`,
+		userID, startDate, endDate, startDate,
+	).Scan(&averages).Error
+	return averages, err
+}
+
+// GetDayOfWeekStats counts a user's entries within a date range and averages
+// their energy and valence for each ISO day of the week, 1 being Monday
+func (r *Repository) GetDayOfWeekStats(userID uint, startDate, endDate time.Time) (map[int]DayOfWeekStats, error) {
+	var rows []struct {
+		Weekday    int
+		Entries    int
+		AvgEnergy  float64
+		AvgValence *float64
+	}
+	err := r.db.Raw(`
+		SELECT EXTRACT(ISODOW FROM date)::int AS weekday, COUNT(*) AS entries,
+			ROUND(AVG(energy_level), 2)::float8 AS avg_energy,
+			ROUND(AVG(`+entryValence+`)::numeric, 2)::float8 AS avg_valence
+		FROM mood_entries`+valenceJoin+`
+		WHERE mood_entries.user_id = ? AND date >= ? AND date <= ? AND mood_entries.deleted_at IS NULL
+		GROUP BY weekday`,
+		userID, startDate, endDate,
+	).Scan(&rows).Error
+	stats := make(map[int]DayOfWeekStats, len(rows))
+	for _, row := range rows {
+		stats[row.Weekday] = DayOfWeekStats{Entries: row.Entries, AvgEnergyLevel: row.AvgEnergy, AvgValence: row.AvgValence}
+	}
+	return stats, err
+}
+
+// GetVolatility measures how much a user's energy levels varied within a
+// date range, comparing each entry with the one before using LAG
+func (r *Repository) GetVolatility(userID uint, startDate, endDate time.Time) (Volatility, error) {
+	var volatility Volatility
+	err := r.db.Raw(`
+		SELECT
+			COALESCE(ROUND(STDDEV_POP(energy_level), 2), 0)::float8 AS std_dev,
+			COALESCE(ROUND(AVG(ABS(change)), 2), 0)::float8 AS avg_daily_change
+		FROM (
+			SELECT energy_level, energy_level - LAG(energy_level) OVER (ORDER BY date) AS change
+			FROM mood_entries
+			WHERE user_id = ? AND date >= ? AND date <= ? AND deleted_at IS NULL
+		) changes`,
+		userID, startDate, endDate,
+	).Scan(&volatility).Error
+	return volatility, err
+}
+
+// GetHeatmap retrieves a user's check-ins within a date range, one per day,
+// with their valence
+func (r *Repository) GetHeatmap(userID uint, startDate, endDate time.Time) ([]HeatmapDay, error) {
+	var days []HeatmapDay
+	err := r.db.Raw(`
+		SELECT to_char(date, 'YYYY-MM-DD') AS date, energy_level, emotional_state, spiritual_state,
+			ROUND((`+entryValence+`)::numeric, 2)::float8 AS valence
+		FROM mood_entries`+valenceJoin+`
+		WHERE mood_entries.user_id = ? AND date >= ? AND date <= ? AND mood_entries.deleted_at IS NULL
+		ORDER BY date ASC`,
+		userID, startDate, endDate,
+	).Scan(&days).Error
+	return days, err
+}
+
+// CountTerms counts every term in the vocabulary, active or not
+func (r *Repository) CountTerms() (int64, error) {
+	var count int64
+	err := r.db.Model(&MoodTerm{}).Count(&count).Error
+	return count, err
+}
+
+// CreateTerms adds terms to the vocabulary in a single batch
+func (r *Repository) CreateTerms(terms []MoodTerm) error {
+	return r.db.CreateInBatches(terms, 100).Error
+}
+
+// CreateTerm adds a term to the vocabulary
+func (r *Repository) CreateTerm(term *MoodTerm) error {
+	return r.db.Create(term).Error
+}
+
+// GetTerm retrieves a vocabulary term by ID
+func (r *Repository) GetTerm(id uint) (*MoodTerm, error) {
+	var term MoodTerm
+	err := r.db.First(&term, id).Error
+	return &term, err
+}
+
+// GetTermByKey retrieves the term of a kind with a key
+func (r *Repository) GetTermByKey(kind, key string) (*MoodTerm, error) {
+	var term MoodTerm
+	err := r.db.Where("kind = ? AND key = ?", kind, key).First(&term).Error
+	return &term, err
+}
+
+// GetTerms retrieves every term in the vocabulary
+func (r *Repository) GetTerms() ([]MoodTerm, error) {
+	var terms []MoodTerm
+	err := r.db.Order("kind ASC, id ASC").Find(&terms).Error
+	return terms, err
+}
+
+// GetActiveTerms retrieves the terms users can currently choose
+func (r *Repository) GetActiveTerms() ([]MoodTerm, error) {
+	var terms []MoodTerm
+	err := r.db.Where("active = ?", true).Order("kind ASC, id ASC").Find(&terms).Error
+	return terms, err
+}
+
+// UpdateTerm updates an existing vocabulary term and, in the same
+// transaction, rewrites state columns on mood entries as RenameStates does
+func (r *Repository) UpdateTerm(term *MoodTerm, renames []StateMapping) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(term).Error; err != nil {
+			return err
+		}
+		return renameStates(tx, renames)
+	})
+}
+
+// DeleteTerm soft deletes a vocabulary term
+func (r *Repository) DeleteTerm(id uint) error {
+	return r.db.Delete(&MoodTerm{}, id).Error
+}
+
+// CountStates counts every user's mood entries, including deleted ones, by
+// the value of a state column, emotional_state or spiritual_state
+func (r *Repository) CountStates(column string) (map[string]int, error) {
+	var rows []struct {
+		State string
+		Count int
+	}
+	err := r.db.Unscoped().Model(&MoodEntry{}).
+		Select(column + " AS state, COUNT(*) AS count").
+		Group(column).
+		Scan(&rows).Error
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.State] = row.Count
+	}
+	return counts, err
+}
+
+// RenameStates rewrites state columns on every mood entry, including
+// deleted ones, in a single transaction
+func (r *Repository) RenameStates(mappings []StateMapping) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return renameStates(tx, mappings)
+	})
+}
+
+func renameStates(tx *gorm.DB, mappings []StateMapping) error {
+	for _, mapping := range mappings {
+		column := mapping.Kind + "_state"
+		err := tx.Unscoped().Model(&MoodEntry{}).
+			Where(column+" = ?", mapping.From).
+			Update(column, mapping.To).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPracticeDays lists a user's mood entries within a date range with their
+// valence and whether the user journaled, gave thanks, prayed for someone or
+// completed a Bible reading the same day
+func (r *Repository) GetPracticeDays(userID uint, startDate, endDate time.Time) ([]PracticeDay, error) {
+	var days []PracticeDay
+	err := r.db.Raw(`
+		SELECT mood_entries.date, energy_level, `+entryValence+` AS valence,
+			EXISTS (SELECT 1 FROM journal_entries j WHERE j.user_id = mood_entries.user_id
+				AND j.created_at::date = mood_entries.date AND j.deleted_at IS NULL) AS journal,
+			EXISTS (SELECT 1 FROM gratitude_entries g WHERE g.user_id = mood_entries.user_id
+				AND g.created_at::date = mood_entries.date AND g.deleted_at IS NULL) AS gratitude,
+			EXISTS (SELECT 1 FROM prayer_logs p WHERE p.user_id = mood_entries.user_id
+				AND p.prayed_at::date = mood_entries.date) AS prayer,
+			EXISTS (SELECT 1 FROM reading_progress rp WHERE rp.user_id = mood_entries.user_id
+				AND rp.completed_at::date = mood_entries.date) AS reading
+		FROM mood_entries`+valenceJoin+`
+		WHERE mood_entries.user_id = ? AND date >= ? AND date <= ? AND mood_entries.deleted_at IS NULL
+		ORDER BY date ASC`,
+		userID, startDate, endDate,
+	).Scan(&days).Error
+	return days, err
+}
+
 
 
@@ -1,12 +1,19 @@
 package mood
 
 import (
+	_ "embed"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+//go:embed vocabulary.json
+var defaultVocabularyJSON []byte
+
 type Service struct {
 	repo *Repository
 }
@@ -29,6 +36,19 @@ func (s *Service) CreateEntry(userID uint, req CreateMoodEntryRequest) (*MoodEnt
 		entryDate = time.Now().UTC().Truncate(24 * time.Hour)
 	}
 
+	vocabulary, err := s.activeVocabulary()
+	if err != nil {
+		return nil, err
+	}
+	emotionalState, err := resolveState(vocabulary, KindEmotional, req.EmotionalState)
+	if err != nil {
+		return nil, err
+	}
+	spiritualState, err := resolveState(vocabulary, KindSpiritual, req.SpiritualState)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if entry already exists for this date
 	existing, err := s.repo.GetTodayEntry(userID, entryDate)
 	if err == nil && existing.ID > 0 {
@@ -37,8 +57,8 @@ func (s *Service) CreateEntry(userID uint, req CreateMoodEntryRequest) (*MoodEnt
 
 	entry := &MoodEntry{
 		UserID:         userID,
-		EmotionalState: req.EmotionalState,
-		SpiritualState: req.SpiritualState,
+		EmotionalState: emotionalState,
+		SpiritualState: spiritualState,
 		EnergyLevel:    req.EnergyLevel,
 		Gratitude:      req.Gratitude,
 		Notes:          req.Notes,
@@ -92,12 +112,23 @@ func (s *Service) UpdateEntry(id, userID uint, req UpdateMoodEntryRequest) (*Moo
 		return nil, errors.New("unauthorized to update this entry")
 	}
 
+	vocabulary, err := s.activeVocabulary()
+	if err != nil {
+		return nil, err
+	}
+
 	// Update fields if provided
 	if req.EmotionalState != "" {
-		entry.EmotionalState = req.EmotionalState
+		entry.EmotionalState, err = resolveState(vocabulary, KindEmotional, req.EmotionalState)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if req.SpiritualState != "" {
-		entry.SpiritualState = req.SpiritualState
+		entry.SpiritualState, err = resolveState(vocabulary, KindSpiritual, req.SpiritualState)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if req.EnergyLevel > 0 {
 		entry.EnergyLevel = req.EnergyLevel
@@ -132,45 +163,259 @@ func (s *Service) GetRecentEntries(userID uint, limit int) ([]MoodEntry, error)
 	return s.repo.GetRecentEntries(userID, limit)
 }
 
-// GetMoodTrends calculates mood trends for a user over a period
+// GetMoodTrends calculates mood trends for a user over the last days: the
+// average energy and valence, state counts, streaks, rolling averages, day-of-week
+// patterns and volatility. They are aggregated in the database rather than
+// by loading every entry.
 func (s *Service) GetMoodTrends(userID uint, days int) (*MoodTrendStats, error) {
 	endDate := time.Now().UTC()
 	startDate := endDate.AddDate(0, 0, -days)
+	today := endDate.Truncate(24 * time.Hour)
 
-	entries, err := s.repo.GetByUserIDAndDateRange(userID, startDate, endDate)
+	summary, err := s.repo.GetTrendSummary(userID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
-
-	if len(entries) == 0 {
-		return &MoodTrendStats{
-			Period:          startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
-			AvgEnergyLevel:  0,
-			EmotionalStates: make(map[string]int),
-			SpiritualStates: make(map[string]int),
-			TotalEntries:    0,
-		}, nil
+	emotionalStates, err := s.repo.GetStateCounts(userID, "emotional_state", startDate, endDate)
+	if err != nil {
+		return nil, err
 	}
-
-	// Calculate statistics
-	var totalEnergy int
-	emotionalStates := make(map[string]int)
-	spiritualStates := make(map[string]int)
-
-	for _, entry := range entries {
-		totalEnergy += entry.EnergyLevel
-		emotionalStates[entry.EmotionalState]++
-		spiritualStates[entry.SpiritualState]++
+	spiritualStates, err := s.repo.GetStateCounts(userID, "spiritual_state", startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	streaks, err := s.repo.GetStreaks(userID, today.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	rolling, err := s.repo.GetRollingAverages(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	weekdays, err := s.repo.GetDayOfWeekStats(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	volatility, err := s.repo.GetVolatility(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
 	}
 
-	avgEnergy := float64(totalEnergy) / float64(len(entries))
-
+	if rolling == nil {
+		rolling = []RollingAverage{}
+	}
 	return &MoodTrendStats{
 		Period:          startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
-		AvgEnergyLevel:  avgEnergy,
+		AvgEnergyLevel:  summary.AvgEnergy,
+		AvgValence:      summary.AvgValence,
 		EmotionalStates: emotionalStates,
 		SpiritualStates: spiritualStates,
-		TotalEntries:    len(entries),
+		TotalEntries:    summary.Total,
+		Streaks:         streaks,
+		Rolling:         rolling,
+		DayOfWeek:       DayOfWeekPattern(weekdays),
+		Volatility:      volatility,
+	}, nil
+}
+
+// DayOfWeekPattern lists the stats for every day of the week, Monday first,
+// from stats keyed by ISO weekday. Days without entries are included empty.
+func DayOfWeekPattern(stats map[int]DayOfWeekStats) []DayOfWeekStats {
+	pattern := make([]DayOfWeekStats, 0, 7)
+	for weekday := 1; weekday <= 7; weekday++ {
+		day := stats[weekday]
+		day.Day = time.Weekday(weekday % 7).String()
+		pattern = append(pattern, day)
+	}
+	return pattern
+}
+
+// GetHeatmap retrieves a year of the user's check-ins for a calendar heatmap
+func (s *Service) GetHeatmap(userID uint, year int) (*Heatmap, error) {
+	if year < 1 || year > 9999 {
+		return nil, errors.New("invalid year")
+	}
+	startDate := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	days, err := s.repo.GetHeatmap(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if days == nil {
+		days = []HeatmapDay{}
+	}
+	return &Heatmap{Year: year, Days: days}, nil
+}
+
+// activeVocabulary builds the vocabulary users can currently choose from
+func (s *Service) activeVocabulary() (*Vocabulary, error) {
+	terms, err := s.repo.GetActiveTerms()
+	if err != nil {
+		return nil, err
+	}
+	return NewVocabulary(terms), nil
+}
+
+// resolveState returns the term key for a state. Any state is accepted for a
+// kind the vocabulary has no terms of.
+func resolveState(vocabulary *Vocabulary, kind, value string) (string, error) {
+	if !vocabulary.Has(kind) {
+		return value, nil
+	}
+	term, ok := vocabulary.Resolve(kind, value)
+	if !ok {
+		return "", fmt.Errorf("%w: %s state %q", ErrUnknownState, kind, value)
+	}
+	return term.Key, nil
+}
+
+// SeedDefaultVocabulary fills an empty vocabulary with the terms shipped
+// with the service. A vocabulary that admins have already edited is left
+// untouched.
+func (s *Service) SeedDefaultVocabulary() error {
+	count, err := s.repo.CountTerms()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var terms []MoodTerm
+	if err := json.Unmarshal(defaultVocabularyJSON, &terms); err != nil {
+		return fmt.Errorf("failed to parse default mood vocabulary: %w", err)
+	}
+	return s.repo.CreateTerms(terms)
+}
+
+// GetVocabulary lists the active terms, labelled for a locale
+func (s *Service) GetVocabulary(locale string) (*LocalizedVocabulary, error) {
+	terms, err := s.repo.GetActiveTerms()
+	if err != nil {
+		return nil, err
+	}
+	return Localize(terms, locale), nil
+}
+
+// GetTerms retrieves every term in the vocabulary for admins
+func (s *Service) GetTerms() ([]MoodTerm, error) {
+	return s.repo.GetTerms()
+}
+
+// AddTerm adds a term to the vocabulary
+func (s *Service) AddTerm(term *MoodTerm) error {
+	term.Key = normaliseKey(term.Key)
+	if _, err := s.repo.GetTermByKey(term.Kind, term.Key); err == nil {
+		return errors.New("mood term already exists")
+	}
+	return s.repo.CreateTerm(term)
+}
+
+// UpdateTerm replaces a term's key, labels, synonyms and scores. Entries
+// store the key, so when it changes they are rewritten to the new one.
+func (s *Service) UpdateTerm(id uint, update *MoodTerm) (*MoodTerm, error) {
+	term, err := s.repo.GetTerm(id)
+	if err != nil {
+		return nil, errors.New("mood term not found")
+	}
+	previous := StateMapping{Kind: term.Kind, From: term.Key}
+
+	update.Key = normaliseKey(update.Key)
+	if existing, err := s.repo.GetTermByKey(update.Kind, update.Key); err == nil && existing.ID != term.ID {
+		return nil, errors.New("mood term already exists")
+	}
+
+	term.Kind = update.Kind
+	term.Key = update.Key
+	term.Label = update.Label
+	term.Labels = update.Labels
+	term.Synonyms = update.Synonyms
+	term.Valence = update.Valence
+	term.Arousal = update.Arousal
+	if update.Active != nil {
+		term.Active = update.Active
+	}
+
+	var renames []StateMapping
+	if term.Key != previous.From {
+		previous.To = term.Key
+		renames = append(renames, previous)
+	}
+	if err := s.repo.UpdateTerm(term, renames); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// DeleteTerm removes a term from the vocabulary. Entries keep its key.
+func (s *Service) DeleteTerm(id uint) error {
+	if _, err := s.repo.GetTerm(id); err != nil {
+		return errors.New("mood term not found")
+	}
+	return s.repo.DeleteTerm(id)
+}
+
+// normaliseKey turns a key into its stored form, e.g. "Burned Out" into
+// "burned_out"
+func normaliseKey(key string) string {
+	return strings.ReplaceAll(NormaliseState(key), " ", "_")
+}
+
+// MapExistingEntries rewrites the free-text states on existing entries to
+// the keys of the terms they match, including inactive terms. States that
+// match nothing are reported and left as they are. A dry run only reports.
+func (s *Service) MapExistingEntries(dryRun bool) (*MappingReport, error) {
+	terms, err := s.repo.GetTerms()
+	if err != nil {
+		return nil, err
+	}
+	vocabulary := NewVocabulary(terms)
+
+	report := &MappingReport{DryRun: dryRun, Mapped: []StateMapping{}, Unmapped: []StateMapping{}}
+	for _, kind := range []string{KindEmotional, KindSpiritual} {
+		counts, err := s.repo.CountStates(kind + "_state")
+		if err != nil {
+			return nil, err
+		}
+		mapped, unmapped := PlanMapping(vocabulary, kind, counts)
+		report.Mapped = append(report.Mapped, mapped...)
+		report.Unmapped = append(report.Unmapped, unmapped...)
+	}
+
+	if dryRun || len(report.Mapped) == 0 {
+		return report, nil
+	}
+	if err := s.repo.RenameStates(report.Mapped); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetCorrelations measures how the user's practices relate to their mood
+// over the last days
+func (s *Service) GetCorrelations(userID uint, days int) (*CorrelationReport, error) {
+	endDate := time.Now().UTC()
+	startDate := endDate.AddDate(0, 0, -days)
+	return s.GetCorrelationsInRange(userID, startDate, endDate)
+}
+
+// GetCorrelationsInRange compares the user's energy and valence on days with
+// and without each practice within a date range, describing the strongest
+// links found
+func (s *Service) GetCorrelationsInRange(userID uint, startDate, endDate time.Time) (*CorrelationReport, error) {
+	days, err := s.repo.GetPracticeDays(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	correlations := Correlate(days)
+	return &CorrelationReport{
+		Period:       startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
+		Days:         len(days),
+		MinSamples:   MinCorrelationSamples,
+		Correlations: correlations,
+		Findings:     StrongestFindings(correlations, MaxCorrelationFindings),
 	}, nil
 }
 
@@ -0,0 +1,190 @@
+package mood
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of mood term, one for each state on an entry
+const (
+	KindEmotional = "emotional"
+	KindSpiritual = "spiritual"
+)
+
+// ErrUnknownState is returned when an entry's state isn't in the vocabulary
+var ErrUnknownState = errors.New("unknown mood state")
+
+// MoodTerm is an admin-managed emotional or spiritual state. Entries store
+// its key; the label, translations and synonyms are what users may type.
+// Valence (unpleasant to pleasant) and arousal (calm to intense) range from
+// -1 to 1.
+type MoodTerm struct {
+	ID        uint              `json:"id" gorm:"primaryKey"`
+	Kind      string            `json:"kind" binding:"required,oneof=emotional spiritual" gorm:"size:20;not null;uniqueIndex:idx_mood_terms_kind_key,where:deleted_at IS NULL"`
+	Key       string            `json:"key" binding:"required,max=50" gorm:"size:50;not null;uniqueIndex:idx_mood_terms_kind_key,where:deleted_at IS NULL"`
+	Label     string            `json:"label" binding:"required"`
+	Labels    map[string]string `json:"labels" gorm:"type:jsonb;serializer:json"` // Translations by locale, e.g. {"es": "Alegre"}
+	Synonyms  []string          `json:"synonyms" gorm:"type:jsonb;serializer:json"`
+	Valence   float64           `json:"valence" binding:"min=-1,max=1"`
+	Arousal   float64           `json:"arousal" binding:"min=-1,max=1"`
+	Active    *bool             `json:"active" gorm:"default:true"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	DeletedAt gorm.DeletedAt    `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// LabelFor returns the term's label in a locale such as "es-MX", falling
+// back to the language ("es") and then to the default label
+func (t MoodTerm) LabelFor(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if locale == "" {
+		return t.Label
+	}
+	for key, label := range t.Labels {
+		if strings.EqualFold(key, locale) {
+			return label
+		}
+	}
+	language := strings.Split(locale, "-")[0]
+	for key, label := range t.Labels {
+		if strings.EqualFold(key, language) {
+			return label
+		}
+	}
+	return t.Label
+}
+
+// Vocabulary looks up mood terms by what users write for them
+type Vocabulary struct {
+	kinds map[string]bool
+	words map[string]map[string]*MoodTerm // By kind, then normalised word
+}
+
+// NewVocabulary indexes terms by key, label, translated labels and
+// synonyms. A key always wins over another term's label or synonym.
+func NewVocabulary(terms []MoodTerm) *Vocabulary {
+	v := &Vocabulary{kinds: make(map[string]bool), words: make(map[string]map[string]*MoodTerm)}
+	add := func(term *MoodTerm, word string) {
+		word = NormaliseState(word)
+		if word == "" {
+			return
+		}
+		if _, taken := v.words[term.Kind][word]; !taken {
+			v.words[term.Kind][word] = term
+		}
+	}
+
+	for i := range terms {
+		term := &terms[i]
+		v.kinds[term.Kind] = true
+		if v.words[term.Kind] == nil {
+			v.words[term.Kind] = make(map[string]*MoodTerm)
+		}
+		add(term, term.Key)
+	}
+	for i := range terms {
+		term := &terms[i]
+		add(term, term.Label)
+		for _, label := range term.Labels {
+			add(term, label)
+		}
+		for _, synonym := range term.Synonyms {
+			add(term, synonym)
+		}
+	}
+	return v
+}
+
+// Has reports whether the vocabulary has any terms of a kind
+func (v *Vocabulary) Has(kind string) bool {
+	return v.kinds[kind]
+}
+
+// Resolve finds the term for a state, ignoring case and spacing, so
+// "Happy", "happy" and "joyful" all resolve to the same term
+func (v *Vocabulary) Resolve(kind, value string) (*MoodTerm, bool) {
+	term, ok := v.words[kind][NormaliseState(value)]
+	return term, ok
+}
+
+// NormaliseState lowercases a state and collapses underscores, hyphens and
+// runs of spaces into single spaces
+func NormaliseState(value string) string {
+	value = strings.NewReplacer("_", " ", "-", " ").Replace(strings.ToLower(value))
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// LocalizedTerm is a mood term as offered to users, labelled in their language
+type LocalizedTerm struct {
+	Key     string  `json:"key"`
+	Label   string  `json:"label"`
+	Valence float64 `json:"valence"`
+	Arousal float64 `json:"arousal"`
+}
+
+// LocalizedVocabulary lists the states users can choose from
+type LocalizedVocabulary struct {
+	Locale    string          `json:"locale"`
+	Emotional []LocalizedTerm `json:"emotional"`
+	Spiritual []LocalizedTerm `json:"spiritual"`
+}
+
+// Localize labels terms for a locale, grouped by kind in their given order
+func Localize(terms []MoodTerm, locale string) *LocalizedVocabulary {
+	vocabulary := &LocalizedVocabulary{Locale: locale, Emotional: []LocalizedTerm{}, Spiritual: []LocalizedTerm{}}
+	for _, term := range terms {
+		localized := LocalizedTerm{Key: term.Key, Label: term.LabelFor(locale), Valence: term.Valence, Arousal: term.Arousal}
+		switch term.Kind {
+		case KindEmotional:
+			vocabulary.Emotional = append(vocabulary.Emotional, localized)
+		case KindSpiritual:
+			vocabulary.Spiritual = append(vocabulary.Spiritual, localized)
+		}
+	}
+	return vocabulary
+}
+
+// StateMapping is a free-text state on existing entries and the term key it
+// maps to, empty when nothing in the vocabulary matches
+type StateMapping struct {
+	Kind    string `json:"kind"`
+	From    string `json:"from"`
+	To      string `json:"to,omitempty"`
+	Entries int    `json:"entries"`
+}
+
+// MappingReport describes how existing entries were, or in a dry run would
+// be, rewritten to use term keys
+type MappingReport struct {
+	DryRun   bool           `json:"dry_run"`
+	Mapped   []StateMapping `json:"mapped"`
+	Unmapped []StateMapping `json:"unmapped"`
+}
+
+// PlanMapping works out which of a kind's existing states, counted by
+// value, map to a different term key and which match nothing. States that
+// are already keys are left out.
+func PlanMapping(vocabulary *Vocabulary, kind string, counts map[string]int) (mapped, unmapped []StateMapping) {
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		mapping := StateMapping{Kind: kind, From: value, Entries: counts[value]}
+		term, ok := vocabulary.Resolve(kind, value)
+		switch {
+		case !ok:
+			unmapped = append(unmapped, mapping)
+		case term.Key != value:
+			mapping.To = term.Key
+			mapped = append(mapped, mapping)
+		}
+	}
+	return mapped, unmapped
+}
@@ -1,21 +1,40 @@
 package openai
 
 import (
+	"context"
+	"math"
 	"net/http"
+	"strconv"
+
+	"armourup/internal/domain/encouragement"
+	"armourup/internal/domain/safety"
+	"armourup/internal/domain/usage"
+	"armourup/internal/llm"
+	"armourup/internal/scripture"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Controller struct {
 	service *Service
+	library *encouragement.Service
+	safety  *safety.Service
 }
 
-func NewController(service *Service) *Controller {
-	return &Controller{service: service}
+// NewController creates the AI controller. The service may be nil when OpenAI
+// is not configured, in which case encouragements are drawn from the library,
+// as they are when the AI is unavailable.
+// Input is checked by the safety service, which may also be nil.
+func NewController(service *Service, library *encouragement.Service, safetyService *safety.Service) *Controller {
+	return &Controller{service: service, library: library, safety: safetyService}
 }
 
+// crisisVerse accompanies crisis resources in place of a generated verse
+var crisisVerse = scripture.FormatVerse("Psalm 34:18", "The Lord is close to the brokenhearted and saves those who are crushed in spirit.")
+
 type GetEncouragementRequest struct {
-	Input string `json:"input" binding:"required"`
+	Input    string `json:"input" binding:"required"`
+	Category string `json:"category"` // Library category to draw from if AI is unavailable
 }
 
 func (c *Controller) GetEncouragement(ctx *gin.Context) {
@@ -25,11 +44,164 @@ func (c *Controller) GetEncouragement(ctx *gin.Context) {
 		return
 	}
 
-	response, err := c.service.GetEncouragement(ctx.Request.Context(), req.Input)
+	check := c.safety.CheckRequest(ctx, safety.SourceAIEncourage, req.Input)
+	if check.ShortCircuit {
+		ctx.JSON(http.StatusOK, crisisResponse(check.Notice))
+		return
+	}
+
+	if c.service == nil {
+		c.respondFromLibrary(ctx, req, check.Notice, nil)
+		return
+	}
+
+	response, err := c.service.GetEncouragement(c.callerContext(ctx), req.Input)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if usage.RespondIfQuotaExceeded(ctx, err) {
+			return
+		}
+		c.respondFromLibrary(ctx, req, check.Notice, err)
 		return
 	}
+	response.Safety = check.Notice
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+// StreamEncouragement streams an AI encouragement as Server-Sent Events.
+// "delta" events carry pieces of the response as they are generated and a
+// final "done" event carries the parsed verse and message. An "error" event is
+// sent if generation fails part way. If the AI can't start, a library
+// encouragement is sent as the "done" event instead. High-risk input skips
+// generation and the "done" event carries crisis resources.
+func (c *Controller) StreamEncouragement(ctx *gin.Context) {
+	var req GetEncouragementRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	check := c.safety.CheckRequest(ctx, safety.SourceAIEncourage, req.Input)
+	if check.ShortCircuit {
+		sendEvent(ctx, "done", crisisResponse(check.Notice))
+		return
+	}
+
+	if c.service == nil {
+		c.streamFromLibrary(ctx, req, check.Notice)
+		return
+	}
+
+	started := false
+	requestCtx := c.callerContext(ctx)
+	response, err := c.service.StreamEncouragement(requestCtx, req.Input, func(delta string) error {
+		started = true
+		sendEvent(ctx, "delta", gin.H{"content": delta})
+		return nil
+	})
+	if err != nil {
+		if requestCtx.Err() != nil {
+			// The client has gone away; there is no one left to tell
+			return
+		}
+		if !started {
+			if usage.RespondIfQuotaExceeded(ctx, err) {
+				return
+			}
+			c.streamFromLibrary(ctx, req, check.Notice)
+			return
+		}
+		sendEvent(ctx, "error", gin.H{"error": err.Error()})
+		return
+	}
+	response.Safety = check.Notice
+
+	sendEvent(ctx, "done", response)
+}
+
+// sendEvent writes a Server-Sent Event and flushes it. The stream headers are
+// set with the first event, so errors before it are still sent as JSON.
+func sendEvent(ctx *gin.Context, event string, data interface{}) {
+	if !ctx.Writer.Written() {
+		ctx.Header("Content-Type", "text/event-stream")
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		ctx.Header("X-Accel-Buffering", "no") // Stop proxies such as nginx buffering the stream
+	}
+	ctx.SSEvent(event, data)
+	ctx.Writer.Flush()
+}
+
+// callerContext attributes AI usage in the request to the authenticated user
+func (c *Controller) callerContext(ctx *gin.Context) context.Context {
+	var userID uint
+	if id, exists := ctx.Get("user_id"); exists {
+		userID = id.(uint)
+	}
+	return llm.WithCaller(ctx.Request.Context(), userID, usage.FeatureEncouragement)
+}
+
+// streamFromLibrary sends a library encouragement as the final event of a stream
+func (c *Controller) streamFromLibrary(ctx *gin.Context, req GetEncouragementRequest, notice *safety.Notice) {
+	if response, ok := c.fromLibrary(req, notice); ok {
+		sendEvent(ctx, "done", response)
+		return
+	}
+
+	sendEvent(ctx, "error", gin.H{"error": "encouragement is currently unavailable"})
+}
+
+// respondFromLibrary answers with a library encouragement when the AI can't.
+// If the library can't help either, the original AI error is reported.
+func (c *Controller) respondFromLibrary(ctx *gin.Context, req GetEncouragementRequest, notice *safety.Notice, aiErr error) {
+	if response, ok := c.fromLibrary(req, notice); ok {
+		ctx.JSON(http.StatusOK, response)
+		return
+	}
+
+	if aiErr == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "encouragement is currently unavailable"})
+		return
+	}
+	if llm.IsUnavailable(aiErr) {
+		if retryAfter := llm.RetryAfter(aiErr); retryAfter > 0 {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "encouragement is currently unavailable"})
+		return
+	}
+	if llm.IsSchemaError(aiErr) {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "AI returned an invalid response, please try again"})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": aiErr.Error()})
+}
+
+// fromLibrary picks the library encouragement best suited to the struggle,
+// marked as not generated by AI
+func (c *Controller) fromLibrary(req GetEncouragementRequest, notice *safety.Notice) (AIResponse, bool) {
+	if c.library == nil {
+		return AIResponse{}, false
+	}
+	enc, err := c.library.Match(req.Input, req.Category)
+	if err != nil {
+		return AIResponse{}, false
+	}
+	return AIResponse{
+		Verse:   scripture.FormatVerse(enc.Reference, enc.Verse),
+		Message: enc.Message,
+		Safety:  notice,
+		Source:  SourceLibrary,
+	}, true
+}
+
+// crisisResponse answers high-risk input with crisis resources instead of an
+// AI encouragement
+func crisisResponse(notice *safety.Notice) AIResponse {
+	return AIResponse{
+		Verse:   crisisVerse,
+		Message: notice.Message,
+		Safety:  notice,
+		Source:  SourceCrisis,
+	}
+}
@@ -3,89 +3,238 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
-	"armourup/internal/config"
-
-	"github.com/sashabaranov/go-openai"
+	"armourup/internal/domain/aicache"
+	"armourup/internal/domain/privacy"
+	"armourup/internal/domain/safety"
+	"armourup/internal/domain/usage"
+	"armourup/internal/llm"
+	"armourup/internal/prompts"
 )
 
 type Service struct {
-	client *openai.Client
+	provider llm.Provider
+	prompts  *prompts.Registry
+	cache    *aicache.Service
+	privacy  *privacy.Service
 }
 
 type AIResponse struct {
-	Verse   string `json:"verse"`
-	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
+	Verse         string         `json:"verse"`
+	Message       string         `json:"message"`
+	Error         string         `json:"error,omitempty"`
+	Safety        *safety.Notice `json:"safety,omitempty"`         // Crisis resources when the input was flagged
+	PromptVersion string         `json:"prompt_version,omitempty"` // Prompt template that generated the response
+	Cached        bool           `json:"cached,omitempty"`         // Served from the response cache
+	Source        string         `json:"source"`                   // Where the encouragement came from, see the Source constants
 }
 
-func NewService() (*Service, error) {
-	apiKey := config.GetOpenAIAPIKey()
-	if apiKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
-	}
+// Sources of an encouragement. Only SourceAI is generated by AI.
+const (
+	SourceAI      = "ai"
+	SourceLibrary = "library"
+	SourceCrisis  = "crisis"
+)
 
-	client := openai.NewClient(apiKey)
-	return &Service{client: client}, nil
+// NewService creates the AI encouragement service. The cache may be nil to
+// always generate a fresh response. Input is redacted before it is cached or
+// sent, and audited by the privacy service, which may also be nil.
+func NewService(provider llm.Provider, registry *prompts.Registry, cache *aicache.Service, privacyService *privacy.Service) *Service {
+	return &Service{provider: provider, prompts: registry, cache: cache, privacy: privacyService}
 }
 
-// GetClient returns the OpenAI client for use in other services
-func (s *Service) GetClient() *openai.Client {
-	return s.client
+// request renders the version of a prompt assigned to the caller, with the
+// input already redacted
+func (s *Service) request(ctx context.Context, name, userInput string) (*prompts.Version, llm.Request, error) {
+	version, err := s.prompts.Select(name, llm.CallerFrom(ctx).UserID)
+	if err != nil {
+		return nil, llm.Request{}, err
+	}
+	req, err := version.Request(promptData{Input: userInput})
+	return version, req, err
 }
 
-func (s *Service) buildPrompt(userInput string) string {
-	return fmt.Sprintf(`Based on the following situation or feeling, provide a relevant Bible verse and a brief message of encouragement:
-	
-Situation: %s
+// EncouragementSchema is the structure an AI encouragement must have
+var EncouragementSchema = llm.Schema{
+	Name: "encouragement",
+	Fields: []llm.Field{
+		{Name: "verse", Type: llm.TypeString, Required: true, MaxLength: 500},
+		{Name: "message", Type: llm.TypeString, Required: true, MaxLength: 1500},
+	},
+}
 
-Please respond in JSON format with the following structure:
-{
-	"verse": "Bible verse with reference",
-	"message": "Brief message of encouragement"
-}`, userInput)
+// promptData is what the encouragement prompts are rendered with
+type promptData struct {
+	Input string
 }
 
 func (s *Service) GetEncouragement(ctx context.Context, userInput string) (*AIResponse, error) {
-	prompt := s.buildPrompt(userInput)
-
-	// Retry logic with exponential backoff
-	maxRetries := 3
-	baseDelay := time.Second
-
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		resp, err := s.client.CreateChatCompletion(
-			ctx,
-			openai.ChatCompletionRequest{
-				Model: openai.GPT3Dot5Turbo,
-				Messages: []openai.ChatCompletionMessage{
-					{
-						Role:    openai.ChatMessageRoleUser,
-						Content: prompt,
-					},
-				},
-			},
-		)
+	userID := llm.CallerFrom(ctx).UserID
+	disclosure := s.privacy.Begin(userID)
+	userInput = disclosure.Text(userInput)
+
+	version, req, err := s.request(ctx, "encouragement", userInput)
+	if err != nil {
+		return nil, err
+	}
+
+	// Looking for a similar cached input can send it to the embedding
+	// provider, so even a cache hit is audited
+	defer s.privacy.Audit(userID, usage.FeatureEncouragement, disclosure)
+
+	entry, miss := s.cache.Lookup(ctx, userID, version.ID(), userInput)
+	if entry != nil {
+		return &AIResponse{
+			Verse:         entry.Verse,
+			Message:       entry.Message,
+			PromptVersion: version.ID(),
+			Cached:        true,
+			Source:        SourceAI,
+		}, nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		var parsed struct {
+			Verse   string `json:"verse"`
+			Message string `json:"message"`
+		}
+		_, err := llm.ChatJSON(ctx, s.provider, req, EncouragementSchema, &parsed)
 
 		if err == nil {
-			var aiResponse AIResponse
-			if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &aiResponse); err != nil {
-				return nil, fmt.Errorf("failed to parse AI response: %v", err)
+			response := &AIResponse{
+				Verse:         strings.TrimSpace(parsed.Verse),
+				Message:       strings.TrimSpace(parsed.Message),
+				PromptVersion: version.ID(),
+				Source:        SourceAI,
 			}
-			return &aiResponse, nil
+			s.cache.Store(miss, response.Verse, response.Message)
+			return response, nil
 		}
 
-		// If we've exhausted all retries, return the error
-		if attempt == maxRetries-1 {
-			return nil, fmt.Errorf("failed after %d attempts: %v", maxRetries, err)
+		// Only outages are worth retrying: a rejected request, a used up
+		// quota or an invalid response (which has already had its repair
+		// attempt) would fail again. Nor is there any point waiting while
+		// the circuit breaker is open.
+		if !llm.IsUnavailable(err) || errors.Is(err, llm.ErrCircuitOpen) {
+			return nil, err
+		}
+		if attempt == maxAttempts-1 {
+			return nil, fmt.Errorf("failed after %d attempts: %w", maxAttempts, err)
 		}
 
-		// Calculate delay with exponential backoff
-		delay := baseDelay * time.Duration(1<<uint(attempt))
-		time.Sleep(delay)
+		// Back off exponentially, or for as long as the provider asked
+		delay := baseRetryDelay * time.Duration(1<<uint(attempt))
+		if retryAfter := llm.RetryAfter(err); retryAfter > delay {
+			delay = retryAfter
+		}
+		if !wait(ctx, delay) {
+			return nil, err
+		}
 	}
+}
+
+// Retries of encouragements that fail because the provider is unavailable
+const (
+	maxAttempts    = 3
+	baseRetryDelay = time.Second
+)
+
+// wait sleeps for delay, unless ctx ends first or its deadline would pass
+// before the delay is up, and reports whether it is worth trying again
+func wait(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
 
-	return nil, fmt.Errorf("unexpected error in retry logic")
+// StreamEncouragement generates an encouragement, passing each piece of the
+// response to onDelta as it arrives, and returns the parsed result once the
+// response is complete. Cancelling ctx (e.g. the client disconnecting) stops
+// generation and returns the context's error.
+func (s *Service) StreamEncouragement(ctx context.Context, userInput string, onDelta func(string) error) (*AIResponse, error) {
+	userID := llm.CallerFrom(ctx).UserID
+	disclosure := s.privacy.Begin(userID)
+	userInput = disclosure.Text(userInput)
+
+	version, req, err := s.request(ctx, "encouragement_stream", userInput)
+	if err != nil {
+		return nil, err
+	}
+	defer s.privacy.Audit(userID, usage.FeatureEncouragement, disclosure)
+
+	stream, err := s.provider.ChatStream(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start AI stream: %w", err)
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	for {
+		delta, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("AI stream failed: %v", err)
+		}
+
+		content.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := ParseStreamedEncouragement(content.String())
+	if err != nil {
+		return nil, err
+	}
+	response.PromptVersion = version.ID()
+	response.Source = SourceAI
+	return response, nil
+}
+
+// ParseStreamedEncouragement splits a streamed response into its message and
+// the verse on the final "Verse:" line. A response that came back as JSON
+// despite the prompt is accepted too.
+func ParseStreamedEncouragement(content string) (*AIResponse, error) {
+	content = strings.TrimSpace(content)
+
+	var jsonResponse AIResponse
+	if strings.HasPrefix(content, "{") && json.Unmarshal([]byte(content), &jsonResponse) == nil && jsonResponse.Message != "" {
+		return &jsonResponse, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	response := &AIResponse{}
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if len(line) >= len("verse:") && strings.EqualFold(line[:len("verse:")], "verse:") {
+			response.Verse = strings.TrimSpace(line[len("verse:"):])
+			lines = lines[:i]
+			break
+		}
+	}
+	response.Message = strings.TrimSpace(strings.Join(lines, "\n"))
+
+	if response.Message == "" {
+		return nil, fmt.Errorf("failed to parse AI response: no message in %q", content)
+	}
+	return response, nil
 }
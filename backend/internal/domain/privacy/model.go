@@ -0,0 +1,56 @@
+package privacy
+
+import (
+	"time"
+)
+
+// How much of a user's data AI features may read
+const (
+	// SharingNone keeps stored data away from AI entirely. Text the user
+	// writes to an AI feature directly is still sent, redacted.
+	SharingNone = "none"
+	// SharingAggregates allows counts, averages and mood states, but no
+	// journal, mood note or other free text
+	SharingAggregates = "aggregates"
+	// SharingFull allows free text too, redacted
+	SharingFull = "full_text"
+)
+
+// ValidSharing reports whether level is a known sharing level
+func ValidSharing(level string) bool {
+	return level == SharingNone || level == SharingAggregates || level == SharingFull
+}
+
+// Settings are a user's AI data-sharing choices
+type Settings struct {
+	UserID    uint      `json:"-" gorm:"primaryKey"`
+	Sharing   string    `json:"sharing" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName matches the ai_privacy_settings table created by the migrations
+func (Settings) TableName() string {
+	return "ai_privacy_settings"
+}
+
+// SettingsRequest represents the request body for updating the settings
+type SettingsRequest struct {
+	Sharing string `json:"sharing" binding:"required"`
+}
+
+// AuditRecord notes which of a user's records were included in an AI
+// request, and how much was redacted from them
+type AuditRecord struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	Feature    string    `json:"feature"`
+	Sharing    string    `json:"sharing"`
+	Records    string    `json:"records"` // Comma-separated, e.g. "journal_entry:12,mood_entry:5"
+	Redactions int       `json:"redactions"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName matches the ai_data_audit_logs table created by the migrations
+func (AuditRecord) TableName() string {
+	return "ai_data_audit_logs"
+}
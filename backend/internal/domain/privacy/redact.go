@@ -0,0 +1,77 @@
+package privacy
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholders that replace redacted personal information
+const (
+	RedactedEmail   = "[EMAIL]"
+	RedactedPhone   = "[PHONE]"
+	RedactedAddress = "[ADDRESS]"
+	RedactedName    = "[NAME]"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// Runs of 9 or more digits with the usual separators, e.g.
+	// "+44 7700 900123" or "(555) 123-4567"
+	phonePattern = regexp.MustCompile(`\+?\(?\d[\d\s().-]{7,}\d`)
+	// A house number and street, e.g. "42 Station Road", or a UK postcode
+	addressPattern = regexp.MustCompile(`\b\d{1,5}\s+(?:[A-Z][a-z]+\s+){1,3}(?:Street|St|Avenue|Ave|Road|Rd|Lane|Ln|Drive|Dr|Boulevard|Blvd|Close|Court|Ct|Crescent|Way|Place|Pl|Terrace)\b\.?|\b[A-Z]{1,2}\d[A-Z\d]?\s*\d[A-Z]{2}\b`)
+	// A capitalised name introduced by a relation or "named", e.g.
+	// "my sister Anna" or "a friend called Tom Smith"
+	namePattern = regexp.MustCompile(`\b((?:[Mm]y|[Oo]ur|[Aa]|[Hh]er|[Hh]is)\s+(?:[a-z]+\s+)?(?:wife|husband|son|daughter|mum|mom|mother|dad|father|brother|sister|friend|boss|colleague|pastor|neighbour|neighbor|partner|boyfriend|girlfriend|fiancée?|aunt|uncle|cousin|grandma|grandpa|grandmother|grandfather|child|kid|teacher|manager|coworker)|named|called|(?:[Mm]r|[Mm]rs|[Mm]s|[Dd]r)\.?)\s+([A-Z][a-z]+(?:\s+[A-Z][a-z]+)?)`)
+)
+
+// nameExceptions are capitalised words that follow the name cues above
+// without being names
+var nameExceptions = map[string]bool{
+	"God": true, "Jesus": true, "Christ": true, "Lord": true, "Father": true, "Holy": true, "Spirit": true,
+	"I": true, "Monday": true, "Tuesday": true, "Wednesday": true, "Thursday": true, "Friday": true,
+	"Saturday": true, "Sunday": true, "Christmas": true, "Easter": true,
+}
+
+// Redact replaces email addresses, phone numbers, street addresses and
+// names it can recognise with placeholders, and returns how many it
+// replaced. Names are only recognised after cues such as "my friend", so
+// redaction is best effort.
+func Redact(text string) (string, int) {
+	count := 0
+	replace := func(pattern *regexp.Regexp, placeholder string) {
+		text = pattern.ReplaceAllStringFunc(text, func(string) string {
+			count++
+			return placeholder
+		})
+	}
+	replace(emailPattern, RedactedEmail)
+	replace(addressPattern, RedactedAddress)
+	text = phonePattern.ReplaceAllStringFunc(text, func(match string) string {
+		if digits(match) < 9 {
+			return match
+		}
+		count++
+		return RedactedPhone
+	})
+	text = namePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := namePattern.FindStringSubmatch(match)
+		name := parts[2]
+		if nameExceptions[strings.Fields(name)[0]] {
+			return match
+		}
+		count++
+		return strings.TrimSuffix(match, name) + RedactedName
+	})
+	return text, count
+}
+
+func digits(text string) int {
+	n := 0
+	for _, r := range text {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package privacy
+
+import (
+	"gorm.io/gorm"
+)
+
+// defaultAuditLimit caps how many audit records a user's listing returns
+const defaultAuditLimit = 100
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// GetSettings retrieves a user's settings, if they have saved any
+func (r *Repository) GetSettings(userID uint) (*Settings, error) {
+	var settings Settings
+	err := r.db.Where("user_id = ?", userID).First(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SaveSettings creates or updates a user's settings
+func (r *Repository) SaveSettings(settings *Settings) error {
+	return r.db.Save(settings).Error
+}
+
+// CreateAuditRecord records the data included in an AI request
+func (r *Repository) CreateAuditRecord(record *AuditRecord) error {
+	return r.db.Create(record).Error
+}
+
+// GetAuditRecords retrieves a user's most recent audit records
+func (r *Repository) GetAuditRecords(userID uint) ([]AuditRecord, error) {
+	var records []AuditRecord
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(defaultAuditLimit).
+		Find(&records).Error
+	return records, err
+}
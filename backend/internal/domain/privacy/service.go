@@ -0,0 +1,117 @@
+package privacy
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type Service struct {
+	repo           *Repository
+	defaultSharing string
+}
+
+// NewService creates the privacy service. Users who haven't chosen a
+// sharing level get defaultSharing, or aggregates only if it isn't valid.
+func NewService(repo *Repository, defaultSharing string) *Service {
+	if !ValidSharing(defaultSharing) {
+		defaultSharing = SharingAggregates
+	}
+	return &Service{repo: repo, defaultSharing: defaultSharing}
+}
+
+// GetSettings retrieves the user's settings, or the defaults if they haven't
+// chosen
+func (s *Service) GetSettings(userID uint) (*Settings, error) {
+	settings, err := s.repo.GetSettings(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &Settings{UserID: userID, Sharing: s.defaultSharing}, nil
+	}
+	return settings, err
+}
+
+// UpdateSettings saves the user's sharing level
+func (s *Service) UpdateSettings(userID uint, req SettingsRequest) (*Settings, error) {
+	if !ValidSharing(req.Sharing) {
+		return nil, errors.New("sharing must be none, aggregates or full_text")
+	}
+
+	settings := &Settings{UserID: userID, Sharing: req.Sharing}
+	if err := s.repo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// GetAuditLog retrieves the record of what the user's AI requests included
+func (s *Service) GetAuditLog(userID uint) ([]AuditRecord, error) {
+	return s.repo.GetAuditRecords(userID)
+}
+
+// Disclosure tracks what of a user's data goes into an AI request. Text
+// passes through it to be redacted, and records are noted as they are
+// included, so that the request can be audited once it is sent.
+type Disclosure struct {
+	Sharing    string
+	records    []string
+	redactions int
+}
+
+// AllowsAggregates reports whether counts and states may be shared
+func (d *Disclosure) AllowsAggregates() bool {
+	return d.Sharing == SharingAggregates || d.Sharing == SharingFull
+}
+
+// AllowsText reports whether free text from stored records may be shared
+func (d *Disclosure) AllowsText() bool {
+	return d.Sharing == SharingFull
+}
+
+// Include notes that a record is part of the request
+func (d *Disclosure) Include(recordType string, id uint) {
+	d.records = append(d.records, fmt.Sprintf("%s:%d", recordType, id))
+}
+
+// Text redacts text before it is added to the request
+func (d *Disclosure) Text(text string) string {
+	redacted, count := Redact(text)
+	d.redactions += count
+	return redacted
+}
+
+// Begin starts tracking an AI request for a user, at their sharing level.
+// If the settings can't be read nothing stored is shared. A nil service,
+// as used when privacy controls aren't configured, shares full text,
+// still redacted.
+func (s *Service) Begin(userID uint) *Disclosure {
+	if s == nil {
+		return &Disclosure{Sharing: SharingFull}
+	}
+	settings, err := s.GetSettings(userID)
+	if err != nil {
+		log.Printf("Warning: failed to read AI privacy settings: %v", err)
+		return &Disclosure{Sharing: SharingNone}
+	}
+	return &Disclosure{Sharing: settings.Sharing}
+}
+
+// Audit records what a request for a feature included. Failing to record
+// is only logged, as the request has already been sent.
+func (s *Service) Audit(userID uint, feature string, d *Disclosure) {
+	if s == nil || d == nil || userID == 0 {
+		return
+	}
+	record := &AuditRecord{
+		UserID:     userID,
+		Feature:    feature,
+		Sharing:    d.Sharing,
+		Records:    strings.Join(d.records, ","),
+		Redactions: d.redactions,
+	}
+	if err := s.repo.CreateAuditRecord(record); err != nil {
+		log.Printf("Warning: failed to record AI data audit: %v", err)
+	}
+}
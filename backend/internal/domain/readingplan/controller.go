@@ -0,0 +1,237 @@
+package readingplan
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service *Service
+}
+
+func NewController(service *Service) *Controller {
+	return &Controller{service: service}
+}
+
+// GetPlans lists all available reading plans
+func (c *Controller) GetPlans(ctx *gin.Context) {
+	plans, err := c.service.GetPlans()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, plans)
+}
+
+// GetPlan retrieves a reading plan with all of its days
+func (c *Controller) GetPlan(ctx *gin.Context) {
+	planID, ok := parsePlanID(ctx)
+	if !ok {
+		return
+	}
+
+	plan, err := c.service.GetPlan(planID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, plan)
+}
+
+// GetMyPlans lists the plans the authenticated user is enrolled in
+func (c *Controller) GetMyPlans(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	enrollments, err := c.service.GetUserEnrollments(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollments)
+}
+
+// Enroll signs the authenticated user up for a reading plan
+func (c *Controller) Enroll(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	planID, ok := parsePlanID(ctx)
+	if !ok {
+		return
+	}
+
+	var req EnrollRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	enrollment, err := c.service.Enroll(userID.(uint), planID, req)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, enrollment)
+}
+
+// Unenroll removes the authenticated user from a reading plan
+func (c *Controller) Unenroll(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	planID, ok := parsePlanID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.Unenroll(userID.(uint), planID); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetTodayReading returns today's passages for the authenticated user
+func (c *Controller) GetTodayReading(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	planID, ok := parsePlanID(ctx)
+	if !ok {
+		return
+	}
+
+	reading, err := c.service.GetTodayReading(userID.(uint), planID)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reading)
+}
+
+// CompleteReading records a finished reading for the authenticated user
+func (c *Controller) CompleteReading(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	planID, ok := parsePlanID(ctx)
+	if !ok {
+		return
+	}
+
+	var req CompleteReadingRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	progress, err := c.service.CompleteReading(userID.(uint), planID, req)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, progress)
+}
+
+// GetProgress returns the authenticated user's progress through a plan
+func (c *Controller) GetProgress(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	planID, ok := parsePlanID(ctx)
+	if !ok {
+		return
+	}
+
+	progress, err := c.service.GetProgress(userID.(uint), planID)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, progress)
+}
+
+// GetCatchUpSchedule returns a schedule for catching up on missed readings
+func (c *Controller) GetCatchUpSchedule(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	planID, ok := parsePlanID(ctx)
+	if !ok {
+		return
+	}
+
+	daysStr := ctx.DefaultQuery("days", strconv.Itoa(defaultCatchUpDays))
+	days, err := strconv.Atoi(daysStr)
+	if err != nil || days < 1 {
+		days = defaultCatchUpDays
+	}
+
+	schedule, err := c.service.GetCatchUpSchedule(userID.(uint), planID, days)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+// handleError maps service errors to HTTP responses
+func (c *Controller) handleError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case "reading plan not found", "not enrolled in this reading plan":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "already enrolled in this reading plan", "reading already completed":
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "invalid date format, use YYYY-MM-DD", "day number is outside this reading plan", "reading plan has not started yet":
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// parsePlanID reads the plan ID from the route, writing a 400 response if it's invalid
+func parsePlanID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
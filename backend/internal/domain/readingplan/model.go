@@ -0,0 +1,111 @@
+package readingplan
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReadingPlan represents a structured Bible reading plan such as "Psalms in 30 Days"
+type ReadingPlan struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	Slug        string           `json:"slug" gorm:"uniqueIndex;not null"`
+	Title       string           `json:"title" gorm:"not null"`
+	Description string           `json:"description"`
+	TotalDays   int              `json:"total_days" gorm:"not null"`
+	Days        []ReadingPlanDay `json:"days,omitempty" gorm:"foreignKey:PlanID"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt   `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// ReadingPlanDay holds the passages assigned to a single day of a plan
+type ReadingPlanDay struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	PlanID    uint   `json:"plan_id" gorm:"not null;uniqueIndex:idx_reading_plan_days_plan_day"`
+	DayNumber int    `json:"day_number" gorm:"not null;uniqueIndex:idx_reading_plan_days_plan_day"`
+	Passages  string `json:"passages" gorm:"not null"` // Semicolon-separated, e.g. "Psalms 1-5; Proverbs 1"
+}
+
+// Enrollment tracks a user's participation in a reading plan
+type Enrollment struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_reading_plan_enrollments_user_plan"`
+	PlanID      uint           `json:"plan_id" gorm:"not null;uniqueIndex:idx_reading_plan_enrollments_user_plan"`
+	StartDate   time.Time      `json:"start_date" gorm:"type:date;not null"`
+	Status      string         `json:"status" gorm:"default:active"` // active, completed
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	Plan        *ReadingPlan   `json:"plan,omitempty" gorm:"foreignKey:PlanID"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName keeps enrollments grouped with the other reading plan tables
+func (Enrollment) TableName() string {
+	return "reading_plan_enrollments"
+}
+
+// ReadingProgress records a completed day of a reading plan
+type ReadingProgress struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	EnrollmentID uint      `json:"enrollment_id" gorm:"not null;uniqueIndex:idx_reading_progress_enrollment_day"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	PlanID       uint      `json:"plan_id" gorm:"not null"`
+	DayNumber    int       `json:"day_number" gorm:"not null;uniqueIndex:idx_reading_progress_enrollment_day"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+// TableName matches the reading_progress table created by the migrations
+func (ReadingProgress) TableName() string {
+	return "reading_progress"
+}
+
+// EnrollRequest represents the request to enrol in a reading plan
+type EnrollRequest struct {
+	StartDate string `json:"start_date"` // Optional, defaults to today (YYYY-MM-DD)
+}
+
+// CompleteReadingRequest represents the request to mark a day's reading as done
+type CompleteReadingRequest struct {
+	DayNumber int `json:"day_number" binding:"omitempty,min=1"` // Optional, defaults to today's reading
+}
+
+// TodayReading is the response for a user's reading on the current day
+type TodayReading struct {
+	PlanID        uint      `json:"plan_id"`
+	PlanTitle     string    `json:"plan_title"`
+	DayNumber     int       `json:"day_number"`
+	Date          time.Time `json:"date"`
+	Passages      []string  `json:"passages"`
+	Completed     bool      `json:"completed"`
+	CompletedDays int       `json:"completed_days"`
+	TotalDays     int       `json:"total_days"`
+	DaysBehind    int       `json:"days_behind"`
+}
+
+// PlanProgress summarises how far a user has read through a plan
+type PlanProgress struct {
+	Enrollment    *Enrollment `json:"enrollment"`
+	CompletedDays []int       `json:"completed_days"`
+	MissedDays    []int       `json:"missed_days"`
+	CurrentDay    int         `json:"current_day"`
+	PercentDone   float64     `json:"percent_done"`
+	DaysBehind    int         `json:"days_behind"`
+	ProjectedEnd  time.Time   `json:"projected_end"`
+}
+
+// CatchUpDay is one day of a generated catch-up schedule
+type CatchUpDay struct {
+	Date       time.Time `json:"date"`
+	DayNumbers []int     `json:"day_numbers"`
+	Passages   []string  `json:"passages"`
+}
+
+// CatchUpSchedule spreads missed readings over the coming days
+type CatchUpSchedule struct {
+	PlanID     uint         `json:"plan_id"`
+	DaysBehind int          `json:"days_behind"`
+	SpreadDays int          `json:"spread_days"`
+	Schedule   []CatchUpDay `json:"schedule"`
+}
@@ -0,0 +1,99 @@
+package readingplan
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:embed plans.json
+var builtinPlansJSON []byte
+
+// PlanDefinition describes a reading plan as data: a set of books read in
+// order and spread evenly over a number of days
+type PlanDefinition struct {
+	Slug        string           `json:"slug"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Days        int              `json:"days"`
+	Books       []BookDefinition `json:"books"`
+}
+
+// BookDefinition is a book of the Bible and its chapter count
+type BookDefinition struct {
+	Name     string `json:"name"`
+	Chapters int    `json:"chapters"`
+}
+
+// LoadBuiltinPlans parses the plan definitions shipped with the service
+func LoadBuiltinPlans() ([]PlanDefinition, error) {
+	var defs []PlanDefinition
+	if err := json.Unmarshal(builtinPlansJSON, &defs); err != nil {
+		return nil, fmt.Errorf("failed to parse built-in reading plans: %w", err)
+	}
+	return defs, nil
+}
+
+// ExpandDays distributes the definition's chapters across its days and returns
+// the passages for each day. Chapters are split as evenly as possible, and
+// consecutive chapters from the same book are collapsed into a range.
+func (d PlanDefinition) ExpandDays() ([]ReadingPlanDay, error) {
+	type chapterRef struct {
+		book    string
+		chapter int
+	}
+
+	var chapters []chapterRef
+	for _, book := range d.Books {
+		for c := 1; c <= book.Chapters; c++ {
+			chapters = append(chapters, chapterRef{book: book.Name, chapter: c})
+		}
+	}
+
+	if d.Days < 1 {
+		return nil, fmt.Errorf("reading plan %q must have at least one day", d.Slug)
+	}
+	if len(chapters) < d.Days {
+		return nil, fmt.Errorf("reading plan %q has fewer chapters than days", d.Slug)
+	}
+
+	days := make([]ReadingPlanDay, 0, d.Days)
+	for day := 0; day < d.Days; day++ {
+		start := day * len(chapters) / d.Days
+		end := (day + 1) * len(chapters) / d.Days
+
+		var passages []string
+		for i := start; i < end; {
+			j := i
+			for j+1 < end && chapters[j+1].book == chapters[i].book {
+				j++
+			}
+			if i == j {
+				passages = append(passages, fmt.Sprintf("%s %d", chapters[i].book, chapters[i].chapter))
+			} else {
+				passages = append(passages, fmt.Sprintf("%s %d-%d", chapters[i].book, chapters[i].chapter, chapters[j].chapter))
+			}
+			i = j + 1
+		}
+
+		days = append(days, ReadingPlanDay{
+			DayNumber: day + 1,
+			Passages:  strings.Join(passages, "; "),
+		})
+	}
+
+	return days, nil
+}
+
+// splitPassages turns a stored passage list back into individual references
+func splitPassages(passages string) []string {
+	parts := strings.Split(passages, ";")
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
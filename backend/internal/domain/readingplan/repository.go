@@ -0,0 +1,140 @@
+package readingplan
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// UpsertPlan creates a plan or refreshes an existing one with the same slug,
+// replacing its days
+func (r *Repository) UpsertPlan(plan *ReadingPlan, days []ReadingPlanDay) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing ReadingPlan
+		err := tx.Where("slug = ?", plan.Slug).First(&existing).Error
+		switch {
+		case err == nil:
+			plan.ID = existing.ID
+			plan.CreatedAt = existing.CreatedAt
+			if err := tx.Save(plan).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(plan).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		for i := range days {
+			days[i].PlanID = plan.ID
+		}
+		if err := tx.Where("plan_id = ? AND day_number > ?", plan.ID, len(days)).Delete(&ReadingPlanDay{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "plan_id"}, {Name: "day_number"}},
+			DoUpdates: clause.AssignmentColumns([]string{"passages"}),
+		}).CreateInBatches(days, 100).Error
+	})
+}
+
+// GetAllPlans retrieves every available reading plan without its days
+func (r *Repository) GetAllPlans() ([]ReadingPlan, error) {
+	var plans []ReadingPlan
+	err := r.db.Order("title ASC").Find(&plans).Error
+	return plans, err
+}
+
+// GetPlanByID retrieves a reading plan with all of its days
+func (r *Repository) GetPlanByID(id uint) (*ReadingPlan, error) {
+	var plan ReadingPlan
+	err := r.db.Preload("Days", func(db *gorm.DB) *gorm.DB {
+		return db.Order("day_number ASC")
+	}).First(&plan, id).Error
+	return &plan, err
+}
+
+// GetPlanDay retrieves the passages for a single day of a plan
+func (r *Repository) GetPlanDay(planID uint, dayNumber int) (*ReadingPlanDay, error) {
+	var day ReadingPlanDay
+	err := r.db.Where("plan_id = ? AND day_number = ?", planID, dayNumber).First(&day).Error
+	return &day, err
+}
+
+// GetPlanDays retrieves the passages for the given days of a plan
+func (r *Repository) GetPlanDays(planID uint, dayNumbers []int) ([]ReadingPlanDay, error) {
+	var days []ReadingPlanDay
+	if len(dayNumbers) == 0 {
+		return days, nil
+	}
+	err := r.db.Where("plan_id = ? AND day_number IN ?", planID, dayNumbers).
+		Order("day_number ASC").
+		Find(&days).Error
+	return days, err
+}
+
+// CreateEnrollment saves a new enrolment
+func (r *Repository) CreateEnrollment(enrollment *Enrollment) error {
+	return r.db.Create(enrollment).Error
+}
+
+// GetEnrollment retrieves a user's enrolment in a plan
+func (r *Repository) GetEnrollment(userID, planID uint) (*Enrollment, error) {
+	var enrollment Enrollment
+	err := r.db.Preload("Plan").
+		Where("user_id = ? AND plan_id = ?", userID, planID).
+		First(&enrollment).Error
+	return &enrollment, err
+}
+
+// GetEnrollmentsByUserID retrieves all of a user's enrolments with their plans
+func (r *Repository) GetEnrollmentsByUserID(userID uint) ([]Enrollment, error) {
+	var enrollments []Enrollment
+	err := r.db.Preload("Plan").
+		Where("user_id = ?", userID).
+		Order("start_date DESC").
+		Find(&enrollments).Error
+	return enrollments, err
+}
+
+// UpdateEnrollment updates an existing enrolment
+func (r *Repository) UpdateEnrollment(enrollment *Enrollment) error {
+	return r.db.Save(enrollment).Error
+}
+
+// DeleteEnrollment removes an enrolment together with its recorded progress.
+// The delete is permanent so that the user can enrol in the plan again.
+func (r *Repository) DeleteEnrollment(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("enrollment_id = ?", id).Delete(&ReadingProgress{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&Enrollment{}, id).Error
+	})
+}
+
+// CreateProgress records a completed reading
+func (r *Repository) CreateProgress(progress *ReadingProgress) error {
+	return r.db.Create(progress).Error
+}
+
+// GetCompletedDays returns the day numbers completed for an enrolment
+func (r *Repository) GetCompletedDays(enrollmentID uint) ([]int, error) {
+	var days []int
+	err := r.db.Model(&ReadingProgress{}).
+		Where("enrollment_id = ?", enrollmentID).
+		Order("day_number ASC").
+		Pluck("day_number", &days).Error
+	return days, err
+}
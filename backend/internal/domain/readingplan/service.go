@@ -0,0 +1,373 @@
+package readingplan
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultCatchUpDays is how many days missed readings are spread over when
+// the caller doesn't specify
+const defaultCatchUpDays = 7
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// SyncBuiltinPlans loads the plan definitions shipped with the service and
+// makes sure each one exists in the database with up-to-date passages
+func (s *Service) SyncBuiltinPlans() error {
+	defs, err := LoadBuiltinPlans()
+	if err != nil {
+		return err
+	}
+
+	for _, def := range defs {
+		days, err := def.ExpandDays()
+		if err != nil {
+			return err
+		}
+		plan := &ReadingPlan{
+			Slug:        def.Slug,
+			Title:       def.Title,
+			Description: def.Description,
+			TotalDays:   def.Days,
+		}
+		if err := s.repo.UpsertPlan(plan, days); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetPlans retrieves all available reading plans
+func (s *Service) GetPlans() ([]ReadingPlan, error) {
+	return s.repo.GetAllPlans()
+}
+
+// GetPlan retrieves a reading plan with its daily passages
+func (s *Service) GetPlan(id uint) (*ReadingPlan, error) {
+	plan, err := s.repo.GetPlanByID(id)
+	if err != nil {
+		return nil, errors.New("reading plan not found")
+	}
+	return plan, nil
+}
+
+// Enroll signs a user up for a reading plan starting on the requested date
+func (s *Service) Enroll(userID, planID uint, req EnrollRequest) (*Enrollment, error) {
+	plan, err := s.repo.GetPlanByID(planID)
+	if err != nil {
+		return nil, errors.New("reading plan not found")
+	}
+
+	startDate := today()
+	if req.StartDate != "" {
+		startDate, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		}
+	}
+
+	existing, err := s.repo.GetEnrollment(userID, planID)
+	if err == nil && existing.ID > 0 {
+		return nil, errors.New("already enrolled in this reading plan")
+	}
+
+	enrollment := &Enrollment{
+		UserID:    userID,
+		PlanID:    planID,
+		StartDate: startDate,
+		Status:    "active",
+	}
+	if err := s.repo.CreateEnrollment(enrollment); err != nil {
+		return nil, err
+	}
+	enrollment.Plan = plan
+
+	return enrollment, nil
+}
+
+// Unenroll removes a user from a reading plan and discards their progress
+func (s *Service) Unenroll(userID, planID uint) error {
+	enrollment, err := s.getEnrollment(userID, planID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteEnrollment(enrollment.ID)
+}
+
+// GetUserEnrollments retrieves every plan the user is enrolled in
+func (s *Service) GetUserEnrollments(userID uint) ([]Enrollment, error) {
+	return s.repo.GetEnrollmentsByUserID(userID)
+}
+
+// GetTodayReading returns the passages scheduled for today in the user's plan
+func (s *Service) GetTodayReading(userID, planID uint) (*TodayReading, error) {
+	enrollment, err := s.getEnrollment(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := today()
+	dayNumber := currentDay(enrollment.StartDate, now, enrollment.Plan.TotalDays)
+	if dayNumber == 0 {
+		return nil, errors.New("reading plan has not started yet")
+	}
+
+	day, err := s.repo.GetPlanDay(planID, dayNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := s.repo.GetCompletedDays(enrollment.ID)
+	if err != nil {
+		return nil, err
+	}
+	done := toSet(completed)
+
+	return &TodayReading{
+		PlanID:        planID,
+		PlanTitle:     enrollment.Plan.Title,
+		DayNumber:     dayNumber,
+		Date:          now,
+		Passages:      splitPassages(day.Passages),
+		Completed:     done[dayNumber],
+		CompletedDays: len(completed),
+		TotalDays:     enrollment.Plan.TotalDays,
+		DaysBehind:    len(missedDays(dayNumber, done)),
+	}, nil
+}
+
+// CompleteReading records that the user has finished a day's reading.
+// When no day is given, today's reading is marked as complete.
+func (s *Service) CompleteReading(userID, planID uint, req CompleteReadingRequest) (*ReadingProgress, error) {
+	enrollment, err := s.getEnrollment(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	dayNumber := req.DayNumber
+	if dayNumber == 0 {
+		dayNumber = currentDay(enrollment.StartDate, today(), enrollment.Plan.TotalDays)
+		if dayNumber == 0 {
+			return nil, errors.New("reading plan has not started yet")
+		}
+	}
+	if dayNumber > enrollment.Plan.TotalDays {
+		return nil, errors.New("day number is outside this reading plan")
+	}
+
+	completed, err := s.repo.GetCompletedDays(enrollment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if toSet(completed)[dayNumber] {
+		return nil, errors.New("reading already completed")
+	}
+
+	progress := &ReadingProgress{
+		EnrollmentID: enrollment.ID,
+		UserID:       userID,
+		PlanID:       planID,
+		DayNumber:    dayNumber,
+		CompletedAt:  time.Now(),
+	}
+	if err := s.repo.CreateProgress(progress); err != nil {
+		return nil, err
+	}
+
+	// Mark the enrolment as finished once every day has been read
+	if len(completed)+1 >= enrollment.Plan.TotalDays && enrollment.Status != "completed" {
+		completedAt := time.Now()
+		enrollment.Status = "completed"
+		enrollment.CompletedAt = &completedAt
+		enrollment.Plan = nil
+		if err := s.repo.UpdateEnrollment(enrollment); err != nil {
+			return nil, err
+		}
+	}
+
+	return progress, nil
+}
+
+// GetProgress summarises a user's progress through a plan
+func (s *Service) GetProgress(userID, planID uint) (*PlanProgress, error) {
+	enrollment, err := s.getEnrollment(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := s.repo.GetCompletedDays(enrollment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := today()
+	total := enrollment.Plan.TotalDays
+	dayNumber := currentDay(enrollment.StartDate, now, total)
+	missed := missedDays(dayNumber, toSet(completed))
+
+	remaining := total - len(completed)
+	projectedEnd := now
+	if remaining > 0 {
+		projectedEnd = now.AddDate(0, 0, remaining-1)
+	}
+
+	return &PlanProgress{
+		Enrollment:    enrollment,
+		CompletedDays: completed,
+		MissedDays:    missed,
+		CurrentDay:    dayNumber,
+		PercentDone:   float64(len(completed)) / float64(total) * 100,
+		DaysBehind:    len(missed),
+		ProjectedEnd:  projectedEnd,
+	}, nil
+}
+
+// GetCatchUpSchedule builds a plan for getting back on track by spreading the
+// readings the user has missed over the next spreadDays days, alongside the
+// readings already scheduled for those days
+func (s *Service) GetCatchUpSchedule(userID, planID uint, spreadDays int) (*CatchUpSchedule, error) {
+	enrollment, err := s.getEnrollment(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	if spreadDays < 1 {
+		spreadDays = defaultCatchUpDays
+	}
+
+	completed, err := s.repo.GetCompletedDays(enrollment.ID)
+	if err != nil {
+		return nil, err
+	}
+	done := toSet(completed)
+
+	now := today()
+	total := enrollment.Plan.TotalDays
+	dayNumber := currentDay(enrollment.StartDate, now, total)
+	missed := missedDays(dayNumber, done)
+
+	schedule := BuildCatchUpSchedule(missed, dayNumber, total, done, now, spreadDays)
+
+	// Resolve the passages for every scheduled day in one query
+	var needed []int
+	for _, day := range schedule {
+		needed = append(needed, day.DayNumbers...)
+	}
+	planDays, err := s.repo.GetPlanDays(planID, needed)
+	if err != nil {
+		return nil, err
+	}
+	passages := make(map[int][]string, len(planDays))
+	for _, d := range planDays {
+		passages[d.DayNumber] = splitPassages(d.Passages)
+	}
+	for i := range schedule {
+		for _, n := range schedule[i].DayNumbers {
+			schedule[i].Passages = append(schedule[i].Passages, passages[n]...)
+		}
+	}
+
+	return &CatchUpSchedule{
+		PlanID:     planID,
+		DaysBehind: len(missed),
+		SpreadDays: spreadDays,
+		Schedule:   schedule,
+	}, nil
+}
+
+// BuildCatchUpSchedule assigns missed plan days to the coming days. Each of
+// the spreadDays days starting at from keeps its regular reading (if not
+// already done) and takes an even share of the missed readings, oldest first.
+// Passages are left empty for the caller to fill in.
+func BuildCatchUpSchedule(missed []int, currentDay, totalDays int, done map[int]bool, from time.Time, spreadDays int) []CatchUpDay {
+	if spreadDays < 1 {
+		spreadDays = 1
+	}
+
+	schedule := make([]CatchUpDay, 0, spreadDays)
+	for d := 0; d < spreadDays; d++ {
+		var dayNumbers []int
+
+		// Round up so any remainder lands on the earliest days
+		start := (d*len(missed) + spreadDays - 1) / spreadDays
+		end := ((d+1)*len(missed) + spreadDays - 1) / spreadDays
+		dayNumbers = append(dayNumbers, missed[start:end]...)
+
+		if regular := currentDay + d; currentDay > 0 && regular <= totalDays && !done[regular] {
+			dayNumbers = append(dayNumbers, regular)
+		}
+
+		if len(dayNumbers) == 0 {
+			continue
+		}
+		sort.Ints(dayNumbers)
+		schedule = append(schedule, CatchUpDay{
+			Date:       from.AddDate(0, 0, d),
+			DayNumbers: dayNumbers,
+		})
+	}
+
+	return schedule
+}
+
+// getEnrollment loads the user's enrolment in a plan, including the plan itself
+func (s *Service) getEnrollment(userID, planID uint) (*Enrollment, error) {
+	enrollment, err := s.repo.GetEnrollment(userID, planID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("not enrolled in this reading plan")
+		}
+		return nil, err
+	}
+	if enrollment.Plan == nil {
+		return nil, errors.New("reading plan not found")
+	}
+	return enrollment, nil
+}
+
+// today returns the current date in UTC with the time stripped
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// currentDay returns the plan day scheduled for the given date, clamped to the
+// plan length, or 0 if the plan hasn't started yet
+func currentDay(startDate, date time.Time, totalDays int) int {
+	start := startDate.UTC().Truncate(24 * time.Hour)
+	if date.Before(start) {
+		return 0
+	}
+	day := int(date.Sub(start).Hours()/24) + 1
+	if day > totalDays {
+		day = totalDays
+	}
+	return day
+}
+
+// missedDays lists the days before currentDay that haven't been completed
+func missedDays(currentDay int, done map[int]bool) []int {
+	missed := []int{}
+	for day := 1; day < currentDay; day++ {
+		if !done[day] {
+			missed = append(missed, day)
+		}
+	}
+	return missed
+}
+
+func toSet(days []int) map[int]bool {
+	set := make(map[int]bool, len(days))
+	for _, d := range days {
+		set[d] = true
+	}
+	return set
+}
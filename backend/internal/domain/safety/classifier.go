@@ -0,0 +1,153 @@
+package safety
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Risk levels, in increasing order of concern
+const (
+	RiskNone   = "none"
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// Risk categories
+const (
+	CategorySelfHarm = "self_harm"
+	CategorySuicide  = "suicide"
+	CategoryAbuse    = "abuse"
+	CategoryDespair  = "despair"
+)
+
+var riskOrder = map[string]int{RiskNone: 0, RiskLow: 1, RiskMedium: 2, RiskHigh: 3}
+
+// MaxRisk returns the more serious of two risk levels
+func MaxRisk(a, b string) string {
+	if riskOrder[b] > riskOrder[a] {
+		return b
+	}
+	return a
+}
+
+// Assessment is the outcome of classifying a piece of text
+type Assessment struct {
+	Risk       string   `json:"risk"`
+	Categories []string `json:"categories,omitempty"`
+	Detector   string   `json:"detector,omitempty"` // keywords, llm or keywords+llm
+}
+
+type phrase struct {
+	text     string
+	risk     string
+	category string
+}
+
+// phrases are matched against normalised text. They are deliberately broad:
+// a false positive shows someone a helpline, a false negative can cost a life.
+var phrases = []phrase{
+	{"kill myself", RiskHigh, CategorySuicide},
+	{"killing myself", RiskHigh, CategorySuicide},
+	{"end my life", RiskHigh, CategorySuicide},
+	{"ending my life", RiskHigh, CategorySuicide},
+	{"take my own life", RiskHigh, CategorySuicide},
+	{"suicide", RiskHigh, CategorySuicide},
+	{"suicidal", RiskHigh, CategorySuicide},
+	{"want to die", RiskHigh, CategorySuicide},
+	{"wanna die", RiskHigh, CategorySuicide},
+	{"better off dead", RiskHigh, CategorySuicide},
+	{"better off without me", RiskHigh, CategorySuicide},
+	{"no reason to live", RiskHigh, CategorySuicide},
+	{"dont want to be alive", RiskHigh, CategorySuicide},
+	{"dont want to live", RiskHigh, CategorySuicide},
+	{"do not want to live", RiskHigh, CategorySuicide},
+	{"do not want to be alive", RiskHigh, CategorySuicide},
+	{"overdose", RiskHigh, CategorySelfHarm},
+	{"hurt myself", RiskHigh, CategorySelfHarm},
+	{"hurting myself", RiskHigh, CategorySelfHarm},
+	{"harm myself", RiskHigh, CategorySelfHarm},
+	{"self harm", RiskHigh, CategorySelfHarm},
+	{"cut myself", RiskHigh, CategorySelfHarm},
+	{"cutting myself", RiskHigh, CategorySelfHarm},
+	{"not safe at home", RiskHigh, CategoryAbuse},
+	{"hits me", RiskMedium, CategoryAbuse},
+	{"beats me", RiskMedium, CategoryAbuse},
+	{"being abused", RiskMedium, CategoryAbuse},
+	{"abusing me", RiskMedium, CategoryAbuse},
+	{"cant go on", RiskMedium, CategoryDespair},
+	{"no way out", RiskMedium, CategoryDespair},
+	{"nobody would miss me", RiskMedium, CategoryDespair},
+	{"no one would miss me", RiskMedium, CategoryDespair},
+	{"give up on life", RiskMedium, CategoryDespair},
+	{"disappear forever", RiskMedium, CategoryDespair},
+	{"hopeless", RiskLow, CategoryDespair},
+	{"worthless", RiskLow, CategoryDespair},
+	{"empty inside", RiskLow, CategoryDespair},
+}
+
+// negations that, directly before a phrase, mean the writer is denying it
+var negations = []string{"not ", "never ", "dont ", "no longer ", "wouldnt ", "would never "}
+
+var nonLetters = regexp.MustCompile(`[^a-z0-9 ]+`)
+
+// normalise lowercases text, drops apostrophes so "don't" matches "dont",
+// and reduces everything else to single spaces
+func normalise(text string) string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer("'", "", "’", "", "-", " ").Replace(text)
+	text = nonLetters.ReplaceAllString(text, " ")
+	return " " + strings.Join(strings.Fields(text), " ") + " "
+}
+
+// Classify assesses text with local keyword heuristics. It is fast, needs no
+// network and is always run; LLM moderation can only raise its result.
+func Classify(text string) Assessment {
+	normalised := normalise(text)
+	risk := RiskNone
+	categories := make(map[string]bool)
+
+	for _, p := range phrases {
+		needle := " " + p.text + " "
+		// Every mention counts, so an earlier denial can't hide a later one
+		for start := 0; ; {
+			idx := strings.Index(normalised[start:], needle)
+			if idx < 0 {
+				break
+			}
+			idx += start
+			start = idx + len(needle) - 1
+
+			phraseRisk := p.risk
+			if negated(normalised[:idx+1]) {
+				// "I'm not suicidal" still deserves gentle care, but not a crisis response
+				phraseRisk = RiskLow
+			}
+			risk = MaxRisk(risk, phraseRisk)
+			categories[p.category] = true
+		}
+	}
+
+	assessment := Assessment{Risk: risk}
+	if risk != RiskNone {
+		assessment.Detector = "keywords"
+		for c := range categories {
+			assessment.Categories = append(assessment.Categories, c)
+		}
+		sort.Strings(assessment.Categories)
+	}
+	return assessment
+}
+
+// negated reports whether the text before a phrase ends with a negation.
+// Only a negation directly before the phrase counts: in "no longer cope,
+// suicide" the negation belongs to another word.
+func negated(before string) bool {
+	for _, n := range negations {
+		if strings.HasSuffix(before, " "+n) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package safety
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service *Service
+}
+
+func NewController(service *Service) *Controller {
+	return &Controller{service: service}
+}
+
+// GetEvents lists safety events for admins, optionally filtered with
+// ?risk= and ?unreviewed=true
+func (c *Controller) GetEvents(ctx *gin.Context) {
+	events, err := c.service.GetEvents(ctx.Query("risk"), ctx.Query("unreviewed") == "true")
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
+
+// ReviewEvent marks a safety event as reviewed by the authenticated admin
+func (c *Controller) ReviewEvent(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	event, err := c.service.ReviewEvent(uint(id), userID.(uint))
+	if err != nil {
+		if err.Error() == "safety event not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, event)
+}
@@ -0,0 +1,64 @@
+package safety
+
+import (
+	"time"
+)
+
+// Sources of user-authored text that are checked
+const (
+	SourceStruggle    = "struggle"
+	SourceAIEncourage = "ai_encourage"
+	SourceJournal     = "journal"
+	SourceMood        = "mood"
+	SourceCompanion   = "companion"
+)
+
+// Actions taken in response to flagged text
+const (
+	ActionResourcesShown = "resources_shown"
+	ActionAIBlocked      = "ai_blocked"
+)
+
+// Event is an audit record of flagged text. The text itself is not stored,
+// only a hash, so the record can be matched to content without copying it.
+type Event struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Source      string     `json:"source" gorm:"not null"`
+	Risk        string     `json:"risk" gorm:"not null;index"`
+	Categories  string     `json:"categories"` // Comma-separated
+	Detector    string     `json:"detector"`
+	Action      string     `json:"action"`
+	Locale      string     `json:"locale"`
+	ContentHash string     `json:"content_hash"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	ReviewedBy  *uint      `json:"reviewed_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName matches the safety_events table created by the migrations
+func (Event) TableName() string {
+	return "safety_events"
+}
+
+// Notice is returned alongside a response when text was flagged
+type Notice struct {
+	Risk       string     `json:"risk"`
+	Categories []string   `json:"categories,omitempty"`
+	Message    string     `json:"message"`
+	Resources  []Resource `json:"resources"`
+}
+
+// Result is the outcome of a safety check
+type Result struct {
+	Assessment
+	// Notice is set when the user should be shown crisis resources
+	Notice *Notice
+	// ShortCircuit is set when AI generation should be skipped entirely
+	ShortCircuit bool
+}
+
+// Flagged reports whether the text raised any concern
+func (r *Result) Flagged() bool {
+	return r != nil && r.Notice != nil
+}
@@ -0,0 +1,52 @@
+package safety
+
+import (
+	"context"
+	"time"
+
+	"armourup/internal/llm"
+)
+
+// moderationTimeout bounds how long LLM moderation may delay a request
+const moderationTimeout = 5 * time.Second
+
+const moderationPrompt = `You are a safety classifier for a Christian wellbeing app. Assess whether the user's text indicates risk of suicide, self-harm, abuse or severe despair.
+Respond ONLY with JSON: {"risk": "none|low|medium|high", "categories": ["suicide"|"self_harm"|"abuse"|"despair"]}
+Use "high" for any suggestion of intent or plans to end their life or harm themselves, or of being in danger.`
+
+// moderationSchema is the structure a moderation verdict must have
+var moderationSchema = llm.Schema{
+	Name: "moderation",
+	Fields: []llm.Field{
+		{Name: "risk", Type: llm.TypeString, Required: true, MaxLength: 10},
+		{Name: "categories", Type: llm.TypeStringList, MaxItems: 4, MaxLength: 20},
+	},
+}
+
+// moderate asks the LLM to classify text. Any failure returns no opinion so
+// that the keyword classifier's result stands.
+func moderate(ctx context.Context, provider llm.Provider, text string) (Assessment, bool) {
+	ctx, cancel := context.WithTimeout(ctx, moderationTimeout)
+	defer cancel()
+
+	var result struct {
+		Risk       string   `json:"risk"`
+		Categories []string `json:"categories"`
+	}
+	_, err := llm.ChatJSON(ctx, provider, llm.Request{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: moderationPrompt},
+			{Role: llm.RoleUser, Content: text},
+		},
+		Temperature: 0,
+		MaxTokens:   60,
+	}, moderationSchema, &result)
+	if err != nil {
+		return Assessment{}, false
+	}
+	if _, ok := riskOrder[result.Risk]; !ok {
+		return Assessment{}, false
+	}
+
+	return Assessment{Risk: result.Risk, Categories: result.Categories, Detector: "llm"}, true
+}
@@ -0,0 +1,48 @@
+package safety
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// CreateEvent records a safety event
+func (r *Repository) CreateEvent(event *Event) error {
+	return r.db.Create(event).Error
+}
+
+// GetEvent retrieves a safety event by ID
+func (r *Repository) GetEvent(id uint) (*Event, error) {
+	var event Event
+	err := r.db.First(&event, id).Error
+	return &event, err
+}
+
+// GetEvents retrieves safety events, newest first, optionally filtered by risk
+// and review status
+func (r *Repository) GetEvents(risk string, unreviewedOnly bool, limit int) ([]Event, error) {
+	var events []Event
+	query := r.db.Order("created_at DESC").Limit(limit)
+	if risk != "" {
+		query = query.Where("risk = ?", risk)
+	}
+	if unreviewedOnly {
+		query = query.Where("reviewed_at IS NULL")
+	}
+	err := query.Find(&events).Error
+	return events, err
+}
+
+// MarkReviewed records that an admin has reviewed an event
+func (r *Repository) MarkReviewed(id, reviewerID uint, at time.Time) error {
+	return r.db.Model(&Event{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"reviewed_at": at, "reviewed_by": reviewerID}).Error
+}
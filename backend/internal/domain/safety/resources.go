@@ -0,0 +1,86 @@
+package safety
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+//go:embed resources.json
+var defaultResourcesJSON []byte
+
+// Resource is a crisis line or service someone can contact
+type Resource struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Phone       string `json:"phone,omitempty"`
+	SMS         string `json:"sms,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// ResourceSet is the crisis message and resources for a locale
+type ResourceSet struct {
+	Message   string     `json:"message"`
+	Resources []Resource `json:"resources"`
+}
+
+// Directory holds crisis resources by locale, e.g. "en-GB", with a "default" entry
+type Directory map[string]ResourceSet
+
+// LoadDirectory loads the crisis resources shipped with the service, or from
+// a JSON file in the same format when path is set
+func LoadDirectory(path string) (Directory, error) {
+	data := defaultResourcesJSON
+	if path != "" {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read crisis resources: %w", err)
+		}
+		data = fileData
+	}
+
+	var directory Directory
+	if err := json.Unmarshal(data, &directory); err != nil {
+		return nil, fmt.Errorf("failed to parse crisis resources: %w", err)
+	}
+	if _, ok := directory["default"]; !ok {
+		return nil, fmt.Errorf("crisis resources must include a default entry")
+	}
+	return directory, nil
+}
+
+// ForLocale returns the resources for a locale such as "en-GB". It falls back
+// to a locale for the same region ("fr-CA" uses "en-CA") and then to the default.
+func (d Directory) ForLocale(locale string) ResourceSet {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	for key, set := range d {
+		if strings.EqualFold(key, locale) {
+			return set
+		}
+	}
+
+	if parts := strings.Split(locale, "-"); len(parts) > 1 {
+		region := parts[len(parts)-1]
+		keys := make([]string, 0, len(d))
+		for key := range d {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if keyParts := strings.Split(key, "-"); len(keyParts) > 1 && strings.EqualFold(keyParts[len(keyParts)-1], region) {
+				return d[key]
+			}
+		}
+	}
+
+	return d["default"]
+}
+
+// LocaleFromHeader picks the preferred locale from an Accept-Language header
+func LocaleFromHeader(header string) string {
+	first := strings.Split(header, ",")[0]
+	return strings.TrimSpace(strings.Split(first, ";")[0])
+}
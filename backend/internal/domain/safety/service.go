@@ -0,0 +1,184 @@
+package safety
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"armourup/internal/domain/privacy"
+	"armourup/internal/domain/usage"
+	"armourup/internal/llm"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultEventLimit caps how many events the admin listing returns
+const defaultEventLimit = 100
+
+// Options configures the safety pipeline
+type Options struct {
+	// LLMModeration adds a second opinion from the LLM provider when the
+	// keyword classifier hasn't already found high risk
+	LLMModeration bool
+	// DefaultLocale chooses crisis resources when the request doesn't say
+	DefaultLocale string
+}
+
+type Service struct {
+	repo      *Repository
+	provider  llm.Provider
+	privacy   *privacy.Service
+	directory Directory
+	opts      Options
+}
+
+// NewService creates the safety pipeline. The provider may be nil, in which
+// case only the keyword classifier is used. Text the user wrote for an AI
+// feature is always moderated, while journal entries and other stored text
+// are only sent for moderation if the user shares full text with AI. Either
+// way it is redacted first.
+func NewService(repo *Repository, provider llm.Provider, privacyService *privacy.Service, directory Directory, opts Options) *Service {
+	if opts.DefaultLocale == "" {
+		opts.DefaultLocale = "en-US"
+	}
+	return &Service{
+		repo:      repo,
+		provider:  provider,
+		privacy:   privacyService,
+		directory: directory,
+		opts:      opts,
+	}
+}
+
+// Check assesses user-authored text. Medium and high risk text gets crisis
+// resources for the locale and is recorded as a safety event; high risk text
+// also short-circuits AI generation.
+func (s *Service) Check(ctx context.Context, userID uint, source, text, locale string) *Result {
+	result := &Result{Assessment: Assessment{Risk: RiskNone}}
+	if s == nil || strings.TrimSpace(text) == "" {
+		return result
+	}
+
+	result.Assessment = s.assess(llm.WithCaller(ctx, userID, usage.FeatureModeration), userID, source, text)
+	if riskOrder[result.Risk] < riskOrder[RiskMedium] {
+		return result
+	}
+
+	if locale == "" {
+		locale = s.opts.DefaultLocale
+	}
+	resources := s.directory.ForLocale(locale)
+	result.Notice = &Notice{
+		Risk:       result.Risk,
+		Categories: result.Categories,
+		Message:    resources.Message,
+		Resources:  resources.Resources,
+	}
+	result.ShortCircuit = result.Risk == RiskHigh
+
+	action := ActionResourcesShown
+	if result.ShortCircuit && (source == SourceAIEncourage || source == SourceCompanion) {
+		action = ActionAIBlocked
+	}
+	s.record(userID, source, locale, action, text, result.Assessment)
+
+	return result
+}
+
+// CheckRequest runs Check for the authenticated user of a request, taking the
+// locale from ?locale= or the Accept-Language header
+func (s *Service) CheckRequest(ctx *gin.Context, source, text string) *Result {
+	if s == nil {
+		return &Result{Assessment: Assessment{Risk: RiskNone}}
+	}
+
+	var userID uint
+	if id, exists := ctx.Get("user_id"); exists {
+		userID = id.(uint)
+	}
+
+	locale := ctx.Query("locale")
+	if locale == "" {
+		locale = LocaleFromHeader(ctx.GetHeader("Accept-Language"))
+	}
+
+	return s.Check(ctx.Request.Context(), userID, source, text, locale)
+}
+
+// GetEvents lists safety events for review
+func (s *Service) GetEvents(risk string, unreviewedOnly bool) ([]Event, error) {
+	return s.repo.GetEvents(risk, unreviewedOnly, defaultEventLimit)
+}
+
+// ReviewEvent marks an event as reviewed by an admin
+func (s *Service) ReviewEvent(id, reviewerID uint) (*Event, error) {
+	if _, err := s.repo.GetEvent(id); err != nil {
+		return nil, errors.New("safety event not found")
+	}
+	if err := s.repo.MarkReviewed(id, reviewerID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetEvent(id)
+}
+
+// assess combines the keyword classifier with optional LLM moderation,
+// keeping whichever found the higher risk
+func (s *Service) assess(ctx context.Context, userID uint, source, text string) Assessment {
+	assessment := Classify(text)
+	if !s.opts.LLMModeration || s.provider == nil || assessment.Risk == RiskHigh {
+		return assessment
+	}
+
+	disclosure := s.privacy.Begin(userID)
+	forAI := source == SourceAIEncourage || source == SourceCompanion
+	if !forAI && !disclosure.AllowsText() {
+		return assessment
+	}
+	moderated, ok := moderate(ctx, s.provider, disclosure.Text(text))
+	s.privacy.Audit(userID, usage.FeatureModeration, disclosure)
+	if !ok || riskOrder[moderated.Risk] <= riskOrder[assessment.Risk] {
+		return assessment
+	}
+
+	combined := Assessment{Risk: moderated.Risk, Detector: "llm"}
+	if assessment.Risk != RiskNone {
+		combined.Detector = "keywords+llm"
+	}
+	categories := make(map[string]bool)
+	for _, c := range append(assessment.Categories, moderated.Categories...) {
+		categories[c] = true
+	}
+	for c := range categories {
+		combined.Categories = append(combined.Categories, c)
+	}
+	sort.Strings(combined.Categories)
+	return combined
+}
+
+// record stores the audit event. Failing to record must never stop someone
+// from seeing crisis resources, so errors are only logged.
+func (s *Service) record(userID uint, source, locale, action, text string, assessment Assessment) {
+	if s.repo == nil {
+		return
+	}
+
+	hash := sha256.Sum256([]byte(text))
+	event := &Event{
+		UserID:      userID,
+		Source:      source,
+		Risk:        assessment.Risk,
+		Categories:  strings.Join(assessment.Categories, ","),
+		Detector:    assessment.Detector,
+		Action:      action,
+		Locale:      locale,
+		ContentHash: hex.EncodeToString(hash[:]),
+	}
+	if err := s.repo.CreateEvent(event); err != nil {
+		log.Printf("Warning: failed to record safety event: %v", err)
+	}
+}
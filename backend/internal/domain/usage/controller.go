@@ -0,0 +1,57 @@
+package usage
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service *Service
+}
+
+func NewController(service *Service) *Controller {
+	return &Controller{service: service}
+}
+
+// GetUsage returns the authenticated user's AI usage and remaining budgets
+func (c *Controller) GetUsage(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	summary, err := c.service.GetUsage(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, summary)
+}
+
+// RespondIfQuotaExceeded writes a 429 response with a Retry-After header if
+// err is a QuotaError, and reports whether it did
+func RespondIfQuotaExceeded(ctx *gin.Context, err error) bool {
+	var quotaErr *QuotaError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(time.Until(quotaErr.ResetsAt).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{
+		"error":     quotaErr.Error(),
+		"scope":     quotaErr.Scope,
+		"resets_at": quotaErr.ResetsAt,
+	})
+	return true
+}
@@ -0,0 +1,57 @@
+package usage
+
+import (
+	"time"
+)
+
+// Features that make AI calls, recorded against each call
+const (
+	FeatureEncouragement = "encouragement"
+	FeatureInsights      = "insights"
+	FeatureCompanion     = "companion"
+	FeatureModeration    = "moderation"
+)
+
+// Record is the token usage and cost of a single AI call
+type Record struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	UserID           uint      `json:"user_id" gorm:"index"` // Zero for calls not made for a user
+	Feature          string    `json:"feature"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	CostUSD          float64   `json:"cost_usd"`
+	CreatedAt        time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName matches the ai_usage_records table created by the migrations
+func (Record) TableName() string {
+	return "ai_usage_records"
+}
+
+// Budget is the tokens used against a limit in the current window. A zero
+// limit means unlimited.
+type Budget struct {
+	Used     int       `json:"used"`
+	Limit    int       `json:"limit"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+// FeatureUsage totals a user's AI calls for one feature and model
+type FeatureUsage struct {
+	Feature          string  `json:"feature"`
+	Model            string  `json:"model"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// Summary is a user's AI usage for today and this month
+type Summary struct {
+	Daily     Budget         `json:"daily"`
+	Monthly   Budget         `json:"monthly"`
+	ThisMonth []FeatureUsage `json:"this_month"`
+}
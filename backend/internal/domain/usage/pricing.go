@@ -0,0 +1,41 @@
+package usage
+
+import (
+	"strings"
+)
+
+// Price is the cost in US dollars per 1,000 tokens
+type Price struct {
+	PromptPer1K     float64 `mapstructure:"prompt_per_1k" json:"prompt_per_1k"`
+	CompletionPer1K float64 `mapstructure:"completion_per_1k" json:"completion_per_1k"`
+}
+
+// Pricing maps model names, or model name prefixes, to prices
+type Pricing map[string]Price
+
+// DefaultPricing covers the OpenAI models the app uses out of the box.
+// Models without a price, such as local models, cost nothing.
+var DefaultPricing = Pricing{
+	"gpt-3.5-turbo":          {PromptPer1K: 0.0005, CompletionPer1K: 0.0015},
+	"gpt-4":                  {PromptPer1K: 0.03, CompletionPer1K: 0.06},
+	"gpt-4-turbo":            {PromptPer1K: 0.01, CompletionPer1K: 0.03},
+	"gpt-4o":                 {PromptPer1K: 0.005, CompletionPer1K: 0.015},
+	"gpt-4o-mini":            {PromptPer1K: 0.00015, CompletionPer1K: 0.0006},
+	"text-embedding-ada-002": {PromptPer1K: 0.0001},
+}
+
+// Cost prices a call. The model is matched exactly or by the longest priced
+// prefix, so "gpt-4-turbo-preview" is priced as "gpt-4-turbo".
+func (p Pricing) Cost(model string, promptTokens, completionTokens int) float64 {
+	price, ok := p[model]
+	if !ok {
+		longest := ""
+		for name, candidate := range p {
+			if strings.HasPrefix(model, name) && len(name) > len(longest) {
+				longest = name
+				price = candidate
+			}
+		}
+	}
+	return float64(promptTokens)/1000*price.PromptPer1K + float64(completionTokens)/1000*price.CompletionPer1K
+}
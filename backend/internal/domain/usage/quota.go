@@ -0,0 +1,65 @@
+package usage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Quota scopes
+const (
+	ScopeDaily   = "daily"
+	ScopeMonthly = "monthly"
+	ScopeGlobal  = "global"
+)
+
+// Limits are the AI budgets. Zero means unlimited.
+type Limits struct {
+	DailyTokens      int     // Per user, per UTC day
+	MonthlyTokens    int     // Per user, per UTC calendar month
+	GlobalMonthlyUSD float64 // Across all users, per UTC calendar month
+}
+
+// QuotaError is returned instead of making an AI call when a budget is used up
+type QuotaError struct {
+	Scope    string    `json:"scope"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+func (e *QuotaError) Error() string {
+	if e.Scope == ScopeGlobal {
+		return "AI features have reached their usage limit for this month, please try again later"
+	}
+	return fmt.Sprintf("you have reached your %s AI usage limit", e.Scope)
+}
+
+// DayStart returns the start of the UTC day containing t
+func DayStart(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// MonthStart returns the start of the UTC month containing t
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// CheckUser returns a QuotaError if a user's usage today or this month has
+// reached its limit
+func (l Limits) CheckUser(dailyUsed, monthlyUsed int, now time.Time) error {
+	if l.DailyTokens > 0 && dailyUsed >= l.DailyTokens {
+		return &QuotaError{Scope: ScopeDaily, ResetsAt: DayStart(now).AddDate(0, 0, 1)}
+	}
+	if l.MonthlyTokens > 0 && monthlyUsed >= l.MonthlyTokens {
+		return &QuotaError{Scope: ScopeMonthly, ResetsAt: MonthStart(now).AddDate(0, 1, 0)}
+	}
+	return nil
+}
+
+// CheckGlobal returns a QuotaError if this month's spending across all users
+// has reached the ceiling
+func (l Limits) CheckGlobal(monthlySpendUSD float64, now time.Time) error {
+	if l.GlobalMonthlyUSD > 0 && monthlySpendUSD >= l.GlobalMonthlyUSD {
+		return &QuotaError{Scope: ScopeGlobal, ResetsAt: MonthStart(now).AddDate(0, 1, 0)}
+	}
+	return nil
+}
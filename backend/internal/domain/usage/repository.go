@@ -0,0 +1,52 @@
+package usage
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create records a single AI call
+func (r *Repository) Create(record *Record) error {
+	return r.db.Create(record).Error
+}
+
+// SumTokensSince totals a user's tokens since a time
+func (r *Repository) SumTokensSince(userID uint, since time.Time) (int, error) {
+	var total int
+	err := r.db.Model(&Record{}).
+		Select("COALESCE(SUM(total_tokens), 0)").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Scan(&total).Error
+	return total, err
+}
+
+// SumCostSince totals the cost of every AI call since a time
+func (r *Repository) SumCostSince(since time.Time) (float64, error) {
+	var total float64
+	err := r.db.Model(&Record{}).
+		Select("COALESCE(SUM(cost_usd), 0)").
+		Where("created_at >= ?", since).
+		Scan(&total).Error
+	return total, err
+}
+
+// GetByFeatureSince totals a user's calls by feature and model since a time
+func (r *Repository) GetByFeatureSince(userID uint, since time.Time) ([]FeatureUsage, error) {
+	var usage []FeatureUsage
+	err := r.db.Model(&Record{}).
+		Select("feature, model, COUNT(*) AS calls, SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens, SUM(cost_usd) AS cost_usd").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Group("feature, model").
+		Order("feature, model").
+		Scan(&usage).Error
+	return usage, err
+}
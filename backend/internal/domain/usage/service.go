@@ -0,0 +1,102 @@
+package usage
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"armourup/internal/llm"
+)
+
+// Service meters AI calls against per-user and global budgets. It
+// implements llm.Meter.
+type Service struct {
+	repo    *Repository
+	limits  Limits
+	pricing Pricing
+}
+
+// NewService creates the usage service. A nil pricing uses DefaultPricing.
+func NewService(repo *Repository, limits Limits, pricing Pricing) *Service {
+	if pricing == nil {
+		pricing = DefaultPricing
+	}
+	return &Service{
+		repo:    repo,
+		limits:  limits,
+		pricing: pricing,
+	}
+}
+
+// Allow checks the global spending ceiling and, for calls made for a user,
+// their daily and monthly token budgets
+func (s *Service) Allow(ctx context.Context, caller llm.Caller) error {
+	now := time.Now()
+
+	if s.limits.GlobalMonthlyUSD > 0 {
+		spend, err := s.repo.SumCostSince(MonthStart(now))
+		if err != nil {
+			// Don't take AI features down because metering is unavailable
+			log.Printf("Warning: failed to check AI spending: %v", err)
+		} else if err := s.limits.CheckGlobal(spend, now); err != nil {
+			return err
+		}
+	}
+
+	if caller.UserID == 0 || (s.limits.DailyTokens == 0 && s.limits.MonthlyTokens == 0) {
+		return nil
+	}
+	daily, err := s.repo.SumTokensSince(caller.UserID, DayStart(now))
+	if err != nil {
+		log.Printf("Warning: failed to check AI usage: %v", err)
+		return nil
+	}
+	monthly, err := s.repo.SumTokensSince(caller.UserID, MonthStart(now))
+	if err != nil {
+		log.Printf("Warning: failed to check AI usage: %v", err)
+		return nil
+	}
+	return s.limits.CheckUser(daily, monthly, now)
+}
+
+// Record stores the tokens and cost of an AI call
+func (s *Service) Record(caller llm.Caller, provider, model string, usage llm.Usage) {
+	record := &Record{
+		UserID:           caller.UserID,
+		Feature:          caller.Feature,
+		Provider:         provider,
+		Model:            model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		CostUSD:          s.pricing.Cost(model, usage.PromptTokens, usage.CompletionTokens),
+	}
+	if err := s.repo.Create(record); err != nil {
+		log.Printf("Warning: failed to record AI usage: %v", err)
+	}
+}
+
+// GetUsage summarises a user's AI usage against their budgets
+func (s *Service) GetUsage(userID uint) (*Summary, error) {
+	now := time.Now()
+	dayStart, monthStart := DayStart(now), MonthStart(now)
+
+	daily, err := s.repo.SumTokensSince(userID, dayStart)
+	if err != nil {
+		return nil, err
+	}
+	monthly, err := s.repo.SumTokensSince(userID, monthStart)
+	if err != nil {
+		return nil, err
+	}
+	byFeature, err := s.repo.GetByFeatureSince(userID, monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Summary{
+		Daily:     Budget{Used: daily, Limit: s.limits.DailyTokens, ResetsAt: dayStart.AddDate(0, 0, 1)},
+		Monthly:   Budget{Used: monthly, Limit: s.limits.MonthlyTokens, ResetsAt: monthStart.AddDate(0, 1, 0)},
+		ThisMonth: byFeature,
+	}, nil
+}
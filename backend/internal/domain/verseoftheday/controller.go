@@ -0,0 +1,138 @@
+package verseoftheday
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	service *Service
+}
+
+func NewController(service *Service) *Controller {
+	return &Controller{service: service}
+}
+
+// GetVerseOfTheDay returns today's verse. Pass scope=personal for a verse
+// themed by the authenticated user's recent moods.
+func (c *Controller) GetVerseOfTheDay(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	personal := ctx.DefaultQuery("scope", "global") == "personal"
+
+	verse, err := c.service.GetVerseOfTheDay(userID.(uint), personal)
+	if err != nil {
+		if err.Error() == "verse pool is empty" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verse)
+}
+
+// GetHistory returns the verses of the day for the last N days
+func (c *Controller) GetHistory(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	personal := ctx.DefaultQuery("scope", "global") == "personal"
+	daysStr := ctx.DefaultQuery("days", "30")
+	days, err := strconv.Atoi(daysStr)
+	if err != nil || days < 1 {
+		days = 30
+	}
+
+	history, err := c.service.GetHistory(userID.(uint), personal, days)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+// GetPool lists every verse in the curated pool
+func (c *Controller) GetPool(ctx *gin.Context) {
+	pool, err := c.service.GetPool()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pool)
+}
+
+// AddPoolVerse adds a verse to the curated pool
+func (c *Controller) AddPoolVerse(ctx *gin.Context) {
+	var verse PoolVerse
+	if err := ctx.ShouldBindJSON(&verse); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.service.AddPoolVerse(&verse); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, verse)
+}
+
+// UpdatePoolVerse updates a verse in the curated pool
+func (c *Controller) UpdatePoolVerse(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	var update PoolVerse
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	verse, err := c.service.UpdatePoolVerse(uint(id), &update)
+	if err != nil {
+		if err.Error() == "pool verse not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verse)
+}
+
+// DeletePoolVerse removes a verse from the curated pool
+func (c *Controller) DeletePoolVerse(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	if err := c.service.DeletePoolVerse(uint(id)); err != nil {
+		if err.Error() == "pool verse not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
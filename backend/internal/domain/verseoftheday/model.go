@@ -0,0 +1,55 @@
+package verseoftheday
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PoolVerse is an admin-curated verse that can be chosen as the verse of the day
+type PoolVerse struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Reference string         `json:"reference" binding:"required" gorm:"uniqueIndex;not null"`
+	Text      string         `json:"text" binding:"required" gorm:"not null"`
+	Themes    string         `json:"themes"`  // Comma-separated, e.g. "peace,comfort"
+	Seasons   string         `json:"seasons"` // Comma-separated liturgical seasons; empty means any season
+	Active    *bool          `json:"active" gorm:"default:true"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName matches the verse_pool table created by the migrations
+func (PoolVerse) TableName() string {
+	return "verse_pool"
+}
+
+// DailyVerse records which verse was chosen for a scope on a given day.
+// It doubles as the per-day cache and the history used to avoid repeats.
+// UserID is 0 for the global verse of the day.
+type DailyVerse struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_verse_history_user_date"`
+	Date      time.Time  `json:"date" gorm:"type:date;not null;uniqueIndex:idx_verse_history_user_date"`
+	VerseID   uint       `json:"verse_id" gorm:"not null;index"`
+	Theme     string     `json:"theme"`
+	Season    string     `json:"season"`
+	Verse     *PoolVerse `json:"verse,omitempty" gorm:"foreignKey:VerseID"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName matches the verse_of_the_day_history table created by the migrations
+func (DailyVerse) TableName() string {
+	return "verse_of_the_day_history"
+}
+
+// VerseOfTheDay is the response for the verse of the day endpoint
+type VerseOfTheDay struct {
+	Date      time.Time `json:"date"`
+	Scope     string    `json:"scope"` // global or personal
+	Reference string    `json:"reference"`
+	Text      string    `json:"text"`
+	Verse     string    `json:"verse"`
+	Theme     string    `json:"theme,omitempty"`
+	Season    string    `json:"season"`
+}
@@ -0,0 +1,99 @@
+package verseoftheday
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// CountPool counts every verse in the pool, active or not
+func (r *Repository) CountPool() (int64, error) {
+	var count int64
+	err := r.db.Model(&PoolVerse{}).Count(&count).Error
+	return count, err
+}
+
+// CreatePoolVerses adds verses to the pool in a single batch
+func (r *Repository) CreatePoolVerses(verses []PoolVerse) error {
+	return r.db.CreateInBatches(verses, 100).Error
+}
+
+// CreatePoolVerse adds a verse to the pool
+func (r *Repository) CreatePoolVerse(verse *PoolVerse) error {
+	return r.db.Create(verse).Error
+}
+
+// GetPoolVerse retrieves a pool verse by ID
+func (r *Repository) GetPoolVerse(id uint) (*PoolVerse, error) {
+	var verse PoolVerse
+	err := r.db.First(&verse, id).Error
+	return &verse, err
+}
+
+// GetPool retrieves every verse in the pool
+func (r *Repository) GetPool() ([]PoolVerse, error) {
+	var verses []PoolVerse
+	err := r.db.Order("id ASC").Find(&verses).Error
+	return verses, err
+}
+
+// GetActivePool retrieves the verses that can currently be chosen
+func (r *Repository) GetActivePool() ([]PoolVerse, error) {
+	var verses []PoolVerse
+	err := r.db.Where("active = ?", true).Order("id ASC").Find(&verses).Error
+	return verses, err
+}
+
+// UpdatePoolVerse updates an existing pool verse
+func (r *Repository) UpdatePoolVerse(verse *PoolVerse) error {
+	return r.db.Save(verse).Error
+}
+
+// DeletePoolVerse soft deletes a pool verse
+func (r *Repository) DeletePoolVerse(id uint) error {
+	return r.db.Delete(&PoolVerse{}, id).Error
+}
+
+// GetDailyVerse retrieves the verse already chosen for a scope and date
+func (r *Repository) GetDailyVerse(userID uint, date time.Time) (*DailyVerse, error) {
+	var daily DailyVerse
+	err := r.db.Preload("Verse", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Where("user_id = ? AND date = ?", userID, date).First(&daily).Error
+	return &daily, err
+}
+
+// CreateDailyVerse records the day's choice. If another request already
+// recorded one for the same scope and date, the existing row wins.
+func (r *Repository) CreateDailyVerse(daily *DailyVerse) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(daily).Error
+}
+
+// GetRecentVerseIDs returns the verses chosen for a scope since the given date
+func (r *Repository) GetRecentVerseIDs(userID uint, since time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&DailyVerse{}).
+		Where("user_id = ? AND date >= ?", userID, since).
+		Pluck("verse_id", &ids).Error
+	return ids, err
+}
+
+// GetHistory retrieves the verses chosen for a scope since the given date
+func (r *Repository) GetHistory(userID uint, since time.Time) ([]DailyVerse, error) {
+	var history []DailyVerse
+	err := r.db.Preload("Verse", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Where("user_id = ? AND date >= ?", userID, since).
+		Order("date DESC").
+		Find(&history).Error
+	return history, err
+}
@@ -0,0 +1,132 @@
+package verseoftheday
+
+import (
+	"strings"
+	"time"
+
+	"armourup/internal/domain/mood"
+)
+
+// Liturgical seasons of the Western church year
+const (
+	SeasonAdvent    = "advent"
+	SeasonChristmas = "christmas"
+	SeasonEpiphany  = "epiphany"
+	SeasonLent      = "lent"
+	SeasonEaster    = "easter"
+	SeasonPentecost = "pentecost"
+	SeasonOrdinary  = "ordinary"
+)
+
+// LiturgicalSeason returns the Western liturgical season for a date
+func LiturgicalSeason(date time.Time) string {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	year := date.Year()
+
+	christmas := time.Date(year, time.December, 25, 0, 0, 0, 0, time.UTC)
+	// Advent starts on the fourth Sunday before Christmas
+	adventStart := christmas.AddDate(0, 0, -int(christmas.Weekday())-21)
+	if christmas.Weekday() == time.Sunday {
+		adventStart = christmas.AddDate(0, 0, -28)
+	}
+
+	easter := EasterSunday(year)
+	ashWednesday := easter.AddDate(0, 0, -46)
+	pentecost := easter.AddDate(0, 0, 49)
+
+	switch {
+	case !date.Before(christmas) || date.Before(time.Date(year, time.January, 6, 0, 0, 0, 0, time.UTC)):
+		return SeasonChristmas
+	case !date.Before(adventStart):
+		return SeasonAdvent
+	case date.Before(ashWednesday):
+		return SeasonEpiphany
+	case date.Before(easter):
+		return SeasonLent
+	case date.Before(pentecost):
+		return SeasonEaster
+	case date.Equal(pentecost):
+		return SeasonPentecost
+	default:
+		return SeasonOrdinary
+	}
+}
+
+// EasterSunday computes the date of Western Easter using the anonymous
+// Gregorian algorithm
+func EasterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// moodThemes maps common emotional states to the verse theme that speaks to them
+var moodThemes = map[string]string{
+	"anxious":     "peace",
+	"worried":     "peace",
+	"stressed":    "peace",
+	"fearful":     "peace",
+	"overwhelmed": "peace",
+	"sad":         "comfort",
+	"lonely":      "comfort",
+	"grieving":    "comfort",
+	"down":        "comfort",
+	"depressed":   "comfort",
+	"hurt":        "comfort",
+	"angry":       "patience",
+	"frustrated":  "patience",
+	"irritable":   "patience",
+	"tired":       "strength",
+	"exhausted":   "strength",
+	"weary":       "strength",
+	"grateful":    "thanksgiving",
+	"thankful":    "thanksgiving",
+	"blessed":     "thanksgiving",
+	"happy":       "joy",
+	"joyful":      "joy",
+	"content":     "joy",
+	"peaceful":    "joy",
+	"excited":     "joy",
+}
+
+// lowEnergyThreshold is the average energy below which strength verses are chosen
+const lowEnergyThreshold = 4.0
+
+// ThemeForMood picks a verse theme from a user's recent mood trends. Low
+// energy takes priority, otherwise the most frequent emotional state decides.
+// An empty theme means there isn't enough mood data to personalise.
+func ThemeForMood(trends *mood.MoodTrendStats) string {
+	if trends == nil || trends.TotalEntries == 0 {
+		return ""
+	}
+	if trends.AvgEnergyLevel > 0 && trends.AvgEnergyLevel < lowEnergyThreshold {
+		return "strength"
+	}
+
+	var dominant string
+	var dominantCount int
+	for state, count := range trends.EmotionalStates {
+		key := strings.ToLower(strings.TrimSpace(state))
+		// Break ties alphabetically so the theme is stable for the day
+		if count > dominantCount || (count == dominantCount && key < dominant) {
+			dominant, dominantCount = key, count
+		}
+	}
+
+	if theme, ok := moodThemes[dominant]; ok {
+		return theme
+	}
+	return "hope"
+}
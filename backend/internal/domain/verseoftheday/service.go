@@ -0,0 +1,314 @@
+package verseoftheday
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	"armourup/internal/domain/mood"
+	"armourup/internal/scripture"
+
+	"gorm.io/gorm"
+)
+
+//go:embed pool.json
+var defaultPoolJSON []byte
+
+const (
+	// defaultRepeatWindowDays is how long a verse is kept out of rotation after being chosen
+	defaultRepeatWindowDays = 30
+	// moodTrendDays is how far back mood check-ins are considered for personal verses
+	moodTrendDays = 7
+	// globalScope is the user ID used to record the global verse of the day
+	globalScope = 0
+)
+
+type Service struct {
+	repo         *Repository
+	moodService  *mood.Service
+	repeatWindow int
+
+	// cache holds today's verses by scope so repeated requests skip the database
+	mu        sync.Mutex
+	cache     map[uint]*VerseOfTheDay
+	cacheDate time.Time
+}
+
+func NewService(repo *Repository, moodService *mood.Service, repeatWindowDays int) *Service {
+	if repeatWindowDays < 1 {
+		repeatWindowDays = defaultRepeatWindowDays
+	}
+	return &Service{
+		repo:         repo,
+		moodService:  moodService,
+		repeatWindow: repeatWindowDays,
+		cache:        make(map[uint]*VerseOfTheDay),
+	}
+}
+
+// SeedDefaultPool fills an empty verse pool with the verses shipped with the
+// service. A pool that admins have already curated is left untouched.
+func (s *Service) SeedDefaultPool() error {
+	count, err := s.repo.CountPool()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var verses []PoolVerse
+	if err := json.Unmarshal(defaultPoolJSON, &verses); err != nil {
+		return fmt.Errorf("failed to parse default verse pool: %w", err)
+	}
+	return s.repo.CreatePoolVerses(verses)
+}
+
+// GetVerseOfTheDay returns today's verse. The global verse follows the
+// liturgical season; a personal verse is also themed by the user's recent
+// mood trends. The choice is made once per scope per day and then reused.
+func (s *Service) GetVerseOfTheDay(userID uint, personal bool) (*VerseOfTheDay, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	scope := uint(globalScope)
+	if personal {
+		scope = userID
+	}
+
+	if cached := s.getCached(scope, today); cached != nil {
+		return cached, nil
+	}
+
+	daily, err := s.repo.GetDailyVerse(scope, today)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		daily, err = s.chooseDailyVerse(scope, personal, today)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := toVerseOfTheDay(daily, personal)
+	s.setCached(scope, today, result)
+	return result, nil
+}
+
+// chooseDailyVerse picks and records the verse for a scope and day
+func (s *Service) chooseDailyVerse(scope uint, personal bool, today time.Time) (*DailyVerse, error) {
+	pool, err := s.repo.GetActivePool()
+	if err != nil {
+		return nil, err
+	}
+	if len(pool) == 0 {
+		return nil, errors.New("verse pool is empty")
+	}
+
+	recentIDs, err := s.repo.GetRecentVerseIDs(scope, today.AddDate(0, 0, -s.repeatWindow))
+	if err != nil {
+		return nil, err
+	}
+	recent := make(map[uint]bool, len(recentIDs))
+	for _, id := range recentIDs {
+		recent[id] = true
+	}
+
+	season := LiturgicalSeason(today)
+	var theme string
+	if personal && s.moodService != nil {
+		trends, err := s.moodService.GetMoodTrends(scope, moodTrendDays)
+		if err == nil {
+			theme = ThemeForMood(trends)
+		}
+	}
+
+	seed := fmt.Sprintf("%s:%d", today.Format("2006-01-02"), scope)
+	verse := SelectVerse(pool, recent, season, theme, seed)
+
+	daily := &DailyVerse{
+		UserID:  scope,
+		Date:    today,
+		VerseID: verse.ID,
+		Theme:   theme,
+		Season:  season,
+	}
+	if err := s.repo.CreateDailyVerse(daily); err != nil {
+		return nil, err
+	}
+
+	// Re-read so that a concurrent request's choice takes precedence
+	return s.repo.GetDailyVerse(scope, today)
+}
+
+// SelectVerse deterministically picks a verse from the pool for the given
+// seed. Verses chosen within the repeat window are skipped, verses must suit
+// the season, and among those, verses matching the theme (or explicitly
+// written for the season when there is no theme) are preferred. Each rule is
+// relaxed in turn if it would leave nothing to choose from.
+func SelectVerse(pool []PoolVerse, recent map[uint]bool, season, theme, seed string) *PoolVerse {
+	var seasonal, fresh, preferred []PoolVerse
+	for _, v := range pool {
+		if !suitsSeason(v, season) {
+			continue
+		}
+		seasonal = append(seasonal, v)
+		if recent[v.ID] {
+			continue
+		}
+		fresh = append(fresh, v)
+
+		if theme != "" {
+			if hasTag(v.Themes, theme) {
+				preferred = append(preferred, v)
+			}
+		} else if hasTag(v.Seasons, season) {
+			preferred = append(preferred, v)
+		}
+	}
+
+	candidates := preferred
+	if len(candidates) == 0 {
+		candidates = fresh
+	}
+	if len(candidates) == 0 {
+		candidates = seasonal
+	}
+	if len(candidates) == 0 {
+		candidates = pool
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(seed))
+	return &candidates[int(h.Sum32()%uint32(len(candidates)))]
+}
+
+// GetHistory returns the verses chosen for the user (or globally) over the last days
+func (s *Service) GetHistory(userID uint, personal bool, days int) ([]VerseOfTheDay, error) {
+	scope := uint(globalScope)
+	if personal {
+		scope = userID
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -days)
+	history, err := s.repo.GetHistory(scope, since)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]VerseOfTheDay, 0, len(history))
+	for i := range history {
+		result = append(result, *toVerseOfTheDay(&history[i], personal))
+	}
+	return result, nil
+}
+
+// GetPool retrieves every verse in the pool for admins
+func (s *Service) GetPool() ([]PoolVerse, error) {
+	return s.repo.GetPool()
+}
+
+// AddPoolVerse adds a verse to the pool
+func (s *Service) AddPoolVerse(verse *PoolVerse) error {
+	verse.Themes = normaliseTags(verse.Themes)
+	verse.Seasons = normaliseTags(verse.Seasons)
+	return s.repo.CreatePoolVerse(verse)
+}
+
+// UpdatePoolVerse replaces a pool verse's content and tags
+func (s *Service) UpdatePoolVerse(id uint, update *PoolVerse) (*PoolVerse, error) {
+	verse, err := s.repo.GetPoolVerse(id)
+	if err != nil {
+		return nil, errors.New("pool verse not found")
+	}
+
+	verse.Reference = update.Reference
+	verse.Text = update.Text
+	verse.Themes = normaliseTags(update.Themes)
+	verse.Seasons = normaliseTags(update.Seasons)
+	if update.Active != nil {
+		verse.Active = update.Active
+	}
+
+	if err := s.repo.UpdatePoolVerse(verse); err != nil {
+		return nil, err
+	}
+	return verse, nil
+}
+
+// DeletePoolVerse removes a verse from the pool
+func (s *Service) DeletePoolVerse(id uint) error {
+	if _, err := s.repo.GetPoolVerse(id); err != nil {
+		return errors.New("pool verse not found")
+	}
+	return s.repo.DeletePoolVerse(id)
+}
+
+func (s *Service) getCached(scope uint, today time.Time) *VerseOfTheDay {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.cacheDate.Equal(today) {
+		return nil
+	}
+	return s.cache[scope]
+}
+
+func (s *Service) setCached(scope uint, today time.Time, verse *VerseOfTheDay) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.cacheDate.Equal(today) {
+		// A new day has started; yesterday's verses are no longer valid
+		s.cache = make(map[uint]*VerseOfTheDay)
+		s.cacheDate = today
+	}
+	s.cache[scope] = verse
+}
+
+func toVerseOfTheDay(daily *DailyVerse, personal bool) *VerseOfTheDay {
+	result := &VerseOfTheDay{
+		Date:   daily.Date,
+		Scope:  "global",
+		Theme:  daily.Theme,
+		Season: daily.Season,
+	}
+	if personal {
+		result.Scope = "personal"
+	}
+	if daily.Verse != nil {
+		result.Reference = daily.Verse.Reference
+		result.Text = daily.Verse.Text
+		result.Verse = scripture.FormatVerse(daily.Verse.Reference, daily.Verse.Text)
+	}
+	return result
+}
+
+// suitsSeason reports whether a verse may be used in the season. Verses
+// without seasons are suitable all year round.
+func suitsSeason(v PoolVerse, season string) bool {
+	return strings.TrimSpace(v.Seasons) == "" || hasTag(v.Seasons, season)
+}
+
+// hasTag reports whether a comma-separated tag list contains the tag
+func hasTag(tags, tag string) bool {
+	for _, t := range strings.Split(tags, ",") {
+		if strings.EqualFold(strings.TrimSpace(t), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// normaliseTags lowercases and trims a comma-separated tag list
+func normaliseTags(tags string) string {
+	var result []string
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			result = append(result, t)
+		}
+	}
+	return strings.Join(result, ",")
+}
@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultListLimit is how many jobs are listed when no limit is given
+const defaultListLimit = 50
+
+// Controller lets admins inspect the queue and retry dead jobs
+type Controller struct {
+	queue *Queue
+}
+
+func NewController(queue *Queue) *Controller {
+	return &Controller{queue: queue}
+}
+
+// ListJobs lists the most recently updated jobs, optionally filtered with
+// ?status=, e.g. ?status=dead for the dead letters
+func (c *Controller) ListJobs(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultListLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultListLimit
+	}
+
+	jobs, err := c.queue.List(ctx.Query("status"), limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jobs)
+}
+
+// RetryJob puts a dead job back in the queue
+func (c *Controller) RetryJob(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	job, err := c.queue.Retry(uint(id))
+	if err != nil {
+		switch err.Error() {
+		case "job not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "only dead jobs can be retried":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,37 @@
+// Package jobs is a Postgres-backed job queue. Workers claim jobs with
+// FOR UPDATE SKIP LOCKED, so any number of them can share the queue, and
+// failed jobs are retried with backoff until they are dead-lettered.
+package jobs
+
+import (
+	"time"
+)
+
+// Job statuses. A dead job has used up its attempts, or failed permanently,
+// and stays in the queue as a dead letter until an admin retries it.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+// Job is a unit of background work
+type Job struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	Kind   string `json:"kind" gorm:"not null;index"`
+	UserID uint   `json:"user_id" gorm:"index"` // Zero for jobs not run for a user
+	// DedupeKey allows only one queued or running job per key
+	DedupeKey   string     `json:"-" gorm:"uniqueIndex:idx_jobs_active_dedupe_key,where:dedupe_key <> '' AND (status = 'queued' OR status = 'running')"`
+	Payload     string     `json:"-" gorm:"type:text"` // JSON, decoded by the handler
+	Status      string     `json:"status" gorm:"not null;index"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	RunAt       time.Time  `json:"run_at" gorm:"index"` // When the job is next due
+	LockedAt    *time.Time `json:"-"`
+	LastError   string     `json:"last_error,omitempty"`
+	Result      string     `json:"-" gorm:"type:text"` // JSON, set by the handler on success
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,233 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Defaults for retrying failed jobs
+const (
+	DefaultMaxAttempts = 5
+	baseBackoff        = 30 * time.Second
+	maxBackoff         = 30 * time.Minute
+)
+
+// Backoff is how long to wait before retrying a job that has failed
+// attempts times: 30 seconds, doubling each time, up to 30 minutes
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := baseBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// permanentError marks a failure that retrying won't fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the job is dead-lettered without retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Queue stores jobs in Postgres
+type Queue struct {
+	db *gorm.DB
+}
+
+func NewQueue(db *gorm.DB) *Queue {
+	return &Queue{db: db}
+}
+
+// Enqueue adds a job of a kind with a JSON payload, due now. If dedupeKey
+// isn't empty and a job with the same key is already queued or running, that
+// job is returned instead and created is false.
+func (q *Queue) Enqueue(kind string, userID uint, dedupeKey string, payload interface{}) (*Job, bool, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The conflicting job can finish between the insert and the lookup, in
+	// which case the insert is tried once more
+	for attempt := 0; ; attempt++ {
+		job := &Job{
+			Kind:        kind,
+			UserID:      userID,
+			DedupeKey:   dedupeKey,
+			Payload:     string(encoded),
+			Status:      StatusQueued,
+			MaxAttempts: DefaultMaxAttempts,
+			RunAt:       time.Now(),
+		}
+		result := q.db.Clauses(clause.OnConflict{DoNothing: true}).Create(job)
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return job, true, nil
+		}
+
+		var existing Job
+		err = q.db.Where("dedupe_key = ? AND status IN ?", dedupeKey, []string{StatusQueued, StatusRunning}).
+			First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) && attempt == 0 {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return &existing, false, nil
+	}
+}
+
+// Get retrieves a job by ID
+func (q *Queue) Get(id uint) (*Job, error) {
+	var job Job
+	err := q.db.First(&job, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List retrieves the most recent jobs, optionally with a status
+func (q *Queue) List(status string, limit int) ([]Job, error) {
+	var jobs []Job
+	query := q.db.Order("updated_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&jobs).Error
+	return jobs, err
+}
+
+// Claim locks the next due job of one of the kinds for a worker, counting
+// the attempt. It returns nil if there is nothing to do. Jobs locked by other
+// workers are skipped rather than waited for.
+func (q *Queue) Claim(kinds []string) (*Job, error) {
+	now := time.Now()
+	var job Job
+	err := q.db.Raw(`
+		UPDATE jobs SET status = ?, attempts = attempts + 1, locked_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = ? AND kind IN ? AND run_at <= ?
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`,
+		StatusRunning, now, now, StatusQueued, kinds, now,
+	).Scan(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	if job.ID == 0 {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+// Complete marks a job as succeeded with its result
+func (q *Queue) Complete(job *Job, result string) error {
+	now := time.Now()
+	job.Status = StatusSucceeded
+	job.Result = result
+	job.LastError = ""
+	job.LockedAt = nil
+	job.FinishedAt = &now
+	return q.db.Save(job).Error
+}
+
+// Fail records a failed attempt. The job is retried after a backoff unless
+// the error is permanent or it has used up its attempts, in which case it
+// is dead-lettered.
+func (q *Queue) Fail(job *Job, jobErr error) error {
+	now := time.Now()
+	job.LastError = jobErr.Error()
+	job.LockedAt = nil
+	if IsPermanent(jobErr) || job.Attempts >= job.MaxAttempts {
+		job.Status = StatusDead
+		job.FinishedAt = &now
+	} else {
+		job.Status = StatusQueued
+		job.RunAt = now.Add(Backoff(job.Attempts))
+	}
+	return q.db.Save(job).Error
+}
+
+// Retry puts a dead job back in the queue with a fresh set of attempts
+func (q *Queue) Retry(id uint) (*Job, error) {
+	job, err := q.Get(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("job not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != StatusDead {
+		return nil, errors.New("only dead jobs can be retried")
+	}
+
+	job.Status = StatusQueued
+	job.Attempts = 0
+	job.RunAt = time.Now()
+	job.FinishedAt = nil
+	if err := q.db.Save(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// errStale is recorded on jobs whose worker stopped without finishing them
+var errStale = errors.New("worker stopped without finishing the job")
+
+// RequeueStale fails running jobs locked for longer than timeout, as their
+// worker has most likely died. The lost run was counted as an attempt when
+// the job was claimed, so like any other failure the job is retried after a
+// backoff, or dead-lettered once it has used up its attempts. A job that
+// keeps bringing its worker down isn't requeued forever.
+func (q *Queue) RequeueStale(timeout time.Duration) error {
+	return q.db.Transaction(func(tx *gorm.DB) error {
+		var stale []Job
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND locked_at < ?", StatusRunning, time.Now().Add(-timeout)).
+			Find(&stale).Error
+		if err != nil {
+			return err
+		}
+
+		txQueue := &Queue{db: tx}
+		for i := range stale {
+			if err := txQueue.Fail(&stale[i], errStale); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Handler runs a job, returning a JSON result to store on success. Errors
+// are retried unless wrapped with Permanent.
+type Handler func(ctx context.Context, job *Job) (string, error)
+
+// WorkerOptions configures a Worker
+type WorkerOptions struct {
+	// Concurrency is the number of jobs run at once
+	Concurrency int
+	// PollInterval is how long an idle worker waits before checking again
+	PollInterval time.Duration
+	// Timeout limits each attempt, including one still running when the
+	// worker stops. Running jobs locked for twice as long are assumed
+	// abandoned and fail the attempt.
+	Timeout time.Duration
+}
+
+// Worker claims jobs from a queue and runs their handlers
+type Worker struct {
+	queue    *Queue
+	opts     WorkerOptions
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	tasks    []task
+	running  sync.WaitGroup
+}
+
+// task is a function run on a schedule, such as one that queues jobs
+type task struct {
+	interval time.Duration
+	fn       func(ctx context.Context)
+}
+
+func NewWorker(queue *Queue, opts WorkerOptions) *Worker {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Minute
+	}
+	return &Worker{queue: queue, opts: opts, handlers: make(map[string]Handler)}
+}
+
+// Handle registers the handler for a kind of job
+func (w *Worker) Handle(kind string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[kind] = handler
+}
+
+func (w *Worker) kinds() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	kinds := make([]string, 0, len(w.handlers))
+	for kind := range w.handlers {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// Every registers a function to run at an interval while the worker runs.
+// Every instance runs it, so it should be safe to run concurrently, e.g. by
+// queueing jobs with a dedupe key.
+func (w *Worker) Every(interval time.Duration, fn func(ctx context.Context)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tasks = append(w.tasks, task{interval: interval, fn: fn})
+}
+
+// Start runs the worker in the background until ctx is cancelled. Once it
+// is, no more jobs are claimed, but those already running are left to
+// finish; Wait blocks until they have.
+func (w *Worker) Start(ctx context.Context) {
+	for i := 0; i < w.opts.Concurrency; i++ {
+		w.goTracked(func() { w.loop(ctx) })
+	}
+	w.goTracked(func() { w.reap(ctx) })
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, t := range w.tasks {
+		t := t
+		w.goTracked(func() { t.run(ctx) })
+	}
+}
+
+// Wait blocks until a started worker has stopped after its context was
+// cancelled, including any jobs it was running
+func (w *Worker) Wait() {
+	w.running.Wait()
+}
+
+func (w *Worker) goTracked(fn func()) {
+	w.running.Add(1)
+	go func() {
+		defer w.running.Done()
+		fn()
+	}()
+}
+
+func (t task) run(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.fn(ctx)
+		}
+	}
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		ran, err := w.RunOnce(ctx)
+		if err != nil {
+			log.Printf("Warning: job worker: %v", err)
+		}
+		if ran {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.opts.PollInterval):
+		}
+	}
+}
+
+// reap fails the attempts of jobs whose worker stopped without finishing them,
+// requeueing them until they run out
+func (w *Worker) reap(ctx context.Context) {
+	ticker := time.NewTicker(w.opts.Timeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.queue.RequeueStale(2 * w.opts.Timeout); err != nil {
+				log.Printf("Warning: requeueing stale jobs: %v", err)
+			}
+		}
+	}
+}
+
+// RunOnce claims and runs a single job, reporting whether there was one.
+// Nothing is claimed once ctx is cancelled, but a job already claimed runs
+// to the end: cancelling ctx doesn't cancel the handler, only its timeout does.
+func (w *Worker) RunOnce(ctx context.Context) (bool, error) {
+	if ctx.Err() != nil {
+		return false, nil
+	}
+	kinds := w.kinds()
+	if len(kinds) == 0 {
+		return false, nil
+	}
+
+	job, err := w.queue.Claim(kinds)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	w.mu.RLock()
+	handler := w.handlers[job.Kind]
+	w.mu.RUnlock()
+
+	result, err := w.run(ctx, handler, job)
+	if err != nil {
+		return true, w.queue.Fail(job, err)
+	}
+	return true, w.queue.Complete(job, result)
+}
+
+// run calls the handler with a timeout, turning a panic into a failure. The
+// handler keeps ctx's values but not its cancellation, so that shutting down
+// doesn't abandon the job part way.
+func (w *Worker) run(ctx context.Context, handler Handler, job *Job) (result string, err error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.opts.Timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
@@ -0,0 +1,199 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, wrapped in an UnavailableError, while the
+// circuit breaker is failing calls fast
+var ErrCircuitOpen = errors.New("AI provider is temporarily unavailable")
+
+// UnavailableError reports that the provider is down, overloaded or rate
+// limiting, as opposed to rejecting the request itself. RetryAfter is how
+// long the provider asked callers to wait, if it said.
+type UnavailableError struct {
+	Err        error
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *UnavailableError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v (retry after %s)", e.Err, e.RetryAfter.Round(time.Second))
+	}
+	return e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// IsUnavailable reports whether err is, or wraps, an UnavailableError
+func IsUnavailable(err error) bool {
+	var unavailable *UnavailableError
+	return errors.As(err, &unavailable)
+}
+
+// RetryAfter returns how long the provider asked callers to wait, or zero
+func RetryAfter(err error) time.Duration {
+	var unavailable *UnavailableError
+	if errors.As(err, &unavailable) {
+		return unavailable.RetryAfter
+	}
+	return 0
+}
+
+// Circuit breaker states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// BreakerOptions configures a Breaker
+type BreakerOptions struct {
+	// FailureThreshold is how many outages in a row open the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a probe is let
+	// through, unless the provider asked for longer with Retry-After
+	OpenTimeout time.Duration
+}
+
+// Breaker wraps a provider in a circuit breaker. After enough outages in a
+// row, calls fail fast with ErrCircuitOpen instead of waiting on a provider
+// that is down. Once the timeout passes a single probe call is let through:
+// if it succeeds the circuit closes, otherwise it opens again. Only
+// UnavailableErrors count as outages; a rejected request says nothing about
+// the provider's health.
+type Breaker struct {
+	provider Provider
+	opts     BreakerOptions
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openUntil time.Time
+}
+
+// NewBreaker wraps provider in a circuit breaker
+func NewBreaker(provider Provider, opts BreakerOptions) *Breaker {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 30 * time.Second
+	}
+	return &Breaker{provider: provider, opts: opts, state: StateClosed}
+}
+
+// Name returns the wrapped provider's name
+func (b *Breaker) Name() string {
+	return b.provider.Name()
+}
+
+// DefaultModel reports the wrapped provider's default model, if it has one
+func (b *Breaker) DefaultModel() string {
+	if defaulter, ok := b.provider.(DefaultModeler); ok {
+		return defaulter.DefaultModel()
+	}
+	return ""
+}
+
+// State reports whether the circuit is closed, open or half open
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && !time.Now().Before(b.openUntil) {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+func (b *Breaker) Chat(ctx context.Context, req Request) (*Response, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.provider.Chat(ctx, req)
+	b.record(probe, err)
+	return resp, err
+}
+
+// ChatStream counts a stream as a success once it has started
+func (b *Breaker) ChatStream(ctx context.Context, req Request) (Stream, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	stream, err := b.provider.ChatStream(ctx, req)
+	b.record(probe, err)
+	return stream, err
+}
+
+// Embed passes embeddings through the breaker when the wrapped provider
+// supports them
+func (b *Breaker) Embed(ctx context.Context, texts []string) (*Embeddings, error) {
+	embedder, ok := b.provider.(Embedder)
+	if !ok {
+		return nil, ErrEmbeddingsUnsupported
+	}
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	embeddings, err := embedder.Embed(ctx, texts)
+	b.record(probe, err)
+	return embeddings, err
+}
+
+// allow decides whether a call may go ahead, and whether it is the probe
+// that decides if a half-open circuit closes
+func (b *Breaker) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		now := time.Now()
+		if now.Before(b.openUntil) {
+			return false, &UnavailableError{Err: ErrCircuitOpen, RetryAfter: b.openUntil.Sub(now)}
+		}
+		b.state = StateHalfOpen
+		return true, nil
+	case StateHalfOpen:
+		// A probe is already in flight
+		return false, &UnavailableError{Err: ErrCircuitOpen, RetryAfter: time.Second}
+	default:
+		return false, nil
+	}
+}
+
+// record updates the circuit with the outcome of a call
+func (b *Breaker) record(probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var unavailable *UnavailableError
+	switch {
+	case errors.As(err, &unavailable):
+		b.failures++
+		if probe || b.failures >= b.opts.FailureThreshold || unavailable.RetryAfter > 0 {
+			wait := b.opts.OpenTimeout
+			if unavailable.RetryAfter > wait {
+				wait = unavailable.RetryAfter
+			}
+			b.state = StateOpen
+			b.openUntil = time.Now().Add(wait)
+		}
+	case err != nil && probe && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+		// The caller gave up, so the probe proved nothing; let another through
+		b.state = StateOpen
+	default:
+		b.failures = 0
+		b.state = StateClosed
+	}
+}
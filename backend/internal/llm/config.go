@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"fmt"
+	"time"
+
+	"armourup/internal/config"
+
+	"github.com/spf13/viper"
+)
+
+// NewFromConfig creates the provider selected by the llm.provider setting:
+//   - "openai" (default) uses OPENAI_API_KEY and llm.model
+//   - "local" uses an OpenAI-compatible server at llm.base_url with llm.model
+//   - "fake" replays llm.fake_responses, for development and tests
+//
+// Calls to a real provider give up after llm.timeout_seconds.
+// An error means AI features should be disabled.
+func NewFromConfig() (Provider, error) {
+	model := viper.GetString("llm.model")
+	timeout := time.Duration(viper.GetInt("llm.timeout_seconds")) * time.Second
+
+	switch provider := viper.GetString("llm.provider"); provider {
+	case "", "openai":
+		apiKey := config.GetOpenAIAPIKey()
+		if apiKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+		}
+		return NewOpenAI(apiKey, model, timeout), nil
+	case "local":
+		provider, err := NewOpenAICompatible(viper.GetString("llm.base_url"), viper.GetString("llm.api_key"), model, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	case "fake":
+		return NewFake(viper.GetStringSlice("llm.fake_responses")...), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", provider)
+	}
+}
@@ -0,0 +1,200 @@
+package llm
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"math"
+	"strings"
+	"sync"
+)
+
+// FakeResponse is one scripted reply from a Fake provider
+type FakeResponse struct {
+	Content string
+	Err     error
+}
+
+// Fake is a deterministic provider that replays scripted responses in order,
+// repeating the last one once the script runs out. With no script it echoes
+// the last user message. Every request is recorded for inspection.
+type Fake struct {
+	mu       sync.Mutex
+	script   []FakeResponse
+	next     int
+	requests []Request
+}
+
+// NewFake creates a fake that replies with the given contents in order
+func NewFake(responses ...string) *Fake {
+	script := make([]FakeResponse, 0, len(responses))
+	for _, r := range responses {
+		script = append(script, FakeResponse{Content: r})
+	}
+	return NewScriptedFake(script...)
+}
+
+// NewScriptedFake creates a fake whose script can include errors
+func NewScriptedFake(script ...FakeResponse) *Fake {
+	return &Fake{script: script}
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) Chat(ctx context.Context, req Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	reply := f.reply(req)
+	if reply.Err != nil {
+		return nil, reply.Err
+	}
+
+	model := req.Model
+	if model == "" {
+		model = "fake"
+	}
+	prompt := 0
+	for _, m := range req.Messages {
+		prompt += len(strings.Fields(m.Content))
+	}
+	completion := len(strings.Fields(reply.Content))
+
+	return &Response{
+		Content: reply.Content,
+		Model:   model,
+		Usage: Usage{
+			PromptTokens:     prompt,
+			CompletionTokens: completion,
+			TotalTokens:      prompt + completion,
+		},
+	}, nil
+}
+
+// ChatStream streams the scripted reply a word at a time
+func (f *Fake) ChatStream(ctx context.Context, req Request) (Stream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	reply := f.reply(req)
+	if reply.Err != nil {
+		return nil, reply.Err
+	}
+	model := req.Model
+	if model == "" {
+		model = "fake"
+	}
+	return &fakeStream{ctx: ctx, model: model, chunks: splitKeepingSpaces(reply.Content)}, nil
+}
+
+// fakeDimensions is the length of the fake's embedding vectors
+const fakeDimensions = 64
+
+// Embed hashes each text's words into a bag-of-words vector, so that texts
+// sharing most of their words are similar
+func (f *Fake) Embed(ctx context.Context, texts []string) (*Embeddings, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	embeddings := &Embeddings{Model: "fake-embedding"}
+	for _, text := range texts {
+		vector := make([]float32, fakeDimensions)
+		words := strings.Fields(strings.ToLower(text))
+		for _, word := range words {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			vector[h.Sum32()%fakeDimensions]++
+		}
+
+		var norm float64
+		for _, v := range vector {
+			norm += float64(v * v)
+		}
+		if norm > 0 {
+			for i := range vector {
+				vector[i] /= float32(math.Sqrt(norm))
+			}
+		}
+
+		embeddings.Vectors = append(embeddings.Vectors, vector)
+		embeddings.Usage.PromptTokens += len(words)
+	}
+	embeddings.Usage.TotalTokens = embeddings.Usage.PromptTokens
+	return embeddings, nil
+}
+
+// Requests returns every request the fake has received
+func (f *Fake) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
+
+func (f *Fake) reply(req Request) FakeResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, req)
+	if len(f.script) == 0 {
+		for i := len(req.Messages) - 1; i >= 0; i-- {
+			if req.Messages[i].Role == RoleUser {
+				return FakeResponse{Content: req.Messages[i].Content}
+			}
+		}
+		return FakeResponse{}
+	}
+
+	reply := f.script[f.next]
+	if f.next < len(f.script)-1 {
+		f.next++
+	}
+	return reply
+}
+
+// splitKeepingSpaces splits text into words, keeping each word's trailing
+// whitespace so that joining the chunks gives back the original text
+func splitKeepingSpaces(text string) []string {
+	var chunks []string
+	start := 0
+	for i := 1; i < len(text); i++ {
+		if text[i-1] == ' ' && text[i] != ' ' {
+			chunks = append(chunks, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		chunks = append(chunks, text[start:])
+	}
+	return chunks
+}
+
+type fakeStream struct {
+	ctx    context.Context
+	model  string
+	chunks []string
+}
+
+func (s *fakeStream) Recv() (string, error) {
+	if err := s.ctx.Err(); err != nil {
+		return "", err
+	}
+	if len(s.chunks) == 0 {
+		return "", io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func (s *fakeStream) Model() string {
+	return s.model
+}
+
+func (s *fakeStream) Close() error {
+	return nil
+}
@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Caller identifies who a completion is for, so that it can be metered
+type Caller struct {
+	UserID  uint   // Zero for calls not made on behalf of a user
+	Feature string // e.g. "encouragement", "insights" or "companion"
+}
+
+type callerKey struct{}
+
+// WithCaller attaches the user and feature a completion is made for to ctx
+func WithCaller(ctx context.Context, userID uint, feature string) context.Context {
+	return context.WithValue(ctx, callerKey{}, Caller{UserID: userID, Feature: feature})
+}
+
+// CallerFrom returns the caller attached to ctx, if any
+func CallerFrom(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+// Meter decides whether a caller may make a completion and records what
+// each completion consumed
+type Meter interface {
+	// Allow returns an error if the caller is over budget
+	Allow(ctx context.Context, caller Caller) error
+	// Record stores the tokens used by a completion
+	Record(caller Caller, provider, model string, usage Usage)
+}
+
+// EstimateTokens roughly counts the tokens in text, for providers and
+// streams that don't report usage
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// Metered wraps a provider so that every completion is checked against and
+// recorded by a meter
+type Metered struct {
+	provider Provider
+	meter    Meter
+}
+
+// NewMetered wraps provider with meter
+func NewMetered(provider Provider, meter Meter) *Metered {
+	return &Metered{provider: provider, meter: meter}
+}
+
+// Name returns the wrapped provider's name
+func (m *Metered) Name() string {
+	return m.provider.Name()
+}
+
+// Chat checks the caller's budget, then records the usage of the completion
+func (m *Metered) Chat(ctx context.Context, req Request) (*Response, error) {
+	caller := CallerFrom(ctx)
+	if err := m.meter.Allow(ctx, caller); err != nil {
+		return nil, err
+	}
+
+	resp, err := m.provider.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := resp.Usage
+	if usage.TotalTokens == 0 {
+		usage = estimateUsage(req, resp.Content)
+	}
+	m.meter.Record(caller, m.provider.Name(), m.resolveModel(resp.Model, req), usage)
+	return resp, nil
+}
+
+// ChatStream checks the caller's budget, then records an estimate of the
+// stream's usage once it is finished or closed
+func (m *Metered) ChatStream(ctx context.Context, req Request) (Stream, error) {
+	caller := CallerFrom(ctx)
+	if err := m.meter.Allow(ctx, caller); err != nil {
+		return nil, err
+	}
+
+	stream, err := m.provider.ChatStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &meteredStream{
+		Stream: stream,
+		record: func(content string) {
+			var model string
+			if s, ok := stream.(ModelStream); ok {
+				model = s.Model()
+			}
+			m.meter.Record(caller, m.provider.Name(), m.resolveModel(model, req), estimateUsage(req, content))
+		},
+	}, nil
+}
+
+// resolveModel picks the model to record: the one the provider reported, else
+// the one requested, else the provider's default
+func (m *Metered) resolveModel(reported string, req Request) string {
+	if reported != "" {
+		return reported
+	}
+	if req.Model != "" {
+		return req.Model
+	}
+	if defaulter, ok := m.provider.(DefaultModeler); ok {
+		return defaulter.DefaultModel()
+	}
+	return ""
+}
+
+// Embed checks the caller's budget and records the embedding's usage. It
+// returns ErrEmbeddingsUnsupported if the wrapped provider can't embed.
+func (m *Metered) Embed(ctx context.Context, texts []string) (*Embeddings, error) {
+	embedder, ok := m.provider.(Embedder)
+	if !ok {
+		return nil, ErrEmbeddingsUnsupported
+	}
+
+	caller := CallerFrom(ctx)
+	if err := m.meter.Allow(ctx, caller); err != nil {
+		return nil, err
+	}
+
+	embeddings, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := embeddings.Usage
+	if usage.TotalTokens == 0 {
+		for _, text := range texts {
+			usage.PromptTokens += EstimateTokens(text)
+		}
+		usage.TotalTokens = usage.PromptTokens
+	}
+	m.meter.Record(caller, m.provider.Name(), embeddings.Model, usage)
+	return embeddings, nil
+}
+
+// estimateUsage approximates usage from the request and response text
+func estimateUsage(req Request, content string) Usage {
+	prompt := 0
+	for _, message := range req.Messages {
+		prompt += EstimateTokens(message.Content)
+	}
+	completion := EstimateTokens(content)
+	return Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+}
+
+// meteredStream records usage exactly once, whether the stream runs to the
+// end or is abandoned part way
+type meteredStream struct {
+	Stream
+	content strings.Builder
+	once    sync.Once
+	record  func(content string)
+}
+
+func (s *meteredStream) Recv() (string, error) {
+	delta, err := s.Stream.Recv()
+	s.content.WriteString(delta)
+	if err == io.EOF {
+		s.once.Do(func() { s.record(s.content.String()) })
+	}
+	return delta, err
+}
+
+func (s *meteredStream) Close() error {
+	s.once.Do(func() { s.record(s.content.String()) })
+	return s.Stream.Close()
+}
@@ -0,0 +1,272 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// OpenAIProvider talks to the OpenAI API or any server exposing an
+// OpenAI-compatible chat completions endpoint, such as Ollama or llama.cpp
+type OpenAIProvider struct {
+	name         string
+	client       *openai.Client
+	defaultModel string
+	timeout      time.Duration
+}
+
+// NewOpenAI creates a provider for the OpenAI API. Calls other than streams
+// give up after timeout, if it is set.
+func NewOpenAI(apiKey, defaultModel string, timeout time.Duration) *OpenAIProvider {
+	if defaultModel == "" {
+		defaultModel = openai.GPT3Dot5Turbo
+	}
+	return &OpenAIProvider{
+		name:         "openai",
+		client:       openai.NewClientWithConfig(withRetryAfter(openai.DefaultConfig(apiKey))),
+		defaultModel: defaultModel,
+		timeout:      timeout,
+	}
+}
+
+// NewOpenAICompatible creates a provider for a local OpenAI-compatible server,
+// e.g. http://localhost:11434/v1 for Ollama. Most local servers ignore the API key.
+func NewOpenAICompatible(baseURL, apiKey, defaultModel string, timeout time.Duration) (*OpenAIProvider, error) {
+	if baseURL == "" {
+		return nil, errors.New("base URL is required for a local LLM provider")
+	}
+	if defaultModel == "" {
+		return nil, errors.New("model is required for a local LLM provider")
+	}
+
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.BaseURL = strings.TrimSuffix(baseURL, "/")
+	return &OpenAIProvider{
+		name:         "local",
+		client:       openai.NewClientWithConfig(withRetryAfter(cfg)),
+		defaultModel: defaultModel,
+		timeout:      timeout,
+	}, nil
+}
+
+// call prepares the context for a call, with the provider's timeout and
+// somewhere to keep the Retry-After header of a failed response
+func (p *OpenAIProvider) call(ctx context.Context) (context.Context, context.CancelFunc, *time.Duration) {
+	retryAfter := new(time.Duration)
+	ctx = context.WithValue(ctx, retryAfterKey{}, retryAfter)
+	if p.timeout <= 0 {
+		return ctx, func() {}, retryAfter
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	return ctx, cancel, retryAfter
+}
+
+// classify wraps errors that mean the provider is down, overloaded or rate
+// limiting in an UnavailableError. The caller cancelling, or a request the
+// provider rejected, is returned as it is.
+func classify(parent context.Context, err error, retryAfter time.Duration) error {
+	if err == nil || parent.Err() != nil {
+		return err
+	}
+
+	status := 0
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	}
+
+	// No status means the provider couldn't be reached or timed out
+	if status == 0 || status == http.StatusTooManyRequests || status >= 500 {
+		return &UnavailableError{Err: err, StatusCode: status, RetryAfter: retryAfter}
+	}
+	return err
+}
+
+type retryAfterKey struct{}
+
+// withRetryAfter makes the client note the Retry-After header of failed
+// responses, which go-openai doesn't include in its errors
+func withRetryAfter(cfg openai.ClientConfig) openai.ClientConfig {
+	transport := cfg.HTTPClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	cfg.HTTPClient = &http.Client{Transport: retryAfterTransport{base: transport}}
+	return cfg
+}
+
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode >= 400 {
+		if slot, ok := req.Context().Value(retryAfterKey{}).(*time.Duration); ok {
+			*slot = ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+	}
+	return resp, err
+}
+
+// ParseRetryAfter reads a Retry-After header, given either in seconds or as
+// an HTTP date. It returns zero if the header is missing or invalid.
+func ParseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+func (p *OpenAIProvider) Name() string {
+	return p.name
+}
+
+func (p *OpenAIProvider) Chat(ctx context.Context, req Request) (*Response, error) {
+	callCtx, cancel, retryAfter := p.call(ctx)
+	defer cancel()
+
+	resp, err := p.client.CreateChatCompletion(callCtx, p.toOpenAI(req))
+	if err != nil {
+		return nil, classify(ctx, err, *retryAfter)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	return &Response{
+		Content: resp.Choices[0].Message.Content,
+		Model:   resp.Model,
+		Usage: Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// Embed embeds texts with text-embedding-ada-002. OpenAI-compatible local
+// servers aren't supported, as their embedding models can't be named.
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) (*Embeddings, error) {
+	if p.name != "openai" {
+		return nil, ErrEmbeddingsUnsupported
+	}
+
+	callCtx, cancel, retryAfter := p.call(ctx)
+	defer cancel()
+
+	resp, err := p.client.CreateEmbeddings(callCtx, openai.EmbeddingRequestStrings{
+		Input: texts,
+		Model: openai.AdaEmbeddingV2,
+	})
+	if err != nil {
+		return nil, classify(ctx, err, *retryAfter)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, ErrEmptyResponse
+	}
+
+	vectors := make([][]float32, len(resp.Data))
+	for _, d := range resp.Data {
+		vectors[d.Index] = d.Embedding
+	}
+	return &Embeddings{
+		Vectors: vectors,
+		Model:   "text-embedding-ada-002",
+		Usage: Usage{
+			PromptTokens: resp.Usage.PromptTokens,
+			TotalTokens:  resp.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// ChatStream isn't given the provider's timeout, as a long reply can take
+// longer to stream; it ends when ctx does
+func (p *OpenAIProvider) ChatStream(ctx context.Context, req Request) (Stream, error) {
+	retryAfter := new(time.Duration)
+	stream, err := p.client.CreateChatCompletionStream(context.WithValue(ctx, retryAfterKey{}, retryAfter), p.toOpenAI(req))
+	if err != nil {
+		return nil, classify(ctx, err, *retryAfter)
+	}
+	return &openAIStream{stream: stream}, nil
+}
+
+// DefaultModel is the model used when a request names none
+func (p *OpenAIProvider) DefaultModel() string {
+	return p.defaultModel
+}
+
+func (p *OpenAIProvider) toOpenAI(req Request) openai.ChatCompletionRequest {
+	model := req.Model
+	if model == "" {
+		model = p.defaultModel
+	}
+
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, openai.ChatCompletionMessage{Role: m.Role, Content: m.Content})
+	}
+
+	result := openai.ChatCompletionRequest{
+		Model:       model,
+		Messages:    messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+	}
+	if req.JSON {
+		result.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+	return result
+}
+
+type openAIStream struct {
+	stream *openai.ChatCompletionStream
+	model  string
+}
+
+func (s *openAIStream) Recv() (string, error) {
+	for {
+		resp, err := s.stream.Recv()
+		if err != nil {
+			return "", err
+		}
+		if resp.Model != "" {
+			s.model = resp.Model
+		}
+		// Skip chunks that only carry role or finish information
+		if len(resp.Choices) > 0 && resp.Choices[0].Delta.Content != "" {
+			return resp.Choices[0].Delta.Content, nil
+		}
+	}
+}
+
+// Model is the model named by the chunks received so far
+func (s *openAIStream) Model() string {
+	return s.model
+}
+
+func (s *openAIStream) Close() error {
+	s.stream.Close()
+	return nil
+}
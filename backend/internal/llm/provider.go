@@ -0,0 +1,109 @@
+// Package llm provides a provider-agnostic interface to large language models,
+// with implementations for OpenAI, OpenAI-compatible local servers and a
+// scripted fake for tests.
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Message roles
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single turn in a chat
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request describes a chat completion. An empty Model uses the provider's
+// default model. JSON asks the model to reply with a single JSON object.
+type Request struct {
+	Model       string
+	Messages    []Message
+	Temperature float32
+	MaxTokens   int
+	JSON        bool
+}
+
+// Usage reports the tokens consumed by a completion
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Response is a completed chat response
+type Response struct {
+	Content string
+	Model   string
+	Usage   Usage
+}
+
+// Stream yields a response in pieces as the model produces it. Recv returns
+// io.EOF once the response is complete. Close must always be called.
+type Stream interface {
+	Recv() (string, error)
+	Close() error
+}
+
+// ModelStream is implemented by streams that know which model is producing
+// them
+type ModelStream interface {
+	Stream
+	Model() string
+}
+
+// DefaultModeler is implemented by providers that can say which model they
+// use when a request names none
+type DefaultModeler interface {
+	DefaultModel() string
+}
+
+// Provider is a source of chat completions
+type Provider interface {
+	// Name identifies the provider, e.g. "openai", "local" or "fake"
+	Name() string
+	// Chat returns a complete response
+	Chat(ctx context.Context, req Request) (*Response, error)
+	// ChatStream returns the response as a stream of content deltas
+	ChatStream(ctx context.Context, req Request) (Stream, error)
+}
+
+// Embeddings are vectors for a batch of texts, in the same order
+type Embeddings struct {
+	Vectors [][]float32
+	Model   string
+	Usage   Usage
+}
+
+// Embedder is implemented by providers that can embed text for similarity search
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) (*Embeddings, error)
+}
+
+// ErrEmbeddingsUnsupported is returned when a provider can't embed text
+var ErrEmbeddingsUnsupported = errors.New("provider does not support embeddings")
+
+// ReadAll drains a stream and returns the full content
+func ReadAll(stream Stream) (string, error) {
+	defer stream.Close()
+
+	var content string
+	for {
+		delta, err := stream.Recv()
+		if err == io.EOF {
+			return content, nil
+		}
+		if err != nil {
+			return content, err
+		}
+		content += delta
+	}
+}
@@ -0,0 +1,211 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrEmptyResponse is returned by providers when the model sends back no content
+var ErrEmptyResponse = errors.New("model returned no choices")
+
+// Field types supported by Schema
+const (
+	TypeString     = "string"
+	TypeStringList = "string_list"
+)
+
+// Field describes one property of a JSON object response
+type Field struct {
+	Name     string
+	Type     string
+	Required bool
+	// MaxLength limits a string, or each string in a list, in characters
+	MaxLength int
+	// MaxItems limits the length of a list
+	MaxItems int
+}
+
+// Schema describes the JSON object a structured response must be
+type Schema struct {
+	Name   string
+	Fields []Field
+}
+
+// SchemaError reports a response that still didn't match its schema after a
+// repair attempt. It means the model misbehaved, not that it was unreachable.
+type SchemaError struct {
+	Schema   string
+	Problems []string
+	Content  string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("AI response did not match the %s schema: %s", e.Schema, strings.Join(e.Problems, "; "))
+}
+
+// IsSchemaError reports whether err is, or wraps, a SchemaError
+func IsSchemaError(err error) bool {
+	var schemaErr *SchemaError
+	return errors.As(err, &schemaErr)
+}
+
+// JSONSchema renders the schema as a JSON Schema document, for prompts
+func (s Schema) JSONSchema() string {
+	properties := make(map[string]interface{}, len(s.Fields))
+	required := []string{}
+	for _, f := range s.Fields {
+		str := map[string]interface{}{"type": "string"}
+		if f.MaxLength > 0 {
+			str["maxLength"] = f.MaxLength
+		}
+		if f.Type == TypeStringList {
+			list := map[string]interface{}{"type": "array", "items": str}
+			if f.MaxItems > 0 {
+				list["maxItems"] = f.MaxItems
+			}
+			properties[f.Name] = list
+		} else {
+			properties[f.Name] = str
+		}
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+
+	doc, _ := json.Marshal(map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	})
+	return string(doc)
+}
+
+// Validate checks content against the schema. It tolerates a markdown code
+// fence around the JSON, which some models add despite JSON mode, and
+// returns the cleaned JSON along with any problems found.
+func (s Schema) Validate(content string) (string, []string) {
+	cleaned := stripCodeFence(content)
+	if cleaned == "" {
+		return cleaned, []string{"response was empty"}
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(cleaned), &object); err != nil {
+		return cleaned, []string{"response is not a JSON object: " + err.Error()}
+	}
+
+	var problems []string
+	for _, f := range s.Fields {
+		raw, ok := object[f.Name]
+		if !ok || string(raw) == "null" {
+			if f.Required {
+				problems = append(problems, fmt.Sprintf("%q is required", f.Name))
+			}
+			continue
+		}
+
+		switch f.Type {
+		case TypeStringList:
+			var list []string
+			if err := json.Unmarshal(raw, &list); err != nil {
+				problems = append(problems, fmt.Sprintf("%q must be an array of strings", f.Name))
+				continue
+			}
+			if f.Required && len(list) == 0 {
+				problems = append(problems, fmt.Sprintf("%q must not be empty", f.Name))
+			}
+			if f.MaxItems > 0 && len(list) > f.MaxItems {
+				problems = append(problems, fmt.Sprintf("%q must have at most %d items", f.Name, f.MaxItems))
+			}
+			for i, item := range list {
+				if f.MaxLength > 0 && utf8.RuneCountInString(item) > f.MaxLength {
+					problems = append(problems, fmt.Sprintf("%q item %d must be at most %d characters", f.Name, i+1, f.MaxLength))
+				}
+			}
+		default:
+			var str string
+			if err := json.Unmarshal(raw, &str); err != nil {
+				problems = append(problems, fmt.Sprintf("%q must be a string", f.Name))
+				continue
+			}
+			if f.Required && strings.TrimSpace(str) == "" {
+				problems = append(problems, fmt.Sprintf("%q must not be empty", f.Name))
+			}
+			if f.MaxLength > 0 && utf8.RuneCountInString(str) > f.MaxLength {
+				problems = append(problems, fmt.Sprintf("%q must be at most %d characters", f.Name, f.MaxLength))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return cleaned, problems
+}
+
+// stripCodeFence removes a surrounding ```json ... ``` fence
+func stripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	return strings.TrimSpace(content)
+}
+
+// ChatJSON requests a JSON response in JSON mode, validates it against the
+// schema and decodes it into out. An invalid response gets one repair round
+// trip, telling the model what was wrong; if that fails too a SchemaError is
+// returned. Any other error is a failure to reach the provider.
+func ChatJSON(ctx context.Context, provider Provider, req Request, schema Schema, out interface{}) (*Response, error) {
+	req.JSON = true
+
+	resp, err := chatAllowingEmpty(ctx, provider, req)
+	if err != nil {
+		return nil, err
+	}
+	cleaned, problems := schema.Validate(resp.Content)
+
+	if len(problems) > 0 {
+		repair := req
+		repair.Messages = append(append([]Message(nil), req.Messages...),
+			Message{Role: RoleAssistant, Content: resp.Content},
+			Message{Role: RoleUser, Content: repairPrompt(schema, problems)},
+		)
+		resp, err = chatAllowingEmpty(ctx, provider, repair)
+		if err != nil {
+			return nil, err
+		}
+		cleaned, problems = schema.Validate(resp.Content)
+		if len(problems) > 0 {
+			return nil, &SchemaError{Schema: schema.Name, Problems: problems, Content: resp.Content}
+		}
+	}
+
+	if err := json.Unmarshal([]byte(cleaned), out); err != nil {
+		return nil, &SchemaError{Schema: schema.Name, Problems: []string{err.Error()}, Content: resp.Content}
+	}
+	return resp, nil
+}
+
+// chatAllowingEmpty treats an empty response as empty content, so that it
+// can be repaired like any other invalid response
+func chatAllowingEmpty(ctx context.Context, provider Provider, req Request) (*Response, error) {
+	resp, err := provider.Chat(ctx, req)
+	if errors.Is(err, ErrEmptyResponse) {
+		return &Response{Model: req.Model}, nil
+	}
+	return resp, err
+}
+
+func repairPrompt(schema Schema, problems []string) string {
+	return fmt.Sprintf(`Your previous response was invalid:
+- %s
+
+Respond again with ONLY a JSON object, no other text, matching this JSON Schema:
+%s`, strings.Join(problems, "\n- "), schema.JSONSchema())
+}
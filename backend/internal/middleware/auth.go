@@ -85,6 +85,22 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// AdminOnly is a Gin middleware that restricts a route to users with the admin role.
+// It must run after AuthMiddleware, which sets the user's role in the context.
+// Returns 403 Forbidden for authenticated users without the admin role.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("user_role")
+		if role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // GenerateToken creates a new JWT token pair (access token and refresh token) for a user.
 // The access token expires in 24 hours, while the refresh token expires in 7 days.
 // Returns a TokenResponse containing both tokens and their metadata.
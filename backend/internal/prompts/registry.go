@@ -0,0 +1,243 @@
+// Package prompts holds the versioned prompt templates used for AI calls,
+// along with each version's model settings. Templates are embedded in the
+// binary and can be overridden from a directory on disk.
+package prompts
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"armourup/internal/llm"
+)
+
+// manifestFile lists every prompt and its versions
+const manifestFile = "manifest.json"
+
+//go:embed templates
+var embedded embed.FS
+
+// Settings are the model parameters a prompt version is sent with. An empty
+// model uses the provider's default; zero temperature and max tokens leave
+// the provider's defaults in place.
+type Settings struct {
+	Model       string  `json:"model"`
+	Temperature float32 `json:"temperature"`
+	MaxTokens   int     `json:"max_tokens"`
+}
+
+// Version is one version of a prompt. A template defines a "user" template
+// and optionally a "system" template.
+type Version struct {
+	Prompt  string
+	Version string
+	Settings
+	// Weight is the share of users assigned this version; zero takes it out
+	// of assignment
+	Weight int
+
+	tmpl *template.Template
+}
+
+// ID identifies the version, e.g. "insights@v2", and is recorded on what it generates
+func (v *Version) ID() string {
+	return v.Prompt + "@" + v.Version
+}
+
+// Messages renders the version's templates with data
+func (v *Version) Messages(data interface{}) ([]llm.Message, error) {
+	var messages []llm.Message
+	for _, part := range []struct{ name, role string }{{"system", llm.RoleSystem}, {"user", llm.RoleUser}} {
+		if v.tmpl.Lookup(part.name) == nil {
+			continue
+		}
+		var content strings.Builder
+		if err := v.tmpl.ExecuteTemplate(&content, part.name, data); err != nil {
+			return nil, fmt.Errorf("failed to render prompt %s: %w", v.ID(), err)
+		}
+		messages = append(messages, llm.Message{Role: part.role, Content: strings.TrimSpace(content.String())})
+	}
+	return messages, nil
+}
+
+// Request renders the version into a request using its settings
+func (v *Version) Request(data interface{}) (llm.Request, error) {
+	messages, err := v.Messages(data)
+	if err != nil {
+		return llm.Request{}, err
+	}
+	return llm.Request{
+		Model:       v.Model,
+		Messages:    messages,
+		Temperature: v.Temperature,
+		MaxTokens:   v.MaxTokens,
+	}, nil
+}
+
+type prompt struct {
+	defaultVersion string
+	versions       map[string]*Version
+}
+
+// Registry holds every prompt's versions
+type Registry struct {
+	prompts map[string]*prompt
+}
+
+type manifestEntry struct {
+	Default  string `json:"default"`
+	Versions map[string]struct {
+		File string `json:"file"`
+		Settings
+		Weight int `json:"weight"`
+	} `json:"versions"`
+}
+
+// Load reads the embedded prompts. If dir is set, a manifest.json there adds
+// or replaces prompts, and template files there take precedence over the
+// embedded ones with the same name.
+func Load(dir string) (*Registry, error) {
+	base, err := fs.Sub(embedded, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := readManifest(base)
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		overrides, err := readManifest(os.DirFS(dir))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for name, entry := range overrides {
+			manifest[name] = entry
+		}
+	}
+
+	registry := &Registry{prompts: make(map[string]*prompt)}
+	for name, entry := range manifest {
+		p := &prompt{defaultVersion: entry.Default, versions: make(map[string]*Version)}
+		for version, spec := range entry.Versions {
+			text, err := readTemplate(base, dir, spec.File)
+			if err != nil {
+				return nil, fmt.Errorf("prompt %s@%s: %w", name, version, err)
+			}
+			tmpl, err := template.New(spec.File).Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("prompt %s@%s: %w", name, version, err)
+			}
+			if tmpl.Lookup("user") == nil {
+				return nil, fmt.Errorf("prompt %s@%s must define a \"user\" template", name, version)
+			}
+			p.versions[version] = &Version{
+				Prompt:   name,
+				Version:  version,
+				Settings: spec.Settings,
+				Weight:   spec.Weight,
+				tmpl:     tmpl,
+			}
+		}
+		if _, ok := p.versions[p.defaultVersion]; !ok {
+			return nil, fmt.Errorf("prompt %s has no default version %q", name, p.defaultVersion)
+		}
+		registry.prompts[name] = p
+	}
+	return registry, nil
+}
+
+func readManifest(fsys fs.FS) (map[string]manifestEntry, error) {
+	data, err := fs.ReadFile(fsys, manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var manifest map[string]manifestEntry
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+func readTemplate(base fs.FS, dir, file string) (string, error) {
+	if dir != "" {
+		if data, err := os.ReadFile(filepath.Join(dir, file)); err == nil {
+			return string(data), nil
+		}
+	}
+	data, err := fs.ReadFile(base, file)
+	return string(data), err
+}
+
+// Get returns a specific version of a prompt
+func (r *Registry) Get(name, version string) (*Version, error) {
+	p, ok := r.prompts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt %q", name)
+	}
+	v, ok := p.versions[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown version %q of prompt %q", version, name)
+	}
+	return v, nil
+}
+
+// Select assigns a version of a prompt to a user. Each user consistently
+// gets the same version, with versions shared out by weight. Calls not made
+// for a user (userID 0) get the default version.
+func (r *Registry) Select(name string, userID uint) (*Version, error) {
+	p, ok := r.prompts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt %q", name)
+	}
+	return p.versions[Assign(name, userID, p.defaultVersion, p.weights())], nil
+}
+
+func (p *prompt) weights() map[string]int {
+	weights := make(map[string]int, len(p.versions))
+	for name, v := range p.versions {
+		weights[name] = v.Weight
+	}
+	return weights
+}
+
+// Assign picks a version for a user from weighted versions. The choice is a
+// stable hash of the prompt name and user, so it doesn't change between
+// requests, and falls back to the default when no version has weight.
+func Assign(prompt string, userID uint, defaultVersion string, weights map[string]int) string {
+	if userID == 0 {
+		return defaultVersion
+	}
+
+	names := make([]string, 0, len(weights))
+	total := 0
+	for name, weight := range weights {
+		if weight > 0 {
+			names = append(names, name)
+			total += weight
+		}
+	}
+	if total == 0 {
+		return defaultVersion
+	}
+	sort.Strings(names)
+
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s:%d", prompt, userID)
+	point := int(h.Sum32() % uint32(total))
+	for _, name := range names {
+		point -= weights[name]
+		if point < 0 {
+			return name
+		}
+	}
+	return defaultVersion
+}
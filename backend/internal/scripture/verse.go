@@ -0,0 +1,48 @@
+// Package scripture provides helpers for working with Bible verse text and references.
+package scripture
+
+import (
+	"regexp"
+	"strings"
+)
+
+// referencePattern matches references such as "John 3:16", "1 Corinthians 13:4-7"
+// and "Song of Solomon 2:4"
+var referencePattern = regexp.MustCompile(`(?:[1-3]\s?)?[A-Z][a-z]+(?:\s(?:of\s)?[A-Z][a-z]+)*\s\d+:\d+(?:\s?[-–]\s?\d+)?`)
+
+// SplitVerse separates a verse string as produced by the AI or entered by a
+// user (e.g. `"I can do all things..." - Philippians 4:13`) into its reference
+// and text. If no reference can be found the whole string is returned as text.
+func SplitVerse(verse string) (reference, text string) {
+	verse = strings.TrimSpace(verse)
+	loc := referencePattern.FindStringIndex(verse)
+	if loc == nil {
+		return "", verse
+	}
+
+	reference = verse[loc[0]:loc[1]]
+	text = verse[:loc[0]] + " " + verse[loc[1]:]
+	return reference, cleanText(text)
+}
+
+// FormatVerse joins a reference and text into the display form used across the app
+func FormatVerse(reference, text string) string {
+	switch {
+	case reference == "":
+		return text
+	case text == "":
+		return reference
+	default:
+		return `"` + text + `" - ` + reference
+	}
+}
+
+// cleanText strips the separators and quotes left around verse text once the
+// reference has been removed
+func cleanText(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.Trim(text, " -–—:()[]")
+	text = strings.TrimSpace(text)
+	text = strings.Trim(text, `"“”'‘’`)
+	return strings.TrimSpace(text)
+}
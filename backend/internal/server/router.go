@@ -4,7 +4,10 @@ package server
 import (
 	"armourup/internal/domain/user"
 	"armourup/internal/middleware"
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -28,11 +31,40 @@ func NewServer(router *gin.Engine, db *gorm.DB, logger *zap.Logger) *Server {
 	}
 }
 
+// shutdownTimeout is how long requests in flight get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 // Start initializes the server routes and begins listening on the specified address.
-// Returns an error if the server fails to start.
-func (s *Server) Start(addr string) error {
-	SetupRoutes(s.router, s.db, s.logger)
-	return s.router.Run(addr)
+// When ctx is cancelled, background workers stop claiming jobs and the server shuts
+// down gracefully, letting requests in flight finish. Start returns once they have
+// and the workers have finished the jobs they were running.
+// Returns an error if the server fails to start or shut down.
+func (s *Server) Start(ctx context.Context, addr string) error {
+	waitForWorkers := SetupRoutes(ctx, s.router, s.db, s.logger)
+	httpServer := &http.Server{Addr: addr, Handler: s.router}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// Jobs already running are bounded by their own timeout
+	defer waitForWorkers()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 // setupRoutes configures all API routes and their handlers.
@@ -53,8 +85,9 @@ func (s *Server) setupRoutes() {
 	setupAuthRoutes(api, s.db, s.logger)
 
 	// Protected routes
-	setupEncouragementRoutes(api, s.db)
-	setupJournalRoutes(api, s.db)
+	safetyService := newSafetyService(s.db, nil, nil)
+	setupEncouragementRoutes(api, s.db, safetyService)
+	setupJournalRoutes(api, s.db, safetyService)
 	setupPrayerRoutes(api, s.db)
 
 	// User routes
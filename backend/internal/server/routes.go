@@ -2,21 +2,36 @@
 package server
 
 import (
+	"armourup/internal/domain/aicache"
 	"armourup/internal/domain/auth"
+	"armourup/internal/domain/collection"
+	"armourup/internal/domain/companion"
 	"armourup/internal/domain/encouragement"
 	"armourup/internal/domain/gratitude"
 	"armourup/internal/domain/insights"
 	"armourup/internal/domain/journal"
+	"armourup/internal/domain/memory"
 	"armourup/internal/domain/mood"
 	"armourup/internal/domain/openai"
 	"armourup/internal/domain/prayer"
 	"armourup/internal/domain/prayerchain"
+	"armourup/internal/domain/privacy"
+	"armourup/internal/domain/readingplan"
+	"armourup/internal/domain/safety"
+	"armourup/internal/domain/usage"
 	"armourup/internal/domain/user"
+	"armourup/internal/domain/verseoftheday"
+	"armourup/internal/jobs"
+	"armourup/internal/llm"
 	"armourup/internal/middleware"
+	"armourup/internal/prompts"
+	"context"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -49,33 +64,45 @@ func setupAuthRoutes(router *gin.RouterGroup, db *gorm.DB, logger *zap.Logger) {
 	}
 }
 
-// setupEncouragementRoutes configures routes for managing encouragements.
-// Includes CRUD operations and a special endpoint for logging struggles.
+// setupEncouragementRoutes configures routes for the encouragement library and struggle logs.
+// Includes reading and random draws from the curated library, admin-only
+// library management, and logging and reviewing the user's own struggles.
+// Logged struggles are checked for crisis signs.
 // All routes are protected and require authentication.
-func setupEncouragementRoutes(router *gin.RouterGroup, db *gorm.DB) {
+func setupEncouragementRoutes(router *gin.RouterGroup, db *gorm.DB, safetyService *safety.Service) {
 	encRepo := encouragement.NewRepository(db)
 	encService := encouragement.NewService(encRepo)
-	encController := encouragement.NewController(encService)
+	encController := encouragement.NewController(encService, safetyService)
 
 	encGroup := router.Group("/encourage")
 	encGroup.Use(middleware.AuthMiddleware())
 	{
-		encGroup.POST("", encController.CreateEncouragement)
 		encGroup.GET("", encController.GetEncouragements)
-		encGroup.GET("/:id", encController.GetEncouragement)
-		encGroup.PUT("/:id", encController.UpdateEncouragement)
-		encGroup.DELETE("/:id", encController.DeleteEncouragement)
+		encGroup.GET("/library", encController.GetLibrary)
+		encGroup.GET("/library/random", encController.DrawRandom)
 		encGroup.POST("/log-struggle", encController.LogStruggle)
+		encGroup.GET("/struggles", encController.GetStruggleLogs)
+		encGroup.DELETE("/struggles/:id", encController.DeleteStruggleLog)
+		encGroup.GET("/:id", encController.GetEncouragement)
+
+		// Only admins curate the library
+		adminGroup := encGroup.Group("")
+		adminGroup.Use(middleware.AdminOnly())
+		{
+			adminGroup.POST("", encController.CreateEncouragement)
+			adminGroup.PUT("/:id", encController.UpdateEncouragement)
+			adminGroup.DELETE("/:id", encController.DeleteEncouragement)
+		}
 	}
 }
 
 // setupJournalRoutes configures routes for managing journal entries.
-// Includes CRUD operations for journal entries.
+// Includes CRUD operations for journal entries. New entries are checked for crisis signs.
 // All routes are protected and require authentication.
-func setupJournalRoutes(router *gin.RouterGroup, db *gorm.DB) {
+func setupJournalRoutes(router *gin.RouterGroup, db *gorm.DB, safetyService *safety.Service) {
 	journalRepo := journal.NewRepository(db)
 	journalService := journal.NewService(journalRepo)
-	journalController := journal.NewController(journalService)
+	journalController := journal.NewController(journalService, safetyService)
 
 	journalGroup := router.Group("/journal")
 	journalGroup.Use(middleware.AuthMiddleware())
@@ -88,49 +115,138 @@ func setupJournalRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	}
 }
 
-// setupOpenAIRoutes configures routes for OpenAI integration.
-// Includes an endpoint for getting AI-generated encouragements.
+// setupOpenAIRoutes configures routes for AI integration.
+// Includes endpoints for getting AI-generated encouragements, either whole or
+// streamed as Server-Sent Events. When no LLM
+// provider is configured or it fails, encouragements matching the struggle are drawn
+// from the curated library.
+// High-risk input is answered with crisis resources instead.
+// Encouragements are served from the response cache when it is enabled.
 // Routes are protected and include rate limiting (10 requests per minute).
-func setupOpenAIRoutes(router *gin.RouterGroup) {
-	openaiService, err := openai.NewService()
-	if err != nil {
-		log.Printf("Warning: OpenAI integration disabled: %v", err)
-		return
+func setupOpenAIRoutes(router *gin.RouterGroup, db *gorm.DB, provider llm.Provider, registry *prompts.Registry, safetyService *safety.Service, cacheService *aicache.Service, privacyService *privacy.Service) {
+	var openaiService *openai.Service
+	if provider != nil {
+		openaiService = openai.NewService(provider, registry, cacheService, privacyService)
 	}
-	openaiController := openai.NewController(openaiService)
+	library := encouragement.NewService(encouragement.NewRepository(db))
+	openaiController := openai.NewController(openaiService, library, safetyService)
 
 	aiGroup := router.Group("/ai")
 	aiGroup.Use(middleware.AuthMiddleware())
 	aiGroup.Use(middleware.RateLimiter("10-M")) // 10 requests per minute
 	{
 		aiGroup.POST("/encourage", openaiController.GetEncouragement)
+		aiGroup.POST("/encourage/stream", openaiController.StreamEncouragement)
 	}
 }
 
-// setupInsightsRoutes configures routes for progress insights.
-// Includes endpoints for generating and retrieving AI-generated monthly summaries.
-// Routes are protected and include rate limiting (5 requests per minute).
-func setupInsightsRoutes(router *gin.RouterGroup, db *gorm.DB) {
-	openaiService, err := openai.NewService()
-	if err != nil {
-		log.Printf("Warning: Insights feature disabled (OpenAI not configured): %v", err)
+// setupCompanionRoutes configures routes for the spiritual companion chat.
+// Includes creating and managing conversations and sending messages.
+// Routes are protected, rate limited (10 requests per minute) and only
+// available when an LLM provider is configured.
+func setupCompanionRoutes(router *gin.RouterGroup, db *gorm.DB, provider llm.Provider, safetyService *safety.Service, privacyService *privacy.Service) {
+	if provider == nil {
+		log.Printf("Warning: Companion chat disabled (no LLM provider configured)")
 		return
 	}
 
+	companionRepo := companion.NewRepository(db)
+	moodService := mood.NewService(mood.NewRepository(db))
+	journalRepo := journal.NewRepository(db)
+	companionService := companion.NewService(companionRepo, provider, moodService, journalRepo, privacyService, companion.Options{
+		Model:            viper.GetString("companion.model"),
+		MaxContextTokens: viper.GetInt("companion.max_context_tokens"),
+		MaxReplyTokens:   viper.GetInt("companion.max_reply_tokens"),
+	})
+	companionController := companion.NewController(companionService, safetyService)
+
+	conversationGroup := router.Group("/ai/conversations")
+	conversationGroup.Use(middleware.AuthMiddleware())
+	conversationGroup.Use(middleware.RateLimiter("10-M")) // 10 requests per minute
+	{
+		conversationGroup.POST("", companionController.CreateConversation)
+		conversationGroup.GET("", companionController.GetConversations)
+		conversationGroup.POST("/:id/messages", companionController.SendMessage)
+		conversationGroup.GET("/:id", companionController.GetConversation)
+		conversationGroup.PUT("/:id", companionController.UpdateConversation)
+		conversationGroup.DELETE("/:id", companionController.DeleteConversation)
+	}
+}
+
+// setupInsightsRoutes configures routes for progress insights.
+// Includes endpoints for generating, regenerating, rating, comparing, exporting and retrieving summaries of a period,
+// written by AI or, without an LLM provider, from templated rules. Provisional insights are
+// refreshed hourly once their period has ended. Statistics for a period need no AI.
+// Routes are protected and the generation routes include rate limiting (5 requests per minute).
+// Workers run until ctx is cancelled; the worker is returned so that shutdown can wait for its jobs,
+// or nil if this instance runs none. Admins can list the background jobs and retry dead ones.
+func setupInsightsRoutes(ctx context.Context, router *gin.RouterGroup, db *gorm.DB, provider llm.Provider, registry *prompts.Registry, privacyService *privacy.Service) *jobs.Worker {
 	insightsRepo := insights.NewRepository(db)
-	insightsService := insights.NewService(insightsRepo, openaiService.GetClient())
-	insightsController := insights.NewController(insightsService)
+	moodService := mood.NewService(mood.NewRepository(db))
+	queue := jobs.NewQueue(db)
+	insightsService := insights.NewService(insightsRepo, provider, registry, privacyService, moodService, queue, viper.GetString("llm.insights_model"))
+	insightsController := insights.NewController(insightsService, newExporter())
 
 	insightsGroup := router.Group("/insights")
 	insightsGroup.Use(middleware.AuthMiddleware())
+	insightsGroup.GET("/stats", insightsController.GetStats)
+
+	if provider == nil {
+		log.Printf("Warning: AI insights disabled, insights will be rules-based (no LLM provider configured)")
+	}
+
+	// Insights are generated in the background; with no workers here,
+	// another instance must be running them
+	var worker *jobs.Worker
+	if workers := viper.GetInt("jobs.workers"); workers > 0 {
+		worker = jobs.NewWorker(queue, jobs.WorkerOptions{
+			Concurrency:  workers,
+			PollInterval: time.Duration(viper.GetInt("jobs.poll_interval_seconds")) * time.Second,
+			Timeout:      time.Duration(viper.GetInt("jobs.timeout_seconds")) * time.Second,
+		})
+		worker.Handle(insights.JobKind, insightsService.HandleJob)
+		worker.Every(time.Hour, func(ctx context.Context) {
+			if _, err := insightsService.RefreshProvisional(time.Now()); err != nil {
+				log.Printf("Warning: failed to refresh provisional insights: %v", err)
+			}
+		})
+		worker.Start(ctx)
+	}
+
 	insightsGroup.Use(middleware.RateLimiter("5-M")) // 5 requests per minute
 	{
 		insightsGroup.POST("/generate", insightsController.GenerateInsight)
 		insightsGroup.GET("", insightsController.GetUserInsights)
 		insightsGroup.GET("/period", insightsController.GetInsightForPeriod)
 		insightsGroup.GET("/periods", insightsController.GetAvailablePeriods)
+		insightsGroup.GET("/compare", insightsController.Compare)
+		insightsGroup.GET("/jobs/:id", insightsController.GetJob)
 		insightsGroup.GET("/:id", insightsController.GetInsight)
+		insightsGroup.POST("/:id/regenerate", insightsController.RegenerateInsight)
+		insightsGroup.GET("/:id/versions", insightsController.GetVersions)
+		insightsGroup.GET("/:id/export", insightsController.ExportInsight)
+		insightsGroup.POST("/:id/rating", insightsController.RateInsight)
+	}
+
+	// Ratings by prompt version, for deciding which prompts to keep
+	adminGroup := router.Group("/admin/insights")
+	adminGroup.Use(middleware.AuthMiddleware())
+	adminGroup.Use(middleware.AdminOnly())
+	{
+		adminGroup.GET("/ratings", insightsController.GetPromptRatings)
+	}
+
+	// Dead jobs stay in the queue until an admin retries them
+	jobsController := jobs.NewController(queue)
+	jobsGroup := router.Group("/admin/jobs")
+	jobsGroup.Use(middleware.AuthMiddleware())
+	jobsGroup.Use(middleware.AdminOnly())
+	{
+		jobsGroup.GET("", jobsController.ListJobs)
+		jobsGroup.POST("/:id/retry", jobsController.RetryJob)
 	}
+
+	return worker
 }
 
 // setupPrayerRoutes configures routes for managing prayer requests.
@@ -186,12 +302,20 @@ func setupPrayerChainRoutes(router *gin.RouterGroup, db *gorm.DB) {
 }
 
 // setupMoodRoutes configures routes for managing mood tracker entries.
-// Includes CRUD operations, daily check-ins, and trend analysis.
+// Includes CRUD operations, daily check-ins, trend analysis, correlations
+// with spiritual practices, the state vocabulary and admin-only management
+// of its terms.
+// Notes are checked for crisis signs when entries are created or updated.
+// The vocabulary is seeded with default terms when empty.
 // All routes are protected and require authentication.
-func setupMoodRoutes(router *gin.RouterGroup, db *gorm.DB) {
+func setupMoodRoutes(router *gin.RouterGroup, db *gorm.DB, safetyService *safety.Service) {
 	moodRepo := mood.NewRepository(db)
 	moodService := mood.NewService(moodRepo)
-	moodController := mood.NewController(moodService)
+	moodController := mood.NewController(moodService, safetyService)
+
+	if err := moodService.SeedDefaultVocabulary(); err != nil {
+		log.Printf("Warning: failed to seed mood vocabulary: %v", err)
+	}
 
 	moodGroup := router.Group("/mood")
 	moodGroup.Use(middleware.AuthMiddleware())
@@ -202,9 +326,21 @@ func setupMoodRoutes(router *gin.RouterGroup, db *gorm.DB) {
 		moodGroup.GET("/recent", moodController.GetRecentEntries)
 		moodGroup.GET("/range", moodController.GetEntriesInRange)
 		moodGroup.GET("/trends", moodController.GetMoodTrends)
+		moodGroup.GET("/heatmap", moodController.GetHeatmap)
+		moodGroup.GET("/correlations", moodController.GetCorrelations)
+		moodGroup.GET("/vocabulary", moodController.GetVocabulary)
 		moodGroup.GET("/:id", moodController.GetEntry)
 		moodGroup.PUT("/:id", moodController.UpdateEntry)
 		moodGroup.DELETE("/:id", moodController.DeleteEntry)
+
+		termsGroup := moodGroup.Group("/vocabulary/terms")
+		termsGroup.Use(middleware.AdminOnly())
+		{
+			termsGroup.GET("", moodController.GetTerms)
+			termsGroup.POST("", moodController.AddTerm)
+			termsGroup.PUT("/:id", moodController.UpdateTerm)
+			termsGroup.DELETE("/:id", moodController.DeleteTerm)
+		}
 	}
 }
 
@@ -231,6 +367,287 @@ func setupGratitudeRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	}
 }
 
+// setupReadingPlanRoutes configures routes for Bible reading plans.
+// Includes listing plans, enrolment, daily readings, progress and catch-up schedules.
+// Built-in plan definitions are synced to the database when the routes are set up.
+// All routes are protected and require authentication.
+func setupReadingPlanRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	planRepo := readingplan.NewRepository(db)
+	planService := readingplan.NewService(planRepo)
+	planController := readingplan.NewController(planService)
+
+	if err := planService.SyncBuiltinPlans(); err != nil {
+		log.Printf("Warning: failed to sync built-in reading plans: %v", err)
+	}
+
+	planGroup := router.Group("/reading-plans")
+	planGroup.Use(middleware.AuthMiddleware())
+	{
+		planGroup.GET("", planController.GetPlans)
+		planGroup.GET("/my-plans", planController.GetMyPlans)
+		planGroup.POST("/:id/enroll", planController.Enroll)
+		planGroup.DELETE("/:id/enroll", planController.Unenroll)
+		planGroup.GET("/:id/today", planController.GetTodayReading)
+		planGroup.POST("/:id/complete", planController.CompleteReading)
+		planGroup.GET("/:id/progress", planController.GetProgress)
+		planGroup.GET("/:id/catch-up", planController.GetCatchUpSchedule)
+		planGroup.GET("/:id", planController.GetPlan)
+	}
+}
+
+// setupMemoryRoutes configures routes for scripture memorisation.
+// Includes the user's verse deck, spaced-repetition reviews, cloze quizzes,
+// retention stats and memorising a verse straight from an encouragement.
+// All routes are protected and require authentication.
+func setupMemoryRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	memoryRepo := memory.NewRepository(db)
+	encRepo := encouragement.NewRepository(db)
+	memoryService := memory.NewService(memoryRepo, encRepo)
+	memoryController := memory.NewController(memoryService)
+
+	memoryGroup := router.Group("/memory")
+	memoryGroup.Use(middleware.AuthMiddleware())
+	{
+		memoryGroup.POST("", memoryController.AddVerse)
+		memoryGroup.GET("", memoryController.GetDeck)
+		memoryGroup.GET("/due", memoryController.GetDueVerses)
+		memoryGroup.GET("/stats", memoryController.GetStats)
+		memoryGroup.POST("/from-encouragement/:id", memoryController.MemoriseEncouragement)
+		memoryGroup.GET("/:id", memoryController.GetVerse)
+		memoryGroup.GET("/:id/quiz", memoryController.GetQuiz)
+		memoryGroup.POST("/:id/review", memoryController.ReviewVerse)
+		memoryGroup.DELETE("/:id", memoryController.DeleteVerse)
+	}
+}
+
+// setupVerseOfTheDayRoutes configures routes for the verse of the day.
+// Includes the global or mood-personalised verse, verse history, and
+// admin-only management of the curated verse pool.
+// The pool is seeded with default verses when empty.
+// All routes are protected and require authentication.
+func setupVerseOfTheDayRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	votdRepo := verseoftheday.NewRepository(db)
+	moodService := mood.NewService(mood.NewRepository(db))
+	votdService := verseoftheday.NewService(votdRepo, moodService, viper.GetInt("verse_of_the_day.repeat_window_days"))
+	votdController := verseoftheday.NewController(votdService)
+
+	if err := votdService.SeedDefaultPool(); err != nil {
+		log.Printf("Warning: failed to seed verse of the day pool: %v", err)
+	}
+
+	votdGroup := router.Group("/verse-of-the-day")
+	votdGroup.Use(middleware.AuthMiddleware())
+	{
+		votdGroup.GET("", votdController.GetVerseOfTheDay)
+		votdGroup.GET("/history", votdController.GetHistory)
+
+		poolGroup := votdGroup.Group("/pool")
+		poolGroup.Use(middleware.AdminOnly())
+		{
+			poolGroup.GET("", votdController.GetPool)
+			poolGroup.POST("", votdController.AddPoolVerse)
+			poolGroup.PUT("/:id", votdController.UpdatePoolVerse)
+			poolGroup.DELETE("/:id", votdController.DeletePoolVerse)
+		}
+	}
+}
+
+// setupCollectionRoutes configures routes for favourites and collections.
+// Includes favouriting encouragements, AI responses and verses, ordered
+// collections of favourites, and read-only share links.
+// All routes are protected except viewing a shared collection, which is
+// rate limited instead.
+func setupCollectionRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	collectionRepo := collection.NewRepository(db)
+	encRepo := encouragement.NewRepository(db)
+	collectionService := collection.NewService(collectionRepo, encRepo)
+	collectionController := collection.NewController(collectionService)
+
+	favouriteGroup := router.Group("/favourites")
+	favouriteGroup.Use(middleware.AuthMiddleware())
+	{
+		favouriteGroup.POST("", collectionController.AddFavourite)
+		favouriteGroup.GET("", collectionController.GetFavourites)
+		favouriteGroup.DELETE("/:id", collectionController.DeleteFavourite)
+	}
+
+	collectionGroup := router.Group("/collections")
+	collectionGroup.Use(middleware.AuthMiddleware())
+	{
+		collectionGroup.POST("", collectionController.CreateCollection)
+		collectionGroup.GET("", collectionController.GetCollections)
+		collectionGroup.POST("/:id/items", collectionController.AddItem)
+		collectionGroup.PUT("/:id/items/order", collectionController.ReorderItems)
+		collectionGroup.DELETE("/:id/items/:favouriteId", collectionController.RemoveItem)
+		collectionGroup.POST("/:id/share", collectionController.ShareCollection)
+		collectionGroup.DELETE("/:id/share", collectionController.UnshareCollection)
+		collectionGroup.GET("/:id", collectionController.GetCollection)
+		collectionGroup.PUT("/:id", collectionController.UpdateCollection)
+		collectionGroup.DELETE("/:id", collectionController.DeleteCollection)
+	}
+
+	sharedGroup := router.Group("/shared/collections")
+	sharedGroup.Use(middleware.RateLimiter("30-M")) // 30 requests per minute
+	{
+		sharedGroup.GET("/:token", collectionController.GetSharedCollection)
+	}
+}
+
+// newSafetyService creates the safety checks shared by every route that
+// accepts user-authored text. If a custom crisis resources file can't be
+// loaded, the built-in resources are used.
+func newSafetyService(db *gorm.DB, provider llm.Provider, privacyService *privacy.Service) *safety.Service {
+	directory, err := safety.LoadDirectory(viper.GetString("safety.resources_file"))
+	if err != nil {
+		log.Printf("Warning: using built-in crisis resources: %v", err)
+		directory, _ = safety.LoadDirectory("")
+	}
+
+	return safety.NewService(safety.NewRepository(db), provider, privacyService, directory, safety.Options{
+		LLMModeration: viper.GetBool("safety.llm_moderation"),
+		DefaultLocale: viper.GetString("safety.default_locale"),
+	})
+}
+
+// setupSafetyRoutes configures routes for reviewing flagged safety events.
+// All routes are admin-only.
+func setupSafetyRoutes(router *gin.RouterGroup, safetyService *safety.Service) {
+	safetyController := safety.NewController(safetyService)
+
+	safetyGroup := router.Group("/admin/safety-events")
+	safetyGroup.Use(middleware.AuthMiddleware())
+	safetyGroup.Use(middleware.AdminOnly())
+	{
+		safetyGroup.GET("", safetyController.GetEvents)
+		safetyGroup.POST("/:id/review", safetyController.ReviewEvent)
+	}
+}
+
+// loadPrompts loads the prompt templates, with overrides from prompts.dir.
+// If the overrides are invalid, the built-in prompts are used.
+func loadPrompts() *prompts.Registry {
+	registry, err := prompts.Load(viper.GetString("prompts.dir"))
+	if err != nil {
+		log.Printf("Warning: using built-in prompts: %v", err)
+		registry, err = prompts.Load("")
+		if err != nil {
+			log.Fatalf("Built-in prompts are invalid: %v", err)
+		}
+	}
+	return registry
+}
+
+// newExporter creates the insight report exporter, branded from the export
+// settings with template overrides from export.templates_dir. If the
+// overrides are invalid, the built-in templates are used.
+func newExporter() *insights.Exporter {
+	branding := insights.Branding{
+		Name:    viper.GetString("export.brand_name"),
+		Tagline: viper.GetString("export.brand_tagline"),
+		Color:   viper.GetString("export.brand_color"),
+		Footer:  viper.GetString("export.footer"),
+	}
+	exporter, err := insights.NewExporter(branding, viper.GetString("export.templates_dir"))
+	if err != nil {
+		log.Printf("Warning: using built-in report templates: %v", err)
+		exporter, err = insights.NewExporter(branding, "")
+		if err != nil {
+			log.Fatalf("Built-in report templates are invalid: %v", err)
+		}
+	}
+	return exporter
+}
+
+// newUsageService creates the meter for AI usage, with budgets from the
+// ai_usage settings and optional price overrides per model
+func newUsageService(db *gorm.DB) *usage.Service {
+	pricing := usage.Pricing{}
+	for model, price := range usage.DefaultPricing {
+		pricing[model] = price
+	}
+	var overrides usage.Pricing
+	if err := viper.UnmarshalKey("ai_usage.pricing", &overrides); err != nil {
+		log.Printf("Warning: ignoring invalid AI pricing: %v", err)
+	}
+	for model, price := range overrides {
+		pricing[model] = price
+	}
+
+	return usage.NewService(usage.NewRepository(db), usage.Limits{
+		DailyTokens:      viper.GetInt("ai_usage.daily_token_limit"),
+		MonthlyTokens:    viper.GetInt("ai_usage.monthly_token_limit"),
+		GlobalMonthlyUSD: viper.GetFloat64("ai_usage.global_monthly_budget_usd"),
+	}, pricing)
+}
+
+// setupUsageRoutes configures routes for AI usage.
+// Includes the user's token usage and remaining daily and monthly budgets.
+// All routes are protected and require authentication.
+func setupUsageRoutes(router *gin.RouterGroup, usageService *usage.Service) {
+	usageController := usage.NewController(usageService)
+
+	usageGroup := router.Group("/ai/usage")
+	usageGroup.Use(middleware.AuthMiddleware())
+	{
+		usageGroup.GET("", usageController.GetUsage)
+	}
+}
+
+// newCacheService creates the AI response cache from the ai_cache settings,
+// or returns nil when it is disabled or there is no provider to cache
+func newCacheService(db *gorm.DB, provider llm.Provider) *aicache.Service {
+	if provider == nil || !viper.GetBool("ai_cache.enabled") {
+		return nil
+	}
+
+	embedder, _ := provider.(llm.Embedder)
+	return aicache.NewService(aicache.NewRepository(db), embedder, aicache.Options{
+		TTL:                 time.Duration(viper.GetInt("ai_cache.ttl_hours")) * time.Hour,
+		Semantic:            viper.GetBool("ai_cache.semantic"),
+		SimilarityThreshold: viper.GetFloat64("ai_cache.similarity_threshold"),
+		MaxInputLength:      viper.GetInt("ai_cache.max_input_length"),
+	})
+}
+
+// setupAICacheRoutes configures routes for the AI response cache.
+// Includes the user's opt-out preference and hit rate statistics (admin only).
+// Routes are only available when the cache is enabled.
+func setupAICacheRoutes(router *gin.RouterGroup, cacheService *aicache.Service) {
+	if cacheService == nil {
+		return
+	}
+	cacheController := aicache.NewController(cacheService)
+
+	preferenceGroup := router.Group("/ai/cache/preferences")
+	preferenceGroup.Use(middleware.AuthMiddleware())
+	{
+		preferenceGroup.GET("", cacheController.GetPreference)
+		preferenceGroup.PUT("", cacheController.UpdatePreference)
+	}
+
+	statsGroup := router.Group("/admin/ai-cache")
+	statsGroup.Use(middleware.AuthMiddleware())
+	statsGroup.Use(middleware.AdminOnly())
+	{
+		statsGroup.GET("/stats", cacheController.GetStats)
+	}
+}
+
+// setupPrivacyRoutes configures routes for AI privacy controls.
+// Includes the user's data-sharing level and the log of what their AI
+// requests included. All routes are protected and require authentication.
+func setupPrivacyRoutes(router *gin.RouterGroup, privacyService *privacy.Service) {
+	privacyController := privacy.NewController(privacyService)
+
+	privacyGroup := router.Group("/privacy")
+	privacyGroup.Use(middleware.AuthMiddleware())
+	{
+		privacyGroup.GET("/ai-settings", privacyController.GetSettings)
+		privacyGroup.PUT("/ai-settings", privacyController.UpdateSettings)
+		privacyGroup.GET("/ai-audit", privacyController.GetAuditLog)
+	}
+}
+
 // SetupRoutes initializes all API routes and their handlers.
 // This is the main routing configuration function that sets up all route groups:
 // - Health check endpoint
@@ -241,20 +658,74 @@ func setupGratitudeRoutes(router *gin.RouterGroup, db *gorm.DB) {
 // - Prayer wall routes
 // - Prayer chain routes
 // - Mood tracker routes
-// - Progress insights routes (if OpenAI configured)
-// - OpenAI integration routes (if configured)
-func SetupRoutes(router *gin.Engine, db *gorm.DB, logger *zap.Logger) {
+// - Bible reading plan routes
+// - Scripture memorisation routes
+// - Verse of the day routes
+// - Favourites and collections routes
+// - Progress insights generated by background workers (rules-based without an LLM provider), and their statistics; the workers stop when ctx is cancelled
+// - AI integration routes (falling back to the encouragement library)
+// - Companion chat routes (if an LLM provider is configured)
+// - Safety event review routes (admin only)
+// - AI usage routes
+// - AI response cache routes (if the cache is enabled)
+// - AI privacy settings and audit log routes
+//
+// The LLM provider shared by the AI features is selected by the llm.provider setting.
+// Every AI call is metered against per-user token budgets and a global spending ceiling.
+// A circuit breaker fails AI calls fast while the provider is down.
+// Prompts and their model settings come from the versioned prompt templates.
+// User-authored text is checked for crisis signs by a shared safety service.
+// What AI may read of each user's data follows their privacy settings, and
+// personal information is redacted before anything is sent.
+//
+// The returned function blocks, once ctx is cancelled, until the background
+// workers have finished the jobs they were running.
+func SetupRoutes(ctx context.Context, router *gin.Engine, db *gorm.DB, logger *zap.Logger) func() {
+	provider, err := llm.NewFromConfig()
+	if err != nil {
+		log.Printf("Warning: AI features limited, no LLM provider: %v", err)
+	}
+	usageService := newUsageService(db)
+	if provider != nil {
+		breaker := llm.NewBreaker(provider, llm.BreakerOptions{
+			FailureThreshold: viper.GetInt("llm.breaker_failure_threshold"),
+			OpenTimeout:      time.Duration(viper.GetInt("llm.breaker_open_seconds")) * time.Second,
+		})
+		provider = llm.NewMetered(breaker, usageService)
+	}
+
+	privacyService := privacy.NewService(privacy.NewRepository(db), viper.GetString("privacy.default_sharing"))
+	safetyService := newSafetyService(db, provider, privacyService)
+	registry := loadPrompts()
+	cacheService := newCacheService(db, provider)
+
+	var worker *jobs.Worker
 	api := router.Group("/api")
 	{
 		setupHealthRoute(api)
 		setupAuthRoutes(api, db, logger)
-		setupEncouragementRoutes(api, db)
-		setupJournalRoutes(api, db)
+		setupEncouragementRoutes(api, db, safetyService)
+		setupJournalRoutes(api, db, safetyService)
 		setupGratitudeRoutes(api, db)
 		setupPrayerRoutes(api, db)
 		setupPrayerChainRoutes(api, db)
-		setupMoodRoutes(api, db)
-		setupInsightsRoutes(api, db)
-		setupOpenAIRoutes(api)
+		setupMoodRoutes(api, db, safetyService)
+		setupReadingPlanRoutes(api, db)
+		setupMemoryRoutes(api, db)
+		setupVerseOfTheDayRoutes(api, db)
+		setupCollectionRoutes(api, db)
+		worker = setupInsightsRoutes(ctx, api, db, provider, registry, privacyService)
+		setupOpenAIRoutes(api, db, provider, registry, safetyService, cacheService, privacyService)
+		setupCompanionRoutes(api, db, provider, safetyService, privacyService)
+		setupSafetyRoutes(api, safetyService)
+		setupUsageRoutes(api, usageService)
+		setupAICacheRoutes(api, cacheService)
+		setupPrivacyRoutes(api, privacyService)
+	}
+
+	return func() {
+		if worker != nil {
+			worker.Wait()
+		}
 	}
 }
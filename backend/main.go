@@ -3,11 +3,17 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"os"
+	"os/signal"
 	"runtime/debug"
+	"syscall"
 
 	"armourup/internal/config"
 	"armourup/internal/database"
+	"armourup/internal/domain/mood"
 	"armourup/internal/middleware"
 	"armourup/internal/server"
 
@@ -15,6 +21,7 @@ import (
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
 )
 
 // logger is a global instance of the structured logger
@@ -67,8 +74,9 @@ func recoveryMiddleware() gin.HandlerFunc {
 // 2. Loads application configuration
 // 3. Initializes the database connection
 // 4. Runs database migrations if enabled
-// 5. Sets up the HTTP router with middleware
-// 6. Starts the HTTP server
+// 5. Runs the map-moods command instead of the server, if given
+// 6. Sets up the HTTP router with middleware
+// 7. Starts the HTTP server, shutting it down gracefully on SIGINT or SIGTERM once running jobs finish
 func main() {
 	// Initialize logger
 	if err := initLogger(); err != nil {
@@ -96,6 +104,11 @@ func main() {
 		logger.Info("Database migrations completed successfully")
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "map-moods" {
+		mapMoods(db, os.Args[2:])
+		return
+	}
+
 	// Create router
 	router := gin.Default()
 
@@ -114,8 +127,44 @@ func main() {
 		port = "8080"
 	}
 
+	// Stop the server and background workers on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	logger.Info("Starting server", zap.String("port", port))
-	if err := srv.Start(":" + port); err != nil {
+	if err := srv.Start(ctx, ":"+port); err != nil {
 		logger.Fatal("Error starting server", zap.Error(err))
 	}
+	logger.Info("Server stopped")
+}
+
+// mapMoods runs the map-moods command, which rewrites the free-text states on
+// existing mood entries as mood vocabulary keys. With -dry-run it only prints
+// what would change.
+func mapMoods(db *gorm.DB, args []string) {
+	flags := flag.NewFlagSet("map-moods", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "print the mapping without updating entries")
+	flags.Parse(args)
+
+	moodService := mood.NewService(mood.NewRepository(db))
+	if err := moodService.SeedDefaultVocabulary(); err != nil {
+		logger.Fatal("Error seeding mood vocabulary", zap.Error(err))
+	}
+
+	report, err := moodService.MapExistingEntries(*dryRun)
+	if err != nil {
+		logger.Fatal("Error mapping mood states", zap.Error(err))
+	}
+
+	for _, mapping := range report.Mapped {
+		fmt.Printf("%s %q -> %q (%d entries)\n", mapping.Kind, mapping.From, mapping.To, mapping.Entries)
+	}
+	for _, mapping := range report.Unmapped {
+		fmt.Printf("%s %q: no matching term (%d entries)\n", mapping.Kind, mapping.From, mapping.Entries)
+	}
+	logger.Info("Mood states mapped",
+		zap.Bool("dry_run", report.DryRun),
+		zap.Int("mapped", len(report.Mapped)),
+		zap.Int("unmapped", len(report.Unmapped)),
+	)
 }
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_reading_progress_user_completed;
+DROP INDEX IF EXISTS idx_reading_progress_enrollment_day;
+DROP INDEX IF EXISTS idx_reading_plans_deleted_at;
+DROP INDEX IF EXISTS idx_reading_plan_enrollments_deleted_at;
+DROP INDEX IF EXISTS idx_reading_plan_enrollments_user_plan;
+DROP INDEX IF EXISTS idx_reading_plan_days_plan_day;
+DROP TABLE IF EXISTS reading_progress;
+DROP TABLE IF EXISTS reading_plan_enrollments;
+DROP TABLE IF EXISTS reading_plan_days;
+DROP TABLE IF EXISTS reading_plans;
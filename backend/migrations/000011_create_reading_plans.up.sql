@@ -0,0 +1,49 @@
+-- Reading plans and their daily passages. Built-in plans are synced from the
+-- service's plan definitions on startup.
+CREATE TABLE IF NOT EXISTS reading_plans (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    total_days INTEGER NOT NULL CHECK (total_days > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS reading_plan_days (
+    id SERIAL PRIMARY KEY,
+    plan_id INTEGER NOT NULL REFERENCES reading_plans(id) ON DELETE CASCADE,
+    day_number INTEGER NOT NULL CHECK (day_number > 0),
+    passages TEXT NOT NULL
+);
+
+-- A user's enrolment in a plan
+CREATE TABLE IF NOT EXISTS reading_plan_enrollments (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_id INTEGER NOT NULL REFERENCES reading_plans(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    status VARCHAR(20) DEFAULT 'active',
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Completed readings, one row per enrolment and plan day
+CREATE TABLE IF NOT EXISTS reading_progress (
+    id SERIAL PRIMARY KEY,
+    enrollment_id INTEGER NOT NULL REFERENCES reading_plan_enrollments(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_id INTEGER NOT NULL REFERENCES reading_plans(id) ON DELETE CASCADE,
+    day_number INTEGER NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_reading_plan_days_plan_day ON reading_plan_days(plan_id, day_number);
+CREATE UNIQUE INDEX idx_reading_plan_enrollments_user_plan ON reading_plan_enrollments(user_id, plan_id);
+CREATE INDEX idx_reading_plan_enrollments_deleted_at ON reading_plan_enrollments(deleted_at);
+CREATE INDEX idx_reading_plans_deleted_at ON reading_plans(deleted_at);
+CREATE UNIQUE INDEX idx_reading_progress_enrollment_day ON reading_progress(enrollment_id, day_number);
+CREATE INDEX idx_reading_progress_user_completed ON reading_progress(user_id, completed_at);
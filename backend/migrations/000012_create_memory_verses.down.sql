@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_memory_reviews_reviewed_at;
+DROP INDEX IF EXISTS idx_memory_reviews_user_id;
+DROP INDEX IF EXISTS idx_memory_reviews_verse_id;
+DROP INDEX IF EXISTS idx_memory_verses_deleted_at;
+DROP INDEX IF EXISTS idx_memory_verses_due_at;
+DROP INDEX IF EXISTS idx_memory_verses_user_reference;
+DROP TABLE IF EXISTS memory_reviews;
+DROP TABLE IF EXISTS memory_verses;
@@ -0,0 +1,35 @@
+-- Memory verse deck, scheduled with the SM-2 spaced repetition algorithm
+CREATE TABLE IF NOT EXISTS memory_verses (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reference VARCHAR(100) NOT NULL,
+    text TEXT NOT NULL,
+    encouragement_id INTEGER REFERENCES encouragements(id) ON DELETE SET NULL,
+    ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    lapses INTEGER NOT NULL DEFAULT 0,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Review history used for retention statistics
+CREATE TABLE IF NOT EXISTS memory_reviews (
+    id SERIAL PRIMARY KEY,
+    verse_id INTEGER NOT NULL REFERENCES memory_verses(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quality INTEGER NOT NULL CHECK (quality >= 0 AND quality <= 5),
+    interval_days INTEGER NOT NULL,
+    ease_factor DOUBLE PRECISION NOT NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_memory_verses_user_reference ON memory_verses(user_id, reference);
+CREATE INDEX idx_memory_verses_due_at ON memory_verses(due_at);
+CREATE INDEX idx_memory_verses_deleted_at ON memory_verses(deleted_at);
+CREATE INDEX idx_memory_reviews_verse_id ON memory_reviews(verse_id);
+CREATE INDEX idx_memory_reviews_user_id ON memory_reviews(user_id);
+CREATE INDEX idx_memory_reviews_reviewed_at ON memory_reviews(reviewed_at);
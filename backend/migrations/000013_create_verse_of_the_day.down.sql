@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_verse_history_verse_id;
+DROP INDEX IF EXISTS idx_verse_history_user_date;
+DROP INDEX IF EXISTS idx_verse_pool_deleted_at;
+DROP TABLE IF EXISTS verse_of_the_day_history;
+DROP TABLE IF EXISTS verse_pool;
@@ -0,0 +1,28 @@
+-- Admin-curated pool of verses for the verse of the day.
+-- Themes and seasons are comma-separated tags; empty seasons means any season.
+CREATE TABLE IF NOT EXISTS verse_pool (
+    id SERIAL PRIMARY KEY,
+    reference VARCHAR(100) NOT NULL UNIQUE,
+    text TEXT NOT NULL,
+    themes TEXT,
+    seasons TEXT,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- One verse per scope per day; user_id 0 is the global verse
+CREATE TABLE IF NOT EXISTS verse_of_the_day_history (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL DEFAULT 0,
+    date DATE NOT NULL,
+    verse_id INTEGER NOT NULL REFERENCES verse_pool(id),
+    theme VARCHAR(50),
+    season VARCHAR(20),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_verse_pool_deleted_at ON verse_pool(deleted_at);
+CREATE UNIQUE INDEX idx_verse_history_user_date ON verse_of_the_day_history(user_id, date);
+CREATE INDEX idx_verse_history_verse_id ON verse_of_the_day_history(verse_id);
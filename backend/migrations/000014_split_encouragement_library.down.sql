@@ -0,0 +1,23 @@
+DROP INDEX IF EXISTS idx_encouragements_category;
+
+ALTER TABLE encouragements ADD COLUMN user_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE encouragements ADD COLUMN type VARCHAR(100);
+UPDATE encouragements SET user_id = created_by, type = 'library';
+
+-- Library entries without an author cannot satisfy the original schema
+DELETE FROM encouragements WHERE user_id IS NULL;
+
+INSERT INTO encouragements (user_id, message, type, category, verse, created_at, updated_at, deleted_at)
+SELECT user_id, message, 'struggle', COALESCE(category, ''), verse, created_at, updated_at, deleted_at
+FROM struggle_logs;
+
+ALTER TABLE encouragements ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE encouragements ALTER COLUMN type SET NOT NULL;
+ALTER TABLE encouragements DROP COLUMN created_by;
+ALTER TABLE encouragements DROP COLUMN reference;
+ALTER TABLE encouragements DROP COLUMN tags;
+CREATE INDEX idx_encouragements_user_id ON encouragements(user_id);
+
+DROP INDEX IF EXISTS idx_struggle_logs_deleted_at;
+DROP INDEX IF EXISTS idx_struggle_logs_user_id;
+DROP TABLE IF EXISTS struggle_logs;
@@ -0,0 +1,46 @@
+-- Per-user history of struggles logged for encouragement
+CREATE TABLE IF NOT EXISTS struggle_logs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    struggle TEXT,
+    message TEXT NOT NULL,
+    verse TEXT,
+    category VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_struggle_logs_user_id ON struggle_logs(user_id);
+CREATE INDEX idx_struggle_logs_deleted_at ON struggle_logs(deleted_at);
+
+-- Move logged struggles out of the encouragement library
+INSERT INTO struggle_logs (user_id, message, verse, category, created_at, updated_at, deleted_at)
+SELECT user_id, message, verse, NULLIF(category, ''), created_at, updated_at, deleted_at
+FROM encouragements
+WHERE type = 'struggle';
+
+DELETE FROM encouragements WHERE type = 'struggle';
+
+-- The remaining encouragements form the admin-curated library
+ALTER TABLE encouragements ADD COLUMN tags TEXT;
+ALTER TABLE encouragements ADD COLUMN reference VARCHAR(100);
+ALTER TABLE encouragements ADD COLUMN created_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+UPDATE encouragements SET created_by = user_id;
+DROP INDEX IF EXISTS idx_encouragements_user_id;
+ALTER TABLE encouragements DROP COLUMN user_id;
+ALTER TABLE encouragements DROP COLUMN type;
+
+CREATE INDEX idx_encouragements_category ON encouragements(category);
+
+-- Starter library entries for the most common struggles
+INSERT INTO encouragements (message, category, tags, reference, verse)
+VALUES
+('You do not have to carry tomorrow today. Bring each worry to God and let His peace stand guard over your heart.', 'anxiety', 'worry,stress', 'Philippians 4:6-7', 'Do not be anxious about anything, but in every situation, by prayer and petition, with thanksgiving, present your requests to God. And the peace of God, which transcends all understanding, will guard your hearts and your minds in Christ Jesus.'),
+('God is not asking you to be fearless, only to remember that He is with you in what you fear.', 'fear', 'courage', 'Isaiah 41:10', 'So do not fear, for I am with you; do not be dismayed, for I am your God. I will strengthen you and help you; I will uphold you with my righteous right hand.'),
+('Your tears are seen. The Lord draws especially near to the brokenhearted.', 'grief', 'loss,sadness', 'Psalm 34:18', 'The Lord is close to the brokenhearted and saves those who are crushed in spirit.'),
+('Temptation is not the end of the story. There is always a way out, and God will show it to you.', 'temptation', 'sin,addiction', '1 Corinthians 10:13', 'No temptation has overtaken you except what is common to mankind. And God is faithful; he will not let you be tempted beyond what you can bear.'),
+('Even when you feel alone, you are never unaccompanied. He goes before you and stays beside you.', 'loneliness', 'isolation', 'Deuteronomy 31:8', 'The Lord himself goes before you and will be with you; he will never leave you nor forsake you. Do not be afraid; do not be discouraged.'),
+('Honest doubts brought to Jesus are met with grace. Ask Him to help the faith you have.', 'doubt', 'faith', 'Mark 9:24', 'Immediately the boy''s father exclaimed, "I do believe; help me overcome my unbelief!"'),
+('When your strength runs out, His does not. Rest in Him and be renewed.', 'weariness', 'tired,burnout', 'Isaiah 40:31', 'But those who hope in the Lord will renew their strength. They will soar on wings like eagles; they will run and not grow weary, they will walk and not be faint.'),
+('Your failure is not final. His mercies met you again this morning.', 'shame', 'guilt,failure', 'Lamentations 3:22-23', 'Because of the Lord''s great love we are not consumed, for his compassions never fail. They are new every morning; great is your faithfulness.');
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_collection_item;
+DROP INDEX IF EXISTS idx_collections_deleted_at;
+DROP INDEX IF EXISTS idx_collections_user_id;
+DROP INDEX IF EXISTS idx_favourites_deleted_at;
+DROP INDEX IF EXISTS idx_favourites_user_id;
+DROP TABLE IF EXISTS collection_items;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS favourites;
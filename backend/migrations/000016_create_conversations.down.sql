@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_conversation_messages_conversation_id;
+DROP INDEX IF EXISTS idx_conversations_deleted_at;
+DROP INDEX IF EXISTS idx_conversations_user_id;
+DROP TABLE IF EXISTS conversation_messages;
+DROP TABLE IF EXISTS conversations;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_safety_events_risk;
+DROP INDEX IF EXISTS idx_safety_events_user_id;
+DROP TABLE IF EXISTS safety_events;
@@ -0,0 +1,19 @@
+-- Audit trail of user-authored text flagged by the safety checks. Only a hash
+-- of the text is kept.
+CREATE TABLE IF NOT EXISTS safety_events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(30) NOT NULL,
+    risk VARCHAR(10) NOT NULL,
+    categories VARCHAR(255),
+    detector VARCHAR(30),
+    action VARCHAR(30),
+    locale VARCHAR(20),
+    content_hash VARCHAR(64),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_safety_events_user_id ON safety_events(user_id);
+CREATE INDEX idx_safety_events_risk ON safety_events(risk);
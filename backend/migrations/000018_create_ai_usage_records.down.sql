@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ai_usage_records_created_at;
+DROP INDEX IF EXISTS idx_ai_usage_records_user_id;
+DROP TABLE IF EXISTS ai_usage_records;
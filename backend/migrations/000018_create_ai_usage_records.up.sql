@@ -0,0 +1,17 @@
+-- Token usage and cost of each AI call, for per-user budgets and the global
+-- spending ceiling. user_id is 0 for calls not made for a user.
+CREATE TABLE IF NOT EXISTS ai_usage_records (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL DEFAULT 0,
+    feature VARCHAR(30),
+    provider VARCHAR(30),
+    model VARCHAR(100),
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_ai_usage_records_user_id ON ai_usage_records(user_id);
+CREATE INDEX idx_ai_usage_records_created_at ON ai_usage_records(created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE struggle_logs DROP COLUMN IF EXISTS prompt_version;
+ALTER TABLE progress_insights DROP COLUMN IF EXISTS prompt_version;
@@ -0,0 +1,4 @@
+-- Record which prompt template version generated each insight and logged
+-- AI encouragement, e.g. "insights@v1"
+ALTER TABLE progress_insights ADD COLUMN IF NOT EXISTS prompt_version VARCHAR(100);
+ALTER TABLE struggle_logs ADD COLUMN IF NOT EXISTS prompt_version VARCHAR(100);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS ai_cache_preferences;
+DROP INDEX IF EXISTS idx_ai_cache_entries_expires_at;
+DROP INDEX IF EXISTS idx_ai_cache_entries_prompt_version;
+DROP INDEX IF EXISTS idx_ai_cache_entries_cache_key;
+DROP TABLE IF EXISTS ai_cache_entries;
@@ -0,0 +1,26 @@
+-- Cached AI encouragements, keyed on a hash of the prompt version and the
+-- normalised input. embedding is a JSON array, which casts to a pgvector
+-- vector when the extension is installed.
+CREATE TABLE IF NOT EXISTS ai_cache_entries (
+    id SERIAL PRIMARY KEY,
+    cache_key VARCHAR(64) NOT NULL,
+    prompt_version VARCHAR(100),
+    verse TEXT,
+    message TEXT,
+    embedding TEXT,
+    embedding_model VARCHAR(100),
+    hits INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_ai_cache_entries_cache_key ON ai_cache_entries(cache_key);
+CREATE INDEX idx_ai_cache_entries_prompt_version ON ai_cache_entries(prompt_version);
+CREATE INDEX idx_ai_cache_entries_expires_at ON ai_cache_entries(expires_at);
+
+-- Users who have opted out of cached responses
+CREATE TABLE IF NOT EXISTS ai_cache_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    opt_out BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
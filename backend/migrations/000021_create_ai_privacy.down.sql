@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_ai_data_audit_logs_created_at;
+DROP INDEX IF EXISTS idx_ai_data_audit_logs_user_id;
+DROP TABLE IF EXISTS ai_data_audit_logs;
+DROP TABLE IF EXISTS ai_privacy_settings;
@@ -0,0 +1,22 @@
+-- How much of their data each user lets AI features read: 'none',
+-- 'aggregates' or 'full_text'. Users without a row get the configured default.
+CREATE TABLE IF NOT EXISTS ai_privacy_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    sharing VARCHAR(20) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Which records were included in each AI request, e.g.
+-- 'journal_entry:12,mood_entry:5', and how much was redacted
+CREATE TABLE IF NOT EXISTS ai_data_audit_logs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feature VARCHAR(30),
+    sharing VARCHAR(20),
+    records TEXT,
+    redactions INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_ai_data_audit_logs_user_id ON ai_data_audit_logs(user_id);
+CREATE INDEX idx_ai_data_audit_logs_created_at ON ai_data_audit_logs(created_at);
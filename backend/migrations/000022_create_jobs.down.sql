@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_progress_insights_user_period;
+CREATE INDEX idx_progress_insights_user_period ON progress_insights(user_id, period);
+DROP INDEX IF EXISTS idx_jobs_active_dedupe_key;
+DROP INDEX IF EXISTS idx_jobs_run_at;
+DROP INDEX IF EXISTS idx_jobs_status;
+DROP INDEX IF EXISTS idx_jobs_user_id;
+DROP INDEX IF EXISTS idx_jobs_kind;
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,40 @@
+-- Background jobs, claimed by workers with FOR UPDATE SKIP LOCKED. Failed
+-- jobs are requeued with a later run_at until max_attempts, then left as
+-- 'dead' for review.
+CREATE TABLE IF NOT EXISTS jobs (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    user_id INTEGER,
+    dedupe_key VARCHAR(255),
+    payload TEXT,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    result TEXT,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_jobs_kind ON jobs(kind);
+CREATE INDEX idx_jobs_user_id ON jobs(user_id);
+CREATE INDEX idx_jobs_status ON jobs(status);
+CREATE INDEX idx_jobs_run_at ON jobs(run_at);
+
+-- Only one queued or running job per dedupe key
+CREATE UNIQUE INDEX idx_jobs_active_dedupe_key ON jobs(dedupe_key)
+    WHERE dedupe_key <> '' AND (status = 'queued' OR status = 'running');
+
+-- Keep the most recent insight where concurrent generations made duplicates
+UPDATE progress_insights SET deleted_at = CURRENT_TIMESTAMP
+WHERE deleted_at IS NULL AND id NOT IN (
+    SELECT MAX(id) FROM progress_insights WHERE deleted_at IS NULL GROUP BY user_id, period
+);
+
+-- Replaces the plain index from 000010
+DROP INDEX IF EXISTS idx_progress_insights_user_period;
+CREATE UNIQUE INDEX idx_progress_insights_user_period ON progress_insights(user_id, period)
+    WHERE deleted_at IS NULL;
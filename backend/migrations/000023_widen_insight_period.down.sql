@@ -0,0 +1,11 @@
+-- Only months fit the original column
+DELETE FROM progress_insights WHERE length(period) > 7;
+
+-- Keep one insight per period, the live one if there is one
+DELETE FROM progress_insights WHERE id NOT IN (
+    SELECT DISTINCT ON (user_id, period) id FROM progress_insights
+    ORDER BY user_id, period, deleted_at IS NOT NULL, id DESC
+);
+
+ALTER TABLE progress_insights ADD CONSTRAINT progress_insights_user_id_period_key UNIQUE (user_id, period);
+ALTER TABLE progress_insights ALTER COLUMN period TYPE VARCHAR(7);
@@ -0,0 +1,6 @@
+-- Weeks, quarters and custom ranges don't fit in seven characters
+ALTER TABLE progress_insights ALTER COLUMN period TYPE VARCHAR(32);
+
+-- The partial index from 000022 keeps periods unique among live insights;
+-- the original constraint also counted deleted ones, so regenerating failed
+ALTER TABLE progress_insights DROP CONSTRAINT IF EXISTS progress_insights_user_id_period_key;
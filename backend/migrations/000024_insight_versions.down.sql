@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_insight_ratings_prompt_version;
+DROP INDEX IF EXISTS idx_insight_ratings_user_version;
+DROP TABLE IF EXISTS insight_ratings;
+DROP INDEX IF EXISTS idx_insight_versions_insight_version;
+DROP TABLE IF EXISTS progress_insight_versions;
+ALTER TABLE progress_insights DROP COLUMN IF EXISTS provisional;
+ALTER TABLE progress_insights DROP COLUMN IF EXISTS version;
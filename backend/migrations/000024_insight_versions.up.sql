@@ -0,0 +1,41 @@
+ALTER TABLE progress_insights ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE progress_insights ADD COLUMN IF NOT EXISTS provisional BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Monthly insights generated before their month ended get refreshed
+UPDATE progress_insights SET provisional = TRUE
+WHERE deleted_at IS NULL AND period ~ '^\d{4}-\d{2}$'
+    AND created_at < to_date(period, 'YYYY-MM') + INTERVAL '1 month';
+
+-- Earlier versions of regenerated insights
+CREATE TABLE IF NOT EXISTS progress_insight_versions (
+    id SERIAL PRIMARY KEY,
+    insight_id INTEGER NOT NULL REFERENCES progress_insights(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    summary TEXT,
+    highlights TEXT,
+    areas TEXT,
+    verse TEXT,
+    mood_stats TEXT,
+    prompt_version TEXT,
+    provisional BOOLEAN NOT NULL DEFAULT FALSE,
+    generated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_insight_versions_insight_version ON progress_insight_versions(insight_id, version);
+
+-- Whether users found each version of an insight helpful
+CREATE TABLE IF NOT EXISTS insight_ratings (
+    id SERIAL PRIMARY KEY,
+    insight_id INTEGER NOT NULL REFERENCES progress_insights(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    prompt_version TEXT,
+    helpful BOOLEAN NOT NULL,
+    comment VARCHAR(500),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_insight_ratings_user_version ON insight_ratings(insight_id, user_id, version);
+CREATE INDEX idx_insight_ratings_prompt_version ON insight_ratings(prompt_version);
@@ -0,0 +1,32 @@
+ALTER TABLE progress_insight_versions ADD COLUMN IF NOT EXISTS mood_stats TEXT;
+UPDATE progress_insight_versions SET mood_stats = 'Avg Energy: ' || (stats->'energy'->>'average') || '/10' WHERE stats IS NOT NULL;
+ALTER TABLE progress_insight_versions DROP COLUMN IF EXISTS stats;
+ALTER TABLE progress_insights ADD COLUMN IF NOT EXISTS mood_stats TEXT;
+UPDATE progress_insights SET mood_stats = 'Avg Energy: ' || (stats->'energy'->>'average') || '/10' WHERE stats IS NOT NULL;
+ALTER TABLE progress_insights DROP COLUMN IF EXISTS stats;
+
+-- Subqueries aren't allowed in ALTER COLUMN ... USING, so lists are
+-- joined into new columns
+ALTER TABLE progress_insight_versions ADD COLUMN areas_text TEXT;
+UPDATE progress_insight_versions SET areas_text = (SELECT string_agg(item, E'\n') FROM jsonb_array_elements_text(areas) AS item)
+    WHERE jsonb_typeof(areas) = 'array';
+ALTER TABLE progress_insight_versions DROP COLUMN areas;
+ALTER TABLE progress_insight_versions RENAME COLUMN areas_text TO areas;
+
+ALTER TABLE progress_insight_versions ADD COLUMN highlights_text TEXT;
+UPDATE progress_insight_versions SET highlights_text = (SELECT string_agg(item, E'\n') FROM jsonb_array_elements_text(highlights) AS item)
+    WHERE jsonb_typeof(highlights) = 'array';
+ALTER TABLE progress_insight_versions DROP COLUMN highlights;
+ALTER TABLE progress_insight_versions RENAME COLUMN highlights_text TO highlights;
+
+ALTER TABLE progress_insights ADD COLUMN areas_text TEXT;
+UPDATE progress_insights SET areas_text = (SELECT string_agg(item, E'\n') FROM jsonb_array_elements_text(areas) AS item)
+    WHERE jsonb_typeof(areas) = 'array';
+ALTER TABLE progress_insights DROP COLUMN areas;
+ALTER TABLE progress_insights RENAME COLUMN areas_text TO areas;
+
+ALTER TABLE progress_insights ADD COLUMN highlights_text TEXT;
+UPDATE progress_insights SET highlights_text = (SELECT string_agg(item, E'\n') FROM jsonb_array_elements_text(highlights) AS item)
+    WHERE jsonb_typeof(highlights) = 'array';
+ALTER TABLE progress_insights DROP COLUMN highlights;
+ALTER TABLE progress_insights RENAME COLUMN highlights_text TO highlights;
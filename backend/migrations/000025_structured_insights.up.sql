@@ -0,0 +1,20 @@
+-- Highlights and areas for growth become JSON lists. Existing text is split
+-- into one item per line.
+ALTER TABLE progress_insights ALTER COLUMN highlights TYPE JSONB USING
+    CASE WHEN COALESCE(TRIM(highlights), '') = '' THEN '[]'::jsonb
+    ELSE to_jsonb(regexp_split_to_array(TRIM(highlights), E'\\s*\\n\\s*')) END;
+ALTER TABLE progress_insights ALTER COLUMN areas TYPE JSONB USING
+    CASE WHEN COALESCE(TRIM(areas), '') = '' THEN '[]'::jsonb
+    ELSE to_jsonb(regexp_split_to_array(TRIM(areas), E'\\s*\\n\\s*')) END;
+ALTER TABLE progress_insight_versions ALTER COLUMN highlights TYPE JSONB USING
+    CASE WHEN COALESCE(TRIM(highlights), '') = '' THEN '[]'::jsonb
+    ELSE to_jsonb(regexp_split_to_array(TRIM(highlights), E'\\s*\\n\\s*')) END;
+ALTER TABLE progress_insight_versions ALTER COLUMN areas TYPE JSONB USING
+    CASE WHEN COALESCE(TRIM(areas), '') = '' THEN '[]'::jsonb
+    ELSE to_jsonb(regexp_split_to_array(TRIM(areas), E'\\s*\\n\\s*')) END;
+
+-- The "Avg Energy: x/10" line is replaced by statistics computed without AI
+ALTER TABLE progress_insights ADD COLUMN IF NOT EXISTS stats JSONB;
+ALTER TABLE progress_insights DROP COLUMN IF EXISTS mood_stats;
+ALTER TABLE progress_insight_versions ADD COLUMN IF NOT EXISTS stats JSONB;
+ALTER TABLE progress_insight_versions DROP COLUMN IF EXISTS mood_stats;
@@ -0,0 +1,2 @@
+ALTER TABLE progress_insight_versions DROP COLUMN IF EXISTS mode;
+ALTER TABLE progress_insights DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,3 @@
+-- Whether an insight was written by AI or, without an LLM provider, from rules
+ALTER TABLE progress_insights ADD COLUMN IF NOT EXISTS mode VARCHAR(10) NOT NULL DEFAULT 'ai';
+ALTER TABLE progress_insight_versions ADD COLUMN IF NOT EXISTS mode VARCHAR(10) NOT NULL DEFAULT 'ai';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_mood_terms_kind_key;
+DROP INDEX IF EXISTS idx_mood_terms_deleted_at;
+DROP TABLE IF EXISTS mood_terms;
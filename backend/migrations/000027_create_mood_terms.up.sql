@@ -0,0 +1,20 @@
+-- Admin-managed vocabulary of emotional and spiritual states for mood entries.
+-- Labels holds translations by locale and synonyms the other words users may
+-- write for a state. Valence and arousal range from -1 to 1.
+CREATE TABLE IF NOT EXISTS mood_terms (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    key VARCHAR(50) NOT NULL,
+    label TEXT NOT NULL,
+    labels JSONB,
+    synonyms JSONB,
+    valence DOUBLE PRECISION NOT NULL DEFAULT 0,
+    arousal DOUBLE PRECISION NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_mood_terms_deleted_at ON mood_terms(deleted_at);
+CREATE UNIQUE INDEX idx_mood_terms_kind_key ON mood_terms(kind, key) WHERE deleted_at IS NULL;
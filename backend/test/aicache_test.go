@@ -0,0 +1,90 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"armourup/internal/domain/aicache"
+	"armourup/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAICacheNormalise(t *testing.T) {
+	assert.Equal(t, "i'm anxious about exams", aicache.Normalise("  I’m ANXIOUS about   exams!!\n"))
+	assert.Equal(t, "", aicache.Normalise("?!"))
+}
+
+func TestAICacheKey(t *testing.T) {
+	key := aicache.Key("encouragement@v1", aicache.Normalise("Anxious about exams."))
+
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, aicache.Key("encouragement@v1", aicache.Normalise("anxious ABOUT exams")))
+	assert.NotEqual(t, key, aicache.Key("encouragement@v2", aicache.Normalise("anxious about exams")))
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, aicache.CosineSimilarity([]float32{1, 2, 3}, []float32{2, 4, 6}), 1e-9)
+	assert.InDelta(t, 0.0, aicache.CosineSimilarity([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.Zero(t, aicache.CosineSimilarity([]float32{1, 2}, []float32{1, 2, 3}))
+	assert.Zero(t, aicache.CosineSimilarity([]float32{0, 0}, []float32{1, 1}))
+}
+
+func TestFakeEmbeddings(t *testing.T) {
+	embeddings, err := llm.NewFake().Embed(context.Background(), []string{
+		"anxious about my exams",
+		"anxious about exams",
+		"grieving my grandmother",
+	})
+	require.NoError(t, err)
+	require.Len(t, embeddings.Vectors, 3)
+
+	similar := aicache.CosineSimilarity(embeddings.Vectors[0], embeddings.Vectors[1])
+	different := aicache.CosineSimilarity(embeddings.Vectors[0], embeddings.Vectors[2])
+	assert.Greater(t, similar, 0.8)
+	assert.Greater(t, similar, different)
+	assert.Equal(t, 10, embeddings.Usage.TotalTokens)
+}
+
+func TestMeteredEmbed(t *testing.T) {
+	meter := &recordingMeter{}
+	ctx := llm.WithCaller(context.Background(), 9, "encouragement")
+
+	t.Run("Records Usage", func(t *testing.T) {
+		metered := llm.NewMetered(llm.NewFake(), meter)
+		_, err := metered.Embed(ctx, []string{"anxious about exams"})
+		require.NoError(t, err)
+		require.Len(t, meter.models, 1)
+		assert.Equal(t, "fake-embedding", meter.models[0])
+		assert.Equal(t, llm.Usage{PromptTokens: 3, TotalTokens: 3}, meter.usages[0])
+	})
+
+	t.Run("Unsupported Provider", func(t *testing.T) {
+		metered := llm.NewMetered(chatOnlyProvider{}, meter)
+		_, err := metered.Embed(ctx, []string{"anxious about exams"})
+		assert.ErrorIs(t, err, llm.ErrEmbeddingsUnsupported)
+	})
+}
+
+func TestAICacheDisabled(t *testing.T) {
+	var cache *aicache.Service
+
+	entry, miss := cache.Lookup(context.Background(), 1, "encouragement@v1", "anxious about exams")
+	assert.Nil(t, entry)
+	assert.Nil(t, miss)
+	cache.Store(miss, "Psalm 23:1", "He is your shepherd")
+}
+
+// chatOnlyProvider is a provider that can't embed text
+type chatOnlyProvider struct{}
+
+func (chatOnlyProvider) Name() string { return "chat-only" }
+
+func (chatOnlyProvider) Chat(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	return &llm.Response{}, nil
+}
+
+func (chatOnlyProvider) ChatStream(ctx context.Context, req llm.Request) (llm.Stream, error) {
+	return nil, llm.ErrEmbeddingsUnsupported
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -41,7 +42,9 @@ func TestAuthEndpoints(t *testing.T) {
 
 	// Initialize server and set up routes
 	logger := zap.NewNop()
-	server.SetupRoutes(router, db, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.SetupRoutes(ctx, router, db, logger)
 
 	// Test registration
 	t.Run("Registration", func(t *testing.T) {
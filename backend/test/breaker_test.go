@@ -0,0 +1,154 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"armourup/internal/domain/encouragement"
+	"armourup/internal/domain/openai"
+	"armourup/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var outage = llm.FakeResponse{Err: &llm.UnavailableError{Err: errors.New("503 service unavailable"), StatusCode: 503}}
+
+func TestBreakerOpensAfterOutages(t *testing.T) {
+	ctx := context.Background()
+	fake := llm.NewScriptedFake(outage, outage, llm.FakeResponse{Content: "recovered"})
+	breaker := llm.NewBreaker(fake, llm.BreakerOptions{FailureThreshold: 2, OpenTimeout: 20 * time.Millisecond})
+
+	for i := 0; i < 2; i++ {
+		_, err := breaker.Chat(ctx, llm.Request{})
+		assert.True(t, llm.IsUnavailable(err))
+	}
+	assert.Equal(t, llm.StateOpen, breaker.State())
+
+	_, err := breaker.Chat(ctx, llm.Request{})
+	assert.ErrorIs(t, err, llm.ErrCircuitOpen)
+	assert.Greater(t, llm.RetryAfter(err), time.Duration(0))
+	assert.Len(t, fake.Requests(), 2, "an open circuit should fail fast")
+
+	time.Sleep(25 * time.Millisecond)
+	assert.Equal(t, llm.StateHalfOpen, breaker.State())
+
+	resp, err := breaker.Chat(ctx, llm.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, "recovered", resp.Content)
+	assert.Equal(t, llm.StateClosed, breaker.State())
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	ctx := context.Background()
+	fake := llm.NewScriptedFake(outage)
+	breaker := llm.NewBreaker(fake, llm.BreakerOptions{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+
+	_, _ = breaker.Chat(ctx, llm.Request{})
+	time.Sleep(25 * time.Millisecond)
+
+	_, err := breaker.Chat(ctx, llm.Request{})
+	assert.NotErrorIs(t, err, llm.ErrCircuitOpen, "the probe should reach the provider")
+	assert.Equal(t, llm.StateOpen, breaker.State())
+	assert.Len(t, fake.Requests(), 2)
+}
+
+func TestBreakerIgnoresRejectedRequests(t *testing.T) {
+	fake := llm.NewScriptedFake(llm.FakeResponse{Err: errors.New("400 bad request")})
+	breaker := llm.NewBreaker(fake, llm.BreakerOptions{FailureThreshold: 1})
+
+	for i := 0; i < 3; i++ {
+		_, err := breaker.Chat(context.Background(), llm.Request{})
+		assert.EqualError(t, err, "400 bad request")
+	}
+	assert.Equal(t, llm.StateClosed, breaker.State())
+}
+
+func TestBreakerHonoursRetryAfter(t *testing.T) {
+	rateLimited := llm.FakeResponse{Err: &llm.UnavailableError{Err: errors.New("429"), StatusCode: 429, RetryAfter: time.Minute}}
+	breaker := llm.NewBreaker(llm.NewScriptedFake(rateLimited), llm.BreakerOptions{FailureThreshold: 5, OpenTimeout: time.Second})
+
+	_, _ = breaker.Chat(context.Background(), llm.Request{})
+	assert.Equal(t, llm.StateOpen, breaker.State())
+
+	_, err := breaker.Chat(context.Background(), llm.Request{})
+	assert.ErrorIs(t, err, llm.ErrCircuitOpen)
+	assert.Greater(t, llm.RetryAfter(err), 50*time.Second)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, llm.ParseRetryAfter("30", now))
+	assert.Equal(t, 2*time.Minute, llm.ParseRetryAfter("Fri, 01 Mar 2024 12:02:00 GMT", now))
+	assert.Zero(t, llm.ParseRetryAfter("Fri, 01 Mar 2024 11:00:00 GMT", now))
+	assert.Zero(t, llm.ParseRetryAfter("soon", now))
+	assert.Zero(t, llm.ParseRetryAfter("", now))
+}
+
+func TestEncouragementRetriesRespectDeadline(t *testing.T) {
+	fake := llm.NewScriptedFake(outage)
+	service := openai.NewService(fake, builtinPrompts(t), nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := service.GetEncouragement(ctx, "anxious about exams")
+	assert.True(t, llm.IsUnavailable(err))
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "should not sleep past the deadline")
+	assert.Len(t, fake.Requests(), 1)
+}
+
+func TestEncouragementDoesNotRetryRejectedRequests(t *testing.T) {
+	fake := llm.NewScriptedFake(llm.FakeResponse{Err: errors.New("401 unauthorized")})
+	service := openai.NewService(fake, builtinPrompts(t), nil, nil)
+
+	_, err := service.GetEncouragement(context.Background(), "anxious about exams")
+	assert.EqualError(t, err, "401 unauthorized")
+	assert.Len(t, fake.Requests(), 1)
+}
+
+func TestEncouragementSource(t *testing.T) {
+	service := openai.NewService(llm.NewFake(fakeEncouragement), builtinPrompts(t), nil, nil)
+
+	resp, err := service.GetEncouragement(context.Background(), "anxious about exams")
+	require.NoError(t, err)
+	assert.Equal(t, openai.SourceAI, resp.Source)
+}
+
+func TestLibraryBestMatches(t *testing.T) {
+	entries := []encouragement.Encouragement{
+		{ID: 1, Category: "fear", Tags: "anxiety,worry"},
+		{ID: 2, Category: "grief", Tags: "loss,mourning"},
+		{ID: 3, Category: "work", Tags: "exams,study,anxiety"},
+	}
+
+	t.Run("Prefers Most Keywords", func(t *testing.T) {
+		best := encouragement.BestMatches(entries, "I'm anxious about my exams")
+		require.Len(t, best, 1)
+		assert.Equal(t, uint(3), best[0].ID)
+	})
+
+	t.Run("Matches Category", func(t *testing.T) {
+		best := encouragement.BestMatches(entries, "Overwhelmed by grief")
+		require.Len(t, best, 1)
+		assert.Equal(t, uint(2), best[0].ID)
+	})
+
+	t.Run("Ties", func(t *testing.T) {
+		assert.Len(t, encouragement.BestMatches(entries, "so much anxiety"), 2)
+	})
+
+	t.Run("Different Endings", func(t *testing.T) {
+		best := encouragement.BestMatches(entries, "feeling anxious and worried")
+		require.Len(t, best, 1)
+		assert.Equal(t, uint(1), best[0].ID)
+	})
+
+	t.Run("No Match", func(t *testing.T) {
+		assert.Empty(t, encouragement.BestMatches(entries, "hello"))
+	})
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"armourup/internal/domain/collection"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionOrdering(t *testing.T) {
+	items := func() []collection.Item {
+		return []collection.Item{
+			{ID: 1, FavouriteID: 10, Position: 0},
+			{ID: 2, FavouriteID: 20, Position: 1},
+			{ID: 3, FavouriteID: 30, Position: 2},
+		}
+	}
+	favourites := func(items []collection.Item) []uint {
+		var ids []uint
+		for i, item := range items {
+			assert.Equal(t, i, item.Position)
+			ids = append(ids, item.FavouriteID)
+		}
+		return ids
+	}
+
+	t.Run("Insert In Middle", func(t *testing.T) {
+		result := collection.InsertAt(items(), collection.Item{ID: 4, FavouriteID: 40}, 1)
+		assert.Equal(t, []uint{10, 40, 20, 30}, favourites(result))
+	})
+
+	t.Run("Insert Out Of Range Is Clamped", func(t *testing.T) {
+		result := collection.InsertAt(items(), collection.Item{ID: 4, FavouriteID: 40}, 99)
+		assert.Equal(t, []uint{10, 20, 30, 40}, favourites(result))
+
+		result = collection.InsertAt(items(), collection.Item{ID: 4, FavouriteID: 40}, -1)
+		assert.Equal(t, []uint{40, 10, 20, 30}, favourites(result))
+	})
+
+	t.Run("Reorder", func(t *testing.T) {
+		result, err := collection.Reorder(items(), []uint{30, 10, 20})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{30, 10, 20}, favourites(result))
+	})
+
+	t.Run("Reorder Must List Every Item Once", func(t *testing.T) {
+		_, err := collection.Reorder(items(), []uint{30, 10})
+		assert.Error(t, err)
+
+		_, err = collection.Reorder(items(), []uint{30, 30, 10})
+		assert.Error(t, err)
+
+		_, err = collection.Reorder(items(), []uint{30, 10, 99})
+		assert.Error(t, err)
+	})
+}
+
+func TestGenerateShareToken(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := collection.GenerateShareToken()
+		assert.NoError(t, err)
+		assert.Len(t, token, 32)
+		assert.NotContains(t, token, "/")
+		assert.NotContains(t, token, "+")
+		assert.False(t, seen[token])
+		seen[token] = true
+	}
+}
+
+func TestCollectionEndpoints(t *testing.T) {
+	router, db := setupEndpointTest(t, "collection_items", "collections", "favourites")
+	_, ownerToken := createUserToken(t, db, "owner", "user")
+	_, otherToken := createUserToken(t, db, "other", "user")
+
+	w := doRequest(router, "POST", "/api/favourites", ownerToken, map[string]string{
+		"type":      "verse",
+		"reference": "Psalm 46:10",
+		"verse":     "Be still, and know that I am God",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	favouriteID := decodeJSON(t, w)["id"]
+
+	w = doRequest(router, "POST", "/api/collections", ownerToken, map[string]string{"name": "Peace"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	path := fmt.Sprintf("/api/collections/%v", decodeJSON(t, w)["id"])
+
+	w = doRequest(router, "POST", path+"/items", ownerToken, map[string]interface{}{"favourite_id": favouriteID})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	t.Run("Other Users Can't See Or Change It", func(t *testing.T) {
+		w := doRequest(router, "GET", path, otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "PUT", path, otherToken, map[string]string{"name": "Mine now"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "POST", path+"/share", otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "DELETE", path, otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "GET", path, ownerToken, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Peace", decodeJSON(t, w)["name"])
+	})
+
+	var sharedPath string
+	t.Run("Shared Link Is Read Only", func(t *testing.T) {
+		w := doRequest(router, "POST", path+"/share", ownerToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		share := decodeJSON(t, w)
+		require.NotEmpty(t, share["share_token"])
+		sharedPath = share["share_path"].(string)
+
+		// Sharing again keeps the same link
+		w = doRequest(router, "POST", path+"/share", ownerToken, nil)
+		assert.Equal(t, share["share_token"], decodeJSON(t, w)["share_token"])
+
+		// Anyone with the link can read it, without signing in
+		w = doRequest(router, "GET", sharedPath, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		shared := decodeJSON(t, w)
+		assert.Equal(t, "Peace", shared["name"])
+		assert.Equal(t, true, shared["shared"])
+		assert.NotContains(t, shared, "share_token")
+		assert.NotContains(t, w.Body.String(), share["share_token"].(string))
+		items := shared["items"].([]interface{})
+		require.Len(t, items, 1)
+		assert.Equal(t, "Psalm 46:10", items[0].(map[string]interface{})["reference"])
+
+		// There is nothing to write to
+		for _, method := range []string{"POST", "PUT", "DELETE"} {
+			w = doRequest(router, method, sharedPath, otherToken, map[string]string{"name": "Changed"})
+			assert.Equal(t, http.StatusNotFound, w.Code, method)
+		}
+		w = doRequest(router, "GET", path, ownerToken, nil)
+		assert.Equal(t, "Peace", decodeJSON(t, w)["name"])
+	})
+
+	t.Run("Unsharing Revokes The Link", func(t *testing.T) {
+		require.NotEmpty(t, sharedPath)
+
+		w := doRequest(router, "DELETE", path+"/share", otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = doRequest(router, "GET", sharedPath, "", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = doRequest(router, "DELETE", path+"/share", ownerToken, nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doRequest(router, "GET", sharedPath, "", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "GET", path, ownerToken, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		view := decodeJSON(t, w)
+		assert.Equal(t, false, view["shared"])
+		assert.NotContains(t, view, "share_token")
+	})
+}
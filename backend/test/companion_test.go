@@ -0,0 +1,150 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"armourup/internal/domain/companion"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 4, companion.EstimateTokens(""))
+	assert.Equal(t, 5, companion.EstimateTokens("abcd"))
+	assert.Equal(t, 29, companion.EstimateTokens(strings.Repeat("a", 100)))
+}
+
+func TestFitHistory(t *testing.T) {
+	history := []companion.Message{
+		{ID: 1, Role: "user", Tokens: 100},
+		{ID: 2, Role: "assistant", Tokens: 100},
+		{ID: 3, Role: "user", Tokens: 100},
+		{ID: 4, Role: "assistant", Tokens: 100},
+		{ID: 5, Role: "user", Tokens: 50},
+	}
+	ids := func(messages []companion.Message) []uint {
+		var result []uint
+		for _, m := range messages {
+			result = append(result, m.ID)
+		}
+		return result
+	}
+
+	t.Run("Everything Fits", func(t *testing.T) {
+		kept, overflow := companion.FitHistory(history, 1000)
+		assert.Equal(t, []uint{1, 2, 3, 4, 5}, ids(kept))
+		assert.Empty(t, overflow)
+	})
+
+	t.Run("Oldest Turns Overflow", func(t *testing.T) {
+		kept, overflow := companion.FitHistory(history, 260)
+		assert.Equal(t, []uint{3, 4, 5}, ids(kept))
+		assert.Equal(t, []uint{1, 2}, ids(overflow))
+	})
+
+	t.Run("Latest Message Always Kept", func(t *testing.T) {
+		kept, overflow := companion.FitHistory(history, 10)
+		assert.Equal(t, []uint{5}, ids(kept))
+		assert.Len(t, overflow, 4)
+	})
+
+	t.Run("Estimates Missing Token Counts", func(t *testing.T) {
+		messages := []companion.Message{
+			{ID: 1, Content: strings.Repeat("a", 400)},
+			{ID: 2, Content: strings.Repeat("a", 40)},
+		}
+		kept, overflow := companion.FitHistory(messages, 50)
+		assert.Equal(t, []uint{2}, ids(kept))
+		assert.Equal(t, []uint{1}, ids(overflow))
+	})
+
+	t.Run("Empty History", func(t *testing.T) {
+		kept, overflow := companion.FitHistory(nil, 100)
+		assert.Empty(t, kept)
+		assert.Empty(t, overflow)
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", companion.Truncate("short", 10))
+	assert.Equal(t, "abc...", companion.Truncate("abcdef", 3))
+
+	// Multi-byte characters are counted whole and never split
+	truncated := companion.Truncate("Señor, ayúdame 🙏🙏", 16)
+	assert.Equal(t, "Señor, ayúdame 🙏...", truncated)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.Equal(t, "主は私の羊飼い", companion.Truncate("主は私の羊飼い", 7))
+}
+
+func TestCompanionEndpoints(t *testing.T) {
+	useFakeProvider(t, "Peace be with you.")
+	router, db := setupEndpointTest(t, "conversation_messages", "conversations")
+	_, token := createUserToken(t, db, "companion", "user")
+	_, otherToken := createUserToken(t, db, "other", "user")
+
+	w := doRequest(router, "POST", "/api/ai/conversations", token, map[string]interface{}{})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	conversation := decodeJSON(t, w)
+	assert.Equal(t, "New conversation", conversation["title"])
+	assert.Equal(t, false, conversation["grounded"])
+	path := fmt.Sprintf("/api/ai/conversations/%v", conversation["id"])
+
+	t.Run("Send Message", func(t *testing.T) {
+		w := doRequest(router, "POST", path+"/messages", token, map[string]string{"content": "I feel anxious about tomorrow"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		reply := decodeJSON(t, w)
+		assert.Equal(t, "assistant", reply["role"])
+		assert.Equal(t, "Peace be with you.", reply["content"])
+
+		w = doRequest(router, "GET", path, token, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		conversation := decodeJSON(t, w)
+		assert.Equal(t, "I feel anxious about tomorrow", conversation["title"])
+		messages := conversation["messages"].([]interface{})
+		require.Len(t, messages, 2)
+		assert.Equal(t, "user", messages[0].(map[string]interface{})["role"])
+		assert.Equal(t, "assistant", messages[1].(map[string]interface{})["role"])
+	})
+
+	t.Run("Empty Message", func(t *testing.T) {
+		w := doRequest(router, "POST", path+"/messages", token, map[string]string{"content": "   "})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Other Users Can't Access It", func(t *testing.T) {
+		w := doRequest(router, "GET", path, otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "POST", path+"/messages", otherToken, map[string]string{"content": "Hello"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "PUT", path, otherToken, map[string]string{"title": "Mine now"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "DELETE", path, otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "GET", "/api/ai/conversations", otherToken, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, "[]", w.Body.String())
+	})
+
+	t.Run("Update And Delete", func(t *testing.T) {
+		w := doRequest(router, "PUT", path, token, map[string]interface{}{"title": "Tomorrow", "grounded": true})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		conversation := decodeJSON(t, w)
+		assert.Equal(t, "Tomorrow", conversation["title"])
+		assert.Equal(t, true, conversation["grounded"])
+
+		w = doRequest(router, "DELETE", path, token, nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = doRequest(router, "GET", path, token, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
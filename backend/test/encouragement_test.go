@@ -2,6 +2,7 @@ package test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,6 +11,8 @@ import (
 	"testing"
 
 	"armourup/internal/config"
+	"armourup/internal/domain/user"
+	"armourup/internal/middleware"
 	"armourup/internal/server"
 	"armourup/test/testutils"
 
@@ -36,7 +39,9 @@ func TestEncouragementEndpoints(t *testing.T) {
 
 	// Initialize server and set up routes
 	logger := zap.NewNop()
-	server.SetupRoutes(router, db, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.SetupRoutes(ctx, router, db, logger)
 
 	// First create a test user
 	registerData := map[string]string{
@@ -56,11 +61,18 @@ func TestEncouragementEndpoints(t *testing.T) {
 	assert.NoError(t, err)
 	token := registerResponse["access_token"].(string)
 
+	// Only admins can curate the library
+	var testUser user.User
+	err = db.Where("email = ?", registerData["email"]).First(&testUser).Error
+	assert.NoError(t, err)
+	adminTokens, err := middleware.GenerateToken(testUser.ID, testUser.Email, "admin")
+	assert.NoError(t, err)
+	adminToken := adminTokens.AccessToken
+
 	// Test create encouragement
 	t.Run("Create Encouragement", func(t *testing.T) {
 		encouragementData := map[string]string{
 			"message":  "You're doing great!",
-			"type":     "motivational",
 			"category": "general",
 		}
 		jsonData, _ := json.Marshal(encouragementData)
@@ -68,7 +80,7 @@ func TestEncouragementEndpoints(t *testing.T) {
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("POST", "/api/encourage", bytes.NewBuffer(jsonData))
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusCreated, w.Code)
@@ -77,7 +89,6 @@ func TestEncouragementEndpoints(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotEmpty(t, response["id"])
 		assert.Equal(t, encouragementData["message"], response["message"])
-		assert.Equal(t, encouragementData["type"], response["type"])
 		assert.Equal(t, encouragementData["category"], response["category"])
 	})
 
@@ -100,11 +111,10 @@ func TestEncouragementEndpoints(t *testing.T) {
 		// Create an encouragement first
 		createReq := httptest.NewRequest("POST", "/api/encourage", strings.NewReader(`{
 			"message": "Test encouragement",
-			"type": "test",
 			"category": "test"
 		}`))
 		createReq.Header.Set("Content-Type", "application/json")
-		createReq.Header.Set("Authorization", "Bearer "+token)
+		createReq.Header.Set("Authorization", "Bearer "+adminToken)
 		createRec := httptest.NewRecorder()
 		router.ServeHTTP(createRec, createReq)
 		assert.Equal(t, http.StatusCreated, createRec.Code)
@@ -125,7 +135,6 @@ func TestEncouragementEndpoints(t *testing.T) {
 		err = json.Unmarshal(getRec.Body.Bytes(), &getResponse)
 		assert.NoError(t, err)
 		assert.Equal(t, "Test encouragement", getResponse["message"])
-		assert.Equal(t, "test", getResponse["type"])
 		assert.Equal(t, "test", getResponse["category"])
 		assert.Equal(t, encouragementID, fmt.Sprintf("%.0f", getResponse["id"].(float64)))
 	})
@@ -135,11 +144,10 @@ func TestEncouragementEndpoints(t *testing.T) {
 		// Create an encouragement first
 		createReq := httptest.NewRequest("POST", "/api/encourage", strings.NewReader(`{
 			"message": "Original message",
-			"type": "original",
 			"category": "test"
 		}`))
 		createReq.Header.Set("Content-Type", "application/json")
-		createReq.Header.Set("Authorization", "Bearer "+token)
+		createReq.Header.Set("Authorization", "Bearer "+adminToken)
 		createRec := httptest.NewRecorder()
 		router.ServeHTTP(createRec, createReq)
 		assert.Equal(t, http.StatusCreated, createRec.Code)
@@ -152,11 +160,10 @@ func TestEncouragementEndpoints(t *testing.T) {
 		encouragementID := fmt.Sprintf("%.0f", createResponse["id"].(float64))
 		updateReq := httptest.NewRequest("PUT", "/api/encourage/"+encouragementID, strings.NewReader(`{
 			"message": "Updated message",
-			"type": "updated",
 			"category": "test"
 		}`))
 		updateReq.Header.Set("Content-Type", "application/json")
-		updateReq.Header.Set("Authorization", "Bearer "+token)
+		updateReq.Header.Set("Authorization", "Bearer "+adminToken)
 		updateRec := httptest.NewRecorder()
 		router.ServeHTTP(updateRec, updateReq)
 		assert.Equal(t, http.StatusOK, updateRec.Code)
@@ -165,7 +172,6 @@ func TestEncouragementEndpoints(t *testing.T) {
 		err = json.Unmarshal(updateRec.Body.Bytes(), &updateResponse)
 		assert.NoError(t, err)
 		assert.Equal(t, "Updated message", updateResponse["message"])
-		assert.Equal(t, "updated", updateResponse["type"])
 		assert.Equal(t, "test", updateResponse["category"])
 		assert.Equal(t, encouragementID, fmt.Sprintf("%.0f", updateResponse["id"].(float64)))
 	})
@@ -175,7 +181,6 @@ func TestEncouragementEndpoints(t *testing.T) {
 		// First create an encouragement
 		encouragementData := map[string]string{
 			"message":  "To be deleted",
-			"type":     "temporary",
 			"category": "test",
 		}
 		jsonData, _ := json.Marshal(encouragementData)
@@ -183,7 +188,7 @@ func TestEncouragementEndpoints(t *testing.T) {
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("POST", "/api/encourage", bytes.NewBuffer(jsonData))
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
 		router.ServeHTTP(w, req)
 
 		var createResponse map[string]interface{}
@@ -194,7 +199,7 @@ func TestEncouragementEndpoints(t *testing.T) {
 		// Now delete it
 		w = httptest.NewRecorder()
 		req, _ = http.NewRequest("DELETE", "/api/encourage/"+encouragementID, nil)
-		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusNoContent, w.Code)
@@ -207,4 +212,111 @@ func TestEncouragementEndpoints(t *testing.T) {
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
+
+	// Test that regular users cannot change the library
+	t.Run("Create Encouragement Requires Admin", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/encourage", strings.NewReader(`{
+			"message": "Not allowed",
+			"category": "test"
+		}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	// Test library filtering and random draws by category
+	t.Run("Library By Category", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/encourage", strings.NewReader(`{
+			"message": "Peace be with you",
+			"category": "Anxiety",
+			"tags": "Worry, stress",
+			"verse": "\"Cast all your anxiety on him because he cares for you.\" - 1 Peter 5:7"
+		}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var created map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &created)
+		assert.NoError(t, err)
+		assert.Equal(t, "anxiety", created["category"])
+		assert.Equal(t, "worry,stress", created["tags"])
+		assert.Equal(t, "1 Peter 5:7", created["reference"])
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/encourage/library?category=anxiety", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var library []map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &library)
+		assert.NoError(t, err)
+		assert.Greater(t, len(library), 0)
+		for _, entry := range library {
+			assert.Equal(t, "anxiety", entry["category"])
+		}
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/encourage/library/random?category=anxiety", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var drawn map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &drawn)
+		assert.NoError(t, err)
+		assert.Equal(t, "anxiety", drawn["category"])
+
+		// Categories without entries aren't swapped for another
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/encourage/library/random?category=no-such-category", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "no encouragements in this category")
+	})
+
+	// Test struggles are kept in the user's own history, not the library
+	t.Run("Log Struggle", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/encourage/log-struggle", strings.NewReader(`{
+			"struggle": "Feeling anxious about work",
+			"verse": "Philippians 4:6",
+			"message": "Bring your worries to God"
+		}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/encourage/struggles", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var logs []map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &logs)
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+		assert.Equal(t, "Feeling anxious about work", logs[0]["struggle"])
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/encourage/library", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+
+		var library []map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &library)
+		assert.NoError(t, err)
+		for _, entry := range library {
+			assert.NotEqual(t, "Bring your worries to God", entry["message"])
+		}
+	})
 }
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"armourup/internal/config"
+	"armourup/internal/domain/user"
+	"armourup/internal/middleware"
+	"armourup/internal/server"
+	"armourup/test/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// setupEndpointTest connects to the test database and sets up the API
+// routes. The tables are emptied first so earlier runs don't leak in, as
+// the users they belonged to are dropped on teardown.
+func setupEndpointTest(t *testing.T, tables ...string) (*gin.Engine, *gorm.DB) {
+	SetupTestConfig(t)
+	t.Cleanup(func() { TeardownTestConfig(t) })
+	require.NoError(t, config.LoadConfig())
+
+	db := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.TeardownTestDB(t, db) })
+	for _, table := range tables {
+		require.NoError(t, db.Exec("DELETE FROM "+table).Error)
+	}
+
+	router := gin.Default()
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := server.SetupRoutes(ctx, router, db, zap.NewNop())
+	// Stop the workers before the database is torn down
+	t.Cleanup(func() {
+		cancel()
+		wait()
+	})
+	return router, db
+}
+
+// useFakeProvider makes the AI features reply with the scripted responses,
+// repeating the last, until the test ends. Call it before setupEndpointTest.
+func useFakeProvider(t *testing.T, responses ...string) {
+	viper.Set("llm.provider", "fake")
+	viper.Set("llm.fake_responses", responses)
+	t.Cleanup(func() {
+		viper.Set("llm.provider", "openai")
+		viper.Set("llm.fake_responses", nil)
+	})
+}
+
+// createUserToken creates a user and an access token for them with the role
+func createUserToken(t *testing.T, db *gorm.DB, username, role string) (*user.User, string) {
+	u := &user.User{
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: "password123",
+	}
+	require.NoError(t, user.NewService(user.NewRepository(db)).CreateUser(u))
+
+	tokens, err := middleware.GenerateToken(u.ID, u.Email, role)
+	require.NoError(t, err)
+	return u, tokens.AccessToken
+}
+
+// requests counts the requests sent by doRequest
+var requests uint32
+
+// doRequest sends a request with an optional JSON body and bearer token.
+// Each request comes from its own address so that rate limits, which are
+// per client, don't get in the way.
+func doRequest(router *gin.Engine, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != nil {
+		encoded, _ := json.Marshal(body)
+		reader = bytes.NewReader(encoded)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, reader)
+	n := atomic.AddUint32(&requests, 1)
+	req.RemoteAddr = fmt.Sprintf("10.%d.%d.%d:1234", n>>16&0xff, n>>8&0xff, n&0xff)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeJSON decodes a response body into a map
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+	return body
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"testing"
+
+	"armourup/internal/domain/insights"
+	"armourup/internal/prompts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compareFixture(t *testing.T) *insights.PeriodComparison {
+	january, err := insights.ParsePeriod("2025-01")
+	require.NoError(t, err)
+	february, err := insights.ParsePeriod("2025-02")
+	require.NoError(t, err)
+
+	from := &insights.InsightData{
+		MoodEntries:     make([]insights.MoodSummary, 4),
+		JournalEntries:  make([]insights.JournalSummary, 31),
+		GratitudeCount:  9,
+		PrayersOffered:  5,
+		PrayersAnswered: 0,
+		AvgEnergyLevel:  4.25,
+		TopEmotions:     map[string]int{"anxious": 3, "hopeful": 1},
+		TopSpiritual:    map[string]int{"seeking": 4},
+	}
+	to := &insights.InsightData{
+		MoodEntries:     make([]insights.MoodSummary, 4),
+		JournalEntries:  make([]insights.JournalSummary, 28),
+		GratitudeCount:  20,
+		PrayersOffered:  8,
+		PrayersAnswered: 2,
+		AvgEnergyLevel:  6.5,
+		TopEmotions:     map[string]int{"anxious": 1, "hopeful": 3},
+		TopSpiritual:    map[string]int{"seeking": 2, "growing": 2},
+	}
+	return insights.ComparePeriods(january, from, february, to)
+}
+
+func TestComparePeriods(t *testing.T) {
+	comparison := compareFixture(t)
+
+	assert.Equal(t, 31, comparison.From.Days)
+	assert.Equal(t, 28, comparison.To.Days)
+	assert.Equal(t, 4.3, comparison.From.AvgEnergy)
+	assert.Equal(t, 2.0, comparison.From.GratitudePerWeek)
+	assert.Equal(t, 5.0, comparison.To.GratitudePerWeek)
+
+	// A journal entry every day is the same frequency in both months
+	assert.Equal(t, 0.0, comparison.Changes.JournalsPerWeek)
+	assert.Equal(t, 3.0, comparison.Changes.GratitudePerWeek)
+	assert.Equal(t, 3, comparison.Changes.PrayersOffered)
+	assert.Equal(t, 2, comparison.Changes.PrayersAnswered)
+	require.NotNil(t, comparison.Changes.AvgEnergy)
+	assert.Equal(t, 2.2, *comparison.Changes.AvgEnergy)
+
+	assert.Equal(t, []insights.StateChange{
+		{State: "anxious", From: 75, To: 25, Change: -50},
+		{State: "hopeful", From: 25, To: 75, Change: 50},
+	}, comparison.Changes.EmotionalStates)
+	assert.Equal(t, []insights.StateChange{
+		{State: "growing", From: 0, To: 50, Change: 50},
+		{State: "seeking", From: 100, To: 50, Change: -50},
+	}, comparison.Changes.SpiritualStates)
+}
+
+func TestComparePeriodsWithoutMoods(t *testing.T) {
+	week, err := insights.ParsePeriod("2025-W07")
+	require.NoError(t, err)
+	month, err := insights.ParsePeriod("2025-02")
+	require.NoError(t, err)
+
+	comparison := insights.ComparePeriods(week, &insights.InsightData{}, month, &insights.InsightData{
+		MoodEntries:    make([]insights.MoodSummary, 2),
+		AvgEnergyLevel: 7,
+		TopEmotions:    map[string]int{"joyful": 2},
+	})
+	assert.Nil(t, comparison.Changes.AvgEnergy)
+	assert.Equal(t, []insights.StateChange{{State: "joyful", From: 0, To: 100, Change: 100}}, comparison.Changes.EmotionalStates)
+	assert.Contains(t, insights.RulesNarrative(comparison), "there aren't mood entries in both periods")
+}
+
+func TestRulesNarrative(t *testing.T) {
+	narrative := insights.RulesNarrative(compareFixture(t))
+
+	assert.Equal(t, "Comparing January 2025 with February 2025: your average energy rose from 4.3 to 6.5. "+
+		"You felt anxious less often (25% of check-ins, from 75%). "+
+		"You gave thanks 3.0 more times per week and journaled as often as before. "+
+		"You prayed 3 times more, and saw 2 prayers answered.", narrative)
+}
+
+func TestComparePrompt(t *testing.T) {
+	registry, err := prompts.Load("")
+	require.NoError(t, err)
+
+	version, err := registry.Select("insights_compare", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "insights_compare@v1", version.ID())
+
+	messages, err := version.Messages(compareFixture(t))
+	require.NoError(t, err)
+	assert.Contains(t, messages[1].Content, "**January 2025** (31 days)\n- Mood check-ins: 4, average energy 4.3/10")
+	assert.Contains(t, messages[1].Content, "- Gratitude entries: 20 (5.0 per week)")
+	assert.Contains(t, messages[1].Content, "- anxious: 75% to 25%")
+	assert.Contains(t, messages[1].Content, "- growing: 0% to 50%")
+}
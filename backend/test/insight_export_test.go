@@ -0,0 +1,130 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"armourup/internal/domain/insights"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportFixture(t *testing.T) *insights.ProgressInsight {
+	period, err := insights.ParsePeriod("2025-02")
+	require.NoError(t, err)
+
+	moods := []insights.MoodSummary{
+		{Date: parseDay("2025-02-10"), EnergyLevel: 6, EmotionalState: "hopeful"},
+		{Date: parseDay("2025-02-11"), EnergyLevel: 8, EmotionalState: "grateful"},
+	}
+	activity := []insights.ActivityCount{
+		{Day: parseDay("2025-02-10"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-11"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-11"), Source: insights.SourceGratitude, Count: 3},
+	}
+
+	return &insights.ProgressInsight{
+		ID:         7,
+		Period:     "2025-02",
+		Summary:    "A month of <growing> trust.\n\nYou kept coming back to prayer.",
+		Highlights: []string{"Gave thanks 3 times"},
+		Areas:      []string{"Try a daily reading"},
+		Verse:      `"The joy of the Lord is your strength." - Nehemiah 8:10`,
+		Stats:      insights.ComputeStats(period, moods, activity, parseDay("2025-03-10")),
+		Mode:       insights.ModeAI,
+	}
+}
+
+func newTestExporter(t *testing.T, dir string) *insights.Exporter {
+	exporter, err := insights.NewExporter(insights.Branding{
+		Name:    "Grace Church",
+		Tagline: "Growing together",
+		Color:   "#336699",
+		Footer:  "Shared with my pastor",
+	}, dir)
+	require.NoError(t, err)
+	return exporter
+}
+
+func TestExportMarkdown(t *testing.T) {
+	report, err := newTestExporter(t, "").Export(exportFixture(t), insights.FormatMarkdown)
+	require.NoError(t, err)
+
+	body := string(report.Body)
+	assert.Equal(t, "text/markdown; charset=utf-8", report.ContentType)
+	assert.Equal(t, "insight-2025-02.md", report.Filename)
+	assert.Contains(t, body, "# Grace Church: Your insight for February 2025")
+	assert.Contains(t, body, "_Growing together_")
+	assert.Contains(t, body, "## Highlights\n\n- Gave thanks 3 times")
+	assert.Contains(t, body, "| Gratitude entries | 3 |")
+	assert.Contains(t, body, "![Entries by area](data:image/svg+xml;base64,")
+	assert.Contains(t, body, "> \"The joy of the Lord is your strength.\" - Nehemiah 8:10")
+	assert.Contains(t, body, "Written by AI from your entries · Shared with my pastor")
+}
+
+func TestExportHTML(t *testing.T) {
+	report, err := newTestExporter(t, "").Export(exportFixture(t), insights.FormatHTML)
+	require.NoError(t, err)
+
+	body := string(report.Body)
+	assert.Equal(t, "text/html; charset=utf-8", report.ContentType)
+	assert.Contains(t, body, "<p>A month of &lt;growing&gt; trust.</p>")
+	assert.Contains(t, body, "<p>You kept coming back to prayer.</p>")
+	assert.Contains(t, body, "border-bottom: 3px solid #336699")
+	assert.Contains(t, body, `<svg xmlns="http://www.w3.org/2000/svg"`)
+	assert.Contains(t, body, `fill="#336699"`)
+	assert.Contains(t, body, "<figcaption>Energy levels</figcaption>")
+}
+
+func TestExportPDF(t *testing.T) {
+	report, err := newTestExporter(t, "").Export(exportFixture(t), insights.FormatPDF)
+	require.NoError(t, err)
+
+	assert.Equal(t, "application/pdf", report.ContentType)
+	assert.Equal(t, "insight-2025-02.pdf", report.Filename)
+	assert.True(t, bytes.HasPrefix(report.Body, []byte("%PDF-")))
+}
+
+func TestExportUnsupportedFormat(t *testing.T) {
+	_, err := newTestExporter(t, "").Export(exportFixture(t), "docx")
+	assert.ErrorIs(t, err, insights.ErrUnsupportedFormat)
+}
+
+func TestExportTemplateOverride(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report.md.tmpl"), []byte("{{.Branding.Name}} | {{.Title}}"), 0o644))
+
+	exporter := newTestExporter(t, dir)
+	report, err := exporter.Export(exportFixture(t), insights.FormatMarkdown)
+	require.NoError(t, err)
+	assert.Equal(t, "Grace Church | Your insight for February 2025", string(report.Body))
+
+	// Formats without an override use the built-in template
+	report, err = exporter.Export(exportFixture(t), insights.FormatHTML)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(report.Body), "<!DOCTYPE html>"))
+}
+
+func TestReportFilenameForCustomPeriod(t *testing.T) {
+	insight := &insights.ProgressInsight{Period: "2025-01-10..2025-02-05"}
+	assert.Equal(t, "insight-2025-01-10-to-2025-02-05.html", insights.ReportFilename(insight, insights.FormatHTML))
+}
+
+func TestReportCharts(t *testing.T) {
+	assert.Empty(t, insights.ReportCharts(nil))
+
+	charts := insights.ReportCharts(exportFixture(t).Stats)
+	require.Len(t, charts, 3)
+	assert.Equal(t, "Entries by area", charts[0].Title)
+	assert.Equal(t, "Entries by week", charts[1].Title)
+	assert.Equal(t, "Energy levels", charts[2].Title)
+	assert.Len(t, charts[2].Bars, 10)
+
+	svg := insights.Chart{Title: "<script>", Bars: []insights.Bar{{Label: "a&b", Value: 2}}}.SVG("#336699")
+	assert.NotContains(t, svg, "<script>")
+	assert.Contains(t, svg, "a&amp;b")
+}
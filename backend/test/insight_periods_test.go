@@ -0,0 +1,136 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"armourup/internal/domain/insights"
+	"armourup/internal/domain/mood"
+	"armourup/internal/domain/privacy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseDay(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		key         string
+		granularity string
+		start       string
+		end         string
+		label       string
+	}{
+		{"2025-01", insights.GranularityMonth, "2025-01-01", "2025-01-31", "January 2025"},
+		{"2025-W07", insights.GranularityWeek, "2025-02-10", "2025-02-16", "the week of 10 February 2025"},
+		{"2026-W01", insights.GranularityWeek, "2025-12-29", "2026-01-04", "the week of 29 December 2025"},
+		{"2020-W53", insights.GranularityWeek, "2020-12-28", "2021-01-03", "the week of 28 December 2020"},
+		{"2025-Q2", insights.GranularityQuarter, "2025-04-01", "2025-06-30", "Q2 2025 (April to June)"},
+		{"2024", insights.GranularityYear, "2024-01-01", "2024-12-31", "2024"},
+		{"2025-01-10..2025-02-05", insights.GranularityCustom, "2025-01-10", "2025-02-05", "10 January 2025 to 5 February 2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			period, err := insights.ParsePeriod(tt.key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.key, period.Key)
+			assert.Equal(t, tt.granularity, period.Granularity)
+			assert.Equal(t, parseDay(tt.start), period.Start)
+			assert.Equal(t, parseDay(tt.end).Add(24*time.Hour-time.Second), period.End)
+			assert.Equal(t, tt.label, period.Label())
+		})
+	}
+
+	for _, key := range []string{"", "2025-13", "2025-W00", "2025-W53", "2025-Q5", "25", "2025-02-05..2025-01-10", "2024-01-01..2025-06-01", "next month"} {
+		_, err := insights.ParsePeriod(key)
+		assert.ErrorIs(t, err, insights.ErrInvalidPeriod, key)
+	}
+}
+
+func TestPeriodMonths(t *testing.T) {
+	period, err := insights.ParsePeriod("2025-Q1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-01", "2025-02", "2025-03"}, period.Months())
+
+	period, err = insights.ParsePeriod("2024")
+	require.NoError(t, err)
+	assert.Len(t, period.Months(), 12)
+}
+
+func TestPeriodsForDates(t *testing.T) {
+	available := insights.PeriodsForDates([]time.Time{parseDay("2024-12-30"), parseDay("2025-02-11"), parseDay("2025-02-12")})
+
+	// 30 December 2024 is in the first ISO week of 2025
+	assert.Equal(t, []string{"2025-W07", "2025-W01"}, available.Weeks)
+	assert.Equal(t, []string{"2025-02", "2024-12"}, available.Months)
+	assert.Equal(t, []string{"2025-Q1", "2024-Q4"}, available.Quarters)
+	assert.Equal(t, []string{"2025", "2024"}, available.Years)
+
+	empty := insights.PeriodsForDates(nil)
+	assert.Empty(t, empty.Months)
+	assert.NotNil(t, empty.Months)
+}
+
+func TestPeriodInsightPrompts(t *testing.T) {
+	registry := builtinPrompts(t)
+
+	t.Run("Weekly", func(t *testing.T) {
+		version, err := registry.Select("insights", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "insights@v4", version.ID())
+
+		messages, err := version.Messages(insights.InsightPromptData{
+			InsightData: &insights.InsightData{Period: "2025-W07", Label: "the week of 10 February 2025", Noun: "week"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, messages[1].Content, "summary for the week of 10 February 2025")
+		assert.Contains(t, messages[1].Content, "suggestions for the week ahead")
+	})
+
+	t.Run("Monthly Mood Findings", func(t *testing.T) {
+		version, err := registry.Select("insights", 1)
+		require.NoError(t, err)
+
+		difference, mean := 1.5, 7.5
+		messages, err := version.Messages(insights.InsightPromptData{
+			InsightData: &insights.InsightData{
+				Period: "2025-02", Label: "February 2025", Noun: "month",
+				MoodFindings: []mood.Correlation{{
+					Practice: mood.PracticePrayer, Measure: mood.MeasureEnergy,
+					DaysWith: 12, DaysWithout: 9, MeanWith: &mean, Difference: &difference,
+				}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, messages[1].Content, "**How Practices Went With Mood:**\n- On days you prayed, your energy averaged 7.5, 1.5 higher than on days you didn't (12 and 9 days).")
+		assert.Contains(t, messages[1].Content, "a pattern worth noticing")
+	})
+
+	t.Run("Year In Review", func(t *testing.T) {
+		year, err := insights.ParsePeriod("2024")
+		require.NoError(t, err)
+		months := []insights.ProgressInsight{
+			{ID: 3, Period: "2024-01", Summary: "A hopeful start", Highlights: []string{"Prayed daily"},
+				Stats: &insights.InsightStats{ActiveDays: 20, Energy: insights.EnergyStats{Average: 6}}},
+			{ID: 9, Period: "2024-06", Summary: "Called my friend Sarah often"},
+		}
+		disclosure := &privacy.Disclosure{Sharing: privacy.SharingAggregates}
+		data := insights.BuildYearReviewData(year, months, disclosure)
+		assert.Equal(t, "2024", data.Year)
+		require.Len(t, data.Months, 2)
+		assert.Equal(t, "June 2024", data.Months[1].Label)
+		assert.NotContains(t, data.Months[1].Summary, "Sarah")
+
+		version, err := registry.Select("insights_year", 1)
+		require.NoError(t, err)
+		messages, err := version.Messages(data)
+		require.NoError(t, err)
+		assert.Contains(t, messages[1].Content, "year in review of 2024")
+		assert.Contains(t, messages[1].Content, "**January 2024** (0 entries on 20 days, longest streak 0 days, average energy 6.0/10)\nA hopeful start\n- Prayed daily")
+		assert.Contains(t, messages[1].Content, "**June 2024**\n")
+	})
+}
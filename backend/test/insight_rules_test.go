@@ -0,0 +1,107 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"armourup/internal/domain/insights"
+	"armourup/internal/domain/mood"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rulesInsightData(t *testing.T) *insights.InsightData {
+	period, err := insights.ParsePeriod("2025-02")
+	require.NoError(t, err)
+
+	moods := []insights.MoodSummary{
+		{Date: parseDay("2025-02-10"), EnergyLevel: 3, EmotionalState: "anxious", SpiritualState: "seeking"},
+		{Date: parseDay("2025-02-11"), EnergyLevel: 4, EmotionalState: "anxious", SpiritualState: "seeking"},
+		{Date: parseDay("2025-02-12"), EnergyLevel: 5, EmotionalState: "hopeful", SpiritualState: "growing"},
+	}
+	activity := []insights.ActivityCount{
+		{Day: parseDay("2025-02-10"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-10"), Source: insights.SourcePrayer, Count: 2},
+		{Day: parseDay("2025-02-11"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-12"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-12"), Source: insights.SourceAnswered, Count: 1},
+	}
+
+	return &insights.InsightData{
+		Period:          period.Key,
+		Label:           period.Label(),
+		Noun:            period.Noun(),
+		MoodEntries:     moods,
+		PrayersOffered:  2,
+		PrayersAnswered: 1,
+		AvgEnergyLevel:  4,
+		TopEmotions:     map[string]int{"anxious": 2, "hopeful": 1},
+		TopSpiritual:    map[string]int{"seeking": 2, "growing": 1},
+		Stats:           insights.ComputeStats(period, moods, activity, parseDay("2025-03-10")),
+	}
+}
+
+func TestGenerateRulesInsight(t *testing.T) {
+	insight := insights.GenerateRulesInsight(rulesInsightData(t))
+
+	assert.Contains(t, insight.Summary, "During February 2025 you made 6 entries across 3 days.")
+	assert.Contains(t, insight.Summary, "You most often felt anxious, and spiritually you were most often seeking")
+	assert.Contains(t, insight.Summary, "God answered 1 prayer this month")
+	assert.Equal(t, []string{
+		"You showed up 3 days in a row.",
+		"You saw 1 prayer answered.",
+		"You prayed for others 2 times.",
+	}, insight.Highlights)
+	assert.Len(t, insight.AreasForGrowth, 3)
+	assert.Contains(t, insight.AreasForGrowth[0], "grateful")
+	assert.Contains(t, insight.Verse, "Philippians 4:6-7")
+}
+
+func TestGenerateRulesInsightWithMoodFindings(t *testing.T) {
+	data := rulesInsightData(t)
+	lower, higher, mean := -0.4, 1.2, 5.1
+	data.MoodFindings = []mood.Correlation{
+		{Practice: mood.PracticeReading, Measure: mood.MeasureValence, DaysWith: 6, DaysWithout: 10, Difference: &lower},
+		{Practice: mood.PracticeGratitude, Measure: mood.MeasureEnergy, DaysWith: 8, DaysWithout: 12, MeanWith: &mean, Difference: &higher},
+	}
+
+	insight := insights.GenerateRulesInsight(data)
+
+	// Only a practice that went with better days is a highlight
+	require.Len(t, insight.Highlights, 4)
+	assert.Equal(t, "On days you gave thanks, your energy averaged 5.1, 1.2 higher than on days you didn't (8 and 12 days).", insight.Highlights[1])
+}
+
+func TestGenerateRulesInsightIsDeterministic(t *testing.T) {
+	first := insights.GenerateRulesInsight(rulesInsightData(t))
+	second := insights.GenerateRulesInsight(rulesInsightData(t))
+	assert.Equal(t, first, second)
+}
+
+func TestGenerateRulesInsightWithoutData(t *testing.T) {
+	period, err := insights.ParsePeriod("2025-W07")
+	require.NoError(t, err)
+
+	insight := insights.GenerateRulesInsight(&insights.InsightData{
+		Period: period.Key,
+		Label:  period.Label(),
+		Noun:   period.Noun(),
+		Stats:  insights.ComputeStats(period, nil, nil, time.Now()),
+	})
+
+	assert.Contains(t, insight.Summary, "Nothing was recorded for the week of 10 February 2025.")
+	assert.Len(t, insight.Highlights, 1)
+	assert.Equal(t, []string{"Try checking in on your mood once a day this week."}, insight.AreasForGrowth)
+	assert.Contains(t, insight.Verse, "Lamentations 3:22-23")
+}
+
+func TestGenerateRulesInsightVerseForLowEnergy(t *testing.T) {
+	data := rulesInsightData(t)
+	data.TopEmotions = map[string]int{"calm": 3}
+	data.AvgEnergyLevel = 2.5
+
+	insight := insights.GenerateRulesInsight(data)
+	assert.Contains(t, insight.Verse, "Isaiah 40:31")
+	assert.Contains(t, insight.AreasForGrowth, "Your energy has been low; make room for rest, and share the load with someone you trust.")
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"armourup/internal/domain/insights"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsWindow(t *testing.T) {
+	// February 2025 starts on a Saturday and ends on a Friday
+	period, err := insights.ParsePeriod("2025-02")
+	require.NoError(t, err)
+
+	start, end := insights.StatsWindow(period)
+	assert.Equal(t, parseDay("2025-01-20"), start)
+	assert.Equal(t, parseDay("2025-03-03").Add(-time.Second), end)
+}
+
+func TestComputeStats(t *testing.T) {
+	period, err := insights.ParsePeriod("2025-W07") // 10-16 February 2025
+	require.NoError(t, err)
+
+	moods := []insights.MoodSummary{
+		{Date: parseDay("2025-02-04"), EnergyLevel: 4, EmotionalState: "anxious"},
+		{Date: parseDay("2025-02-10"), EnergyLevel: 6, EmotionalState: "hopeful", SpiritualState: "growing"},
+		{Date: parseDay("2025-02-11"), EnergyLevel: 8, EmotionalState: "hopeful", SpiritualState: "growing"},
+		{Date: parseDay("2025-02-13"), EnergyLevel: 7, EmotionalState: "grateful", SpiritualState: "seeking"},
+	}
+	activity := []insights.ActivityCount{
+		{Day: parseDay("2025-02-04"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-05"), Source: insights.SourceJournal, Count: 1},
+		{Day: parseDay("2025-02-10"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-10"), Source: insights.SourcePrayer, Count: 3},
+		{Day: parseDay("2025-02-11"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-11"), Source: insights.SourceGratitude, Count: 2},
+		{Day: parseDay("2025-02-12"), Source: insights.SourceReading, Count: 1},
+		{Day: parseDay("2025-02-13"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-13"), Source: insights.SourceAnswered, Count: 1},
+		{Day: parseDay("2025-02-13"), Source: insights.SourceJournal, Count: 1},
+	}
+
+	stats := insights.ComputeStats(period, moods, activity, parseDay("2025-03-01"))
+
+	assert.Equal(t, insights.EntryCounts{Moods: 3, Journals: 1, Gratitude: 2, PrayersOffered: 3, PrayersAnswered: 1, Readings: 1}, stats.Entries)
+	assert.Equal(t, 11, stats.Entries.Total())
+	assert.Equal(t, 4, stats.ActiveDays)
+
+	// 10-13 February in a row; the period ended days ago with no activity on its last day
+	assert.Equal(t, 4, stats.Streaks.Longest)
+	assert.Equal(t, 0, stats.Streaks.Current)
+
+	assert.Equal(t, 7.0, stats.Energy.Average)
+	require.Len(t, stats.Energy.Distribution, 10)
+	assert.Equal(t, insights.EnergyCount{Level: 8, Count: 1}, stats.Energy.Distribution[7])
+	assert.Equal(t, 0, stats.Energy.Distribution[3].Count) // The 4 was the week before
+
+	assert.Equal(t, []insights.StateCount{{State: "hopeful", Count: 2}, {State: "grateful", Count: 1}}, stats.TopEmotional)
+	assert.Equal(t, []insights.StateCount{{State: "growing", Count: 2}, {State: "seeking", Count: 1}}, stats.TopSpiritual)
+
+	require.Len(t, stats.Weeks, 1)
+	week := stats.Weeks[0]
+	assert.Equal(t, "2025-W07", week.Week)
+	assert.Equal(t, "2025-02-10", week.Start)
+	assert.Equal(t, 11, week.Entries)
+	assert.Equal(t, 7.0, week.AvgEnergy)
+	require.NotNil(t, week.EntriesChange)
+	assert.Equal(t, 9, *week.EntriesChange)
+	require.NotNil(t, week.EnergyChange)
+	assert.Equal(t, 3.0, *week.EnergyChange)
+}
+
+func TestCurrentStreakWhilePeriodIsOngoing(t *testing.T) {
+	period, err := insights.ParsePeriod("2025-02")
+	require.NoError(t, err)
+
+	activity := []insights.ActivityCount{
+		{Day: parseDay("2025-02-08"), Source: insights.SourceMood, Count: 1},
+		{Day: parseDay("2025-02-09"), Source: insights.SourceJournal, Count: 1},
+	}
+
+	// Nothing yet today doesn't break the streak
+	stats := insights.ComputeStats(period, nil, activity, parseDay("2025-02-10").Add(9*time.Hour))
+	assert.Equal(t, 2, stats.Streaks.Current)
+	assert.Equal(t, 2, stats.Streaks.Longest)
+
+	// Missing a whole day does
+	stats = insights.ComputeStats(period, nil, activity, parseDay("2025-02-11").Add(9*time.Hour))
+	assert.Equal(t, 0, stats.Streaks.Current)
+}
+
+func TestComputeStatsWithNoData(t *testing.T) {
+	period, err := insights.ParsePeriod("2025-Q1")
+	require.NoError(t, err)
+
+	stats := insights.ComputeStats(period, nil, nil, parseDay("2025-06-01"))
+	assert.Equal(t, 0, stats.Entries.Total())
+	assert.Equal(t, 0.0, stats.Energy.Average)
+	assert.NotNil(t, stats.TopEmotional)
+	assert.Len(t, stats.Weeks, 14)
+	for _, week := range stats.Weeks {
+		assert.Nil(t, week.EntriesChange)
+		assert.Nil(t, week.EnergyChange)
+	}
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"armourup/internal/domain/insights"
+	"armourup/internal/jobs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDueForRefresh(t *testing.T) {
+	insight := &insights.ProgressInsight{Period: "2025-01", Provisional: true}
+
+	assert.False(t, insights.DueForRefresh(insight, parseDay("2025-01-20")))
+	// A day's grace after the month ends
+	assert.False(t, insights.DueForRefresh(insight, parseDay("2025-02-01")))
+	assert.True(t, insights.DueForRefresh(insight, parseDay("2025-02-02")))
+
+	insight.Provisional = false
+	assert.False(t, insights.DueForRefresh(insight, parseDay("2025-03-01")))
+
+	week := &insights.ProgressInsight{Period: "2025-W07", Provisional: true}
+	assert.False(t, insights.DueForRefresh(week, parseDay("2025-02-16")))
+	assert.True(t, insights.DueForRefresh(week, parseDay("2025-02-18")))
+
+	invalid := &insights.ProgressInsight{Period: "someday", Provisional: true}
+	assert.False(t, insights.DueForRefresh(invalid, parseDay("2030-01-01")))
+}
+
+func TestWorkerRunsScheduledTasks(t *testing.T) {
+	worker := jobs.NewWorker(nil, jobs.WorkerOptions{PollInterval: time.Hour, Timeout: time.Hour})
+	runs := make(chan struct{}, 10)
+	worker.Every(5*time.Millisecond, func(ctx context.Context) {
+		runs <- struct{}{}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker.Start(ctx)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatal("scheduled task did not run")
+		}
+	}
+
+	// Once stopped, the worker's tasks have all returned
+	cancel()
+	worker.Wait()
+}
+
+const (
+	fakeFirstInsight  = `{"summary": "A steady week", "highlights": ["You prayed daily"], "areas_for_growth": ["Rest"], "verse": "Psalm 23:1"}`
+	fakeSecondInsight = `{"summary": "A week of growth", "highlights": ["You journaled"], "areas_for_growth": ["Patience"], "verse": "James 1:4"}`
+)
+
+// waitForInsightJob polls an insight job until the worker has finished it,
+// returning the job's status
+func waitForInsightJob(t *testing.T, router *gin.Engine, token string, accepted map[string]interface{}) map[string]interface{} {
+	statusURL := accepted["status_url"].(string)
+	deadline := time.Now().Add(20 * time.Second)
+	for time.Now().Before(deadline) {
+		w := doRequest(router, "GET", statusURL, token, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		status := decodeJSON(t, w)
+		switch status["status"] {
+		case jobs.StatusSucceeded:
+			return status
+		case jobs.StatusDead:
+			require.FailNow(t, "insight job failed", "%v", status["error"])
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.FailNow(t, "insight job did not finish")
+	return nil
+}
+
+func TestInsightEndpoints(t *testing.T) {
+	useFakeProvider(t, fakeFirstInsight, fakeSecondInsight)
+	router, db := setupEndpointTest(t, "insight_ratings", "progress_insight_versions", "progress_insights", "jobs")
+	_, token := createUserToken(t, db, "reflective", "user")
+	_, otherToken := createUserToken(t, db, "other", "user")
+	_, adminToken := createUserToken(t, db, "admin", "admin")
+
+	w := doRequest(router, "POST", "/api/insights/generate", token, map[string]string{"period": "2024-W07"})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	generated := waitForInsightJob(t, router, token, decodeJSON(t, w))["insight"].(map[string]interface{})
+	assert.Equal(t, "A steady week", generated["summary"])
+	assert.Equal(t, float64(1), generated["version"])
+	path := fmt.Sprintf("/api/insights/%v", generated["id"])
+
+	t.Run("Regenerate Keeps Earlier Versions", func(t *testing.T) {
+		w := doRequest(router, "POST", path+"/regenerate", token, nil)
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		assert.NotEmpty(t, w.Header().Get("Location"))
+		regenerated := waitForInsightJob(t, router, token, decodeJSON(t, w))["insight"].(map[string]interface{})
+		assert.Equal(t, generated["id"], regenerated["id"])
+		assert.Equal(t, "A week of growth", regenerated["summary"])
+		assert.Equal(t, float64(2), regenerated["version"])
+
+		w = doRequest(router, "GET", path+"/versions", token, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var versions []map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &versions))
+		require.Len(t, versions, 1)
+		assert.Equal(t, float64(1), versions[0]["version"])
+		assert.Equal(t, "A steady week", versions[0]["summary"])
+		assert.Equal(t, generated["prompt_version"], versions[0]["prompt_version"])
+	})
+
+	t.Run("Rate The Current Version", func(t *testing.T) {
+		w := doRequest(router, "POST", path+"/rating", token, map[string]interface{}{})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = doRequest(router, "POST", path+"/rating", token, map[string]interface{}{"helpful": false})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		// Rating again changes the verdict rather than adding another
+		w = doRequest(router, "POST", path+"/rating", token, map[string]interface{}{"helpful": true, "comment": "Encouraging"})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		rating := decodeJSON(t, w)
+		assert.Equal(t, float64(2), rating["version"])
+		assert.Equal(t, true, rating["helpful"])
+		assert.Equal(t, generated["prompt_version"], rating["prompt_version"])
+
+		w = doRequest(router, "GET", "/api/admin/insights/ratings", token, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = doRequest(router, "GET", "/api/admin/insights/ratings", adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var ratings []map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ratings))
+		require.Len(t, ratings, 1)
+		assert.Equal(t, generated["prompt_version"], ratings[0]["prompt_version"])
+		assert.Equal(t, float64(1), ratings[0]["helpful"])
+		assert.Equal(t, float64(0), ratings[0]["not_helpful"])
+		assert.Equal(t, float64(1), ratings[0]["helpful_rate"])
+	})
+
+	t.Run("Rules When Sharing Is Off", func(t *testing.T) {
+		w := doRequest(router, "PUT", "/api/privacy/ai-settings", otherToken, map[string]string{"sharing": "none"})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = doRequest(router, "POST", "/api/insights/generate", otherToken, map[string]string{"period": "2024-02"})
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		private := waitForInsightJob(t, router, otherToken, decodeJSON(t, w))["insight"].(map[string]interface{})
+		assert.Equal(t, insights.ModeRules, private["mode"])
+		assert.Equal(t, insights.RulesVersion, private["prompt_version"])
+	})
+
+	t.Run("Other Users Can't Touch It", func(t *testing.T) {
+		w := doRequest(router, "POST", path+"/regenerate", otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "GET", path+"/versions", otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "POST", path+"/rating", otherToken, map[string]interface{}{"helpful": false})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doRequest(router, "GET", "/api/admin/insights/ratings", adminToken, nil)
+		assert.Contains(t, w.Body.String(), `"not_helpful":0`)
+	})
+}
@@ -0,0 +1,351 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"armourup/internal/config"
+	"armourup/internal/domain/insights"
+	"armourup/internal/jobs"
+	"armourup/test/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestJobBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, jobs.Backoff(0))
+	assert.Equal(t, 30*time.Second, jobs.Backoff(1))
+	assert.Equal(t, time.Minute, jobs.Backoff(2))
+	assert.Equal(t, 4*time.Minute, jobs.Backoff(4))
+	assert.Equal(t, 30*time.Minute, jobs.Backoff(7))
+	assert.Equal(t, 30*time.Minute, jobs.Backoff(100))
+}
+
+func TestPermanentJobErrors(t *testing.T) {
+	cause := errors.New("insight not found")
+
+	t.Run("Marked", func(t *testing.T) {
+		err := jobs.Permanent(cause)
+		assert.True(t, jobs.IsPermanent(err))
+		assert.Equal(t, cause.Error(), err.Error())
+		assert.ErrorIs(t, err, cause)
+	})
+
+	t.Run("Wrapped", func(t *testing.T) {
+		err := fmt.Errorf("generating insight: %w", jobs.Permanent(cause))
+		assert.True(t, jobs.IsPermanent(err))
+	})
+
+	t.Run("Unmarked", func(t *testing.T) {
+		assert.False(t, jobs.IsPermanent(cause))
+		assert.Nil(t, jobs.Permanent(nil))
+	})
+}
+
+// setupJobsDB connects to the test database with an empty job queue
+func setupJobsDB(t *testing.T) *gorm.DB {
+	SetupTestConfig(t)
+	t.Cleanup(func() { TeardownTestConfig(t) })
+	require.NoError(t, config.LoadConfig())
+
+	db := testutils.SetupTestDB(t)
+	require.NoError(t, db.Exec("DELETE FROM jobs").Error)
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM jobs")
+		testutils.TeardownTestDB(t, db)
+	})
+	return db
+}
+
+func TestJobQueue(t *testing.T) {
+	db := setupJobsDB(t)
+	queue := jobs.NewQueue(db)
+	kinds := []string{"test"}
+
+	t.Run("Enqueue Dedupes Active Jobs", func(t *testing.T) {
+		first, created, err := queue.Enqueue("test", 1, "test:dedupe", map[string]string{"n": "1"})
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		second, created, err := queue.Enqueue("test", 1, "test:dedupe", map[string]string{"n": "2"})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, first.ID, second.ID)
+
+		// Running jobs still hold the key
+		claimed, err := queue.Claim(kinds)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, first.ID, claimed.ID)
+		_, created, err = queue.Enqueue("test", 1, "test:dedupe", nil)
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		// Finished jobs release it
+		require.NoError(t, queue.Complete(claimed, "{}"))
+		third, created, err := queue.Enqueue("test", 1, "test:dedupe", nil)
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotEqual(t, first.ID, third.ID)
+
+		// Jobs without a key are never deduped
+		a, created, err := queue.Enqueue("other", 1, "", nil)
+		require.NoError(t, err)
+		assert.True(t, created)
+		b, created, err := queue.Enqueue("other", 1, "", nil)
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotEqual(t, a.ID, b.ID)
+
+		require.NoError(t, db.Exec("DELETE FROM jobs").Error)
+	})
+
+	t.Run("Claim Skips Locked Jobs", func(t *testing.T) {
+		first, _, err := queue.Enqueue("test", 1, "", nil)
+		require.NoError(t, err)
+		second, _, err := queue.Enqueue("test", 1, "", nil)
+		require.NoError(t, err)
+
+		// Another worker holds the first job's row lock
+		tx := db.Begin()
+		defer tx.Rollback()
+		require.NoError(t, tx.Exec("SELECT id FROM jobs WHERE id = ? FOR UPDATE", first.ID).Error)
+
+		claimed, err := queue.Claim(kinds)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, second.ID, claimed.ID)
+		assert.Equal(t, jobs.StatusRunning, claimed.Status)
+		assert.Equal(t, 1, claimed.Attempts)
+		assert.NotNil(t, claimed.LockedAt)
+
+		// Nothing else is free until the lock is released
+		none, err := queue.Claim(kinds)
+		require.NoError(t, err)
+		assert.Nil(t, none)
+
+		tx.Rollback()
+		claimed, err = queue.Claim(kinds)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, first.ID, claimed.ID)
+
+		require.NoError(t, db.Exec("DELETE FROM jobs").Error)
+	})
+
+	t.Run("Claim Ignores Other Kinds And Future Jobs", func(t *testing.T) {
+		_, _, err := queue.Enqueue("other", 1, "", nil)
+		require.NoError(t, err)
+		later, _, err := queue.Enqueue("test", 1, "", nil)
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&jobs.Job{}).Where("id = ?", later.ID).Update("run_at", time.Now().Add(time.Hour)).Error)
+
+		claimed, err := queue.Claim(kinds)
+		require.NoError(t, err)
+		assert.Nil(t, claimed)
+
+		require.NoError(t, db.Exec("DELETE FROM jobs").Error)
+	})
+
+	t.Run("Fail Dead Letters After Max Attempts", func(t *testing.T) {
+		job, _, err := queue.Enqueue("test", 1, "", nil)
+		require.NoError(t, err)
+
+		for attempt := 1; attempt <= jobs.DefaultMaxAttempts; attempt++ {
+			claimed, err := queue.Claim(kinds)
+			require.NoError(t, err)
+			require.NotNil(t, claimed)
+			assert.Equal(t, attempt, claimed.Attempts)
+
+			before := time.Now().Truncate(time.Microsecond) // Postgres keeps microseconds
+			require.NoError(t, queue.Fail(claimed, errors.New("provider unavailable")))
+
+			stored, err := queue.Get(job.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "provider unavailable", stored.LastError)
+			if attempt < jobs.DefaultMaxAttempts {
+				assert.Equal(t, jobs.StatusQueued, stored.Status)
+				assert.False(t, stored.RunAt.Before(before.Add(jobs.Backoff(attempt))))
+				assert.Nil(t, stored.FinishedAt)
+
+				// Skip the backoff
+				require.NoError(t, db.Model(&jobs.Job{}).Where("id = ?", job.ID).Update("run_at", time.Now()).Error)
+			} else {
+				assert.Equal(t, jobs.StatusDead, stored.Status)
+				assert.NotNil(t, stored.FinishedAt)
+			}
+		}
+
+		none, err := queue.Claim(kinds)
+		require.NoError(t, err)
+		assert.Nil(t, none)
+
+		dead, err := queue.List(jobs.StatusDead, 10)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, job.ID, dead[0].ID)
+
+		retried, err := queue.Retry(job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusQueued, retried.Status)
+		assert.Equal(t, 0, retried.Attempts)
+
+		_, err = queue.Retry(job.ID)
+		assert.EqualError(t, err, "only dead jobs can be retried")
+		_, err = queue.Retry(job.ID + 1000)
+		assert.EqualError(t, err, "job not found")
+
+		require.NoError(t, db.Exec("DELETE FROM jobs").Error)
+	})
+
+	t.Run("Fail Dead Letters Permanent Errors", func(t *testing.T) {
+		_, _, err := queue.Enqueue("test", 1, "", nil)
+		require.NoError(t, err)
+		claimed, err := queue.Claim(kinds)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+
+		require.NoError(t, queue.Fail(claimed, jobs.Permanent(errors.New("insight not found"))))
+		stored, err := queue.Get(claimed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusDead, stored.Status)
+		assert.Equal(t, 1, stored.Attempts)
+
+		require.NoError(t, db.Exec("DELETE FROM jobs").Error)
+	})
+
+	t.Run("Stale Jobs Use Up Attempts", func(t *testing.T) {
+		job, _, err := queue.Enqueue("test", 1, "", nil)
+		require.NoError(t, err)
+		claimed, err := queue.Claim(kinds)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+
+		// A worker that stopped part way leaves its job locked
+		abandon := func(attempts int) {
+			require.NoError(t, db.Model(&jobs.Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+				"status": jobs.StatusRunning, "attempts": attempts, "locked_at": time.Now().Add(-time.Hour),
+			}).Error)
+		}
+
+		abandon(1)
+		require.NoError(t, queue.RequeueStale(time.Minute))
+		stored, err := queue.Get(job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusQueued, stored.Status)
+		assert.Equal(t, 1, stored.Attempts)
+		assert.Nil(t, stored.LockedAt)
+		assert.NotEmpty(t, stored.LastError)
+
+		abandon(jobs.DefaultMaxAttempts)
+		require.NoError(t, queue.RequeueStale(time.Minute))
+		stored, err = queue.Get(job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusDead, stored.Status)
+		assert.NotNil(t, stored.FinishedAt)
+
+		// Jobs locked more recently are left to their worker
+		fresh, _, err := queue.Enqueue("test", 1, "", nil)
+		require.NoError(t, err)
+		_, err = queue.Claim(kinds)
+		require.NoError(t, err)
+		require.NoError(t, queue.RequeueStale(time.Minute))
+		stored, err = queue.Get(fresh.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusRunning, stored.Status)
+
+		require.NoError(t, db.Exec("DELETE FROM jobs").Error)
+	})
+
+	t.Run("Shutdown Waits For Running Jobs", func(t *testing.T) {
+		job, _, err := queue.Enqueue("test", 1, "", nil)
+		require.NoError(t, err)
+
+		started, release := make(chan struct{}), make(chan struct{})
+		worker := jobs.NewWorker(queue, jobs.WorkerOptions{PollInterval: 10 * time.Millisecond, Timeout: time.Minute})
+		worker.Handle("test", func(ctx context.Context, job *jobs.Job) (string, error) {
+			close(started)
+			<-release
+			// Shutting down doesn't cancel a job part way
+			return "{}", ctx.Err()
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		worker.Start(ctx)
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("job did not start")
+		}
+
+		cancel()
+		stopped := make(chan struct{})
+		go func() {
+			worker.Wait()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			t.Fatal("worker stopped before its job finished")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatal("worker did not stop")
+		}
+
+		stored, err := queue.Get(job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusSucceeded, stored.Status)
+
+		// Nothing more is claimed once stopped
+		_, _, err = queue.Enqueue("test", 1, "", nil)
+		require.NoError(t, err)
+		ran, err := worker.RunOnce(ctx)
+		require.NoError(t, err)
+		assert.False(t, ran)
+
+		require.NoError(t, db.Exec("DELETE FROM jobs").Error)
+	})
+}
+
+func TestInsightCreateOnce(t *testing.T) {
+	db := setupJobsDB(t)
+	repo := insights.NewRepository(db)
+	user := testutils.CreateTestUser(t, db, "insights@example.com", "password123")
+	t.Cleanup(func() { db.Unscoped().Where("user_id = ?", user.ID).Delete(&insights.ProgressInsight{}) })
+
+	first, err := repo.CreateOnce(&insights.ProgressInsight{UserID: user.ID, Period: "2024-02", Summary: "first"})
+	require.NoError(t, err)
+	require.NotZero(t, first.ID)
+
+	// A concurrent generation gets the insight that won
+	second, err := repo.CreateOnce(&insights.ProgressInsight{UserID: user.ID, Period: "2024-02", Summary: "second"})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, "first", second.Summary)
+
+	// Weeks and custom ranges are kept apart from months
+	week, err := repo.CreateOnce(&insights.ProgressInsight{UserID: user.ID, Period: "2024-W07", Summary: "week"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, week.ID)
+
+	ranged, err := repo.CreateOnce(&insights.ProgressInsight{UserID: user.ID, Period: "2024-01-10..2024-02-05", Summary: "range"})
+	require.NoError(t, err)
+	assert.NotEqual(t, week.ID, ranged.ID)
+
+	// Deleted insights don't block a new one for the period
+	require.NoError(t, db.Delete(&insights.ProgressInsight{}, week.ID).Error)
+	third, err := repo.CreateOnce(&insights.ProgressInsight{UserID: user.ID, Period: "2024-W07", Summary: "third"})
+	require.NoError(t, err)
+	assert.NotEqual(t, week.ID, third.ID)
+	assert.Equal(t, "third", third.Summary)
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -35,7 +36,9 @@ func TestJournalEndpoints(t *testing.T) {
 
 	// Initialize server and set up routes
 	logger := zap.NewNop()
-	server.SetupRoutes(router, db, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.SetupRoutes(ctx, router, db, logger)
 
 	// First create a test user and get token
 	registerData := map[string]string{
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"armourup/internal/domain/openai"
+	"armourup/internal/llm"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	req := llm.Request{Messages: []llm.Message{{Role: llm.RoleUser, Content: "hello there"}}}
+
+	t.Run("Replays Script And Repeats Last", func(t *testing.T) {
+		fake := llm.NewFake("first", "second")
+		for _, want := range []string{"first", "second", "second"} {
+			resp, err := fake.Chat(ctx, req)
+			assert.NoError(t, err)
+			assert.Equal(t, want, resp.Content)
+		}
+		assert.Len(t, fake.Requests(), 3)
+	})
+
+	t.Run("Scripted Errors", func(t *testing.T) {
+		fake := llm.NewScriptedFake(
+			llm.FakeResponse{Err: errors.New("boom")},
+			llm.FakeResponse{Content: "ok"},
+		)
+		_, err := fake.Chat(ctx, req)
+		assert.EqualError(t, err, "boom")
+		resp, err := fake.Chat(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp.Content)
+	})
+
+	t.Run("Echoes Without Script", func(t *testing.T) {
+		resp, err := llm.NewFake().Chat(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello there", resp.Content)
+		assert.Equal(t, 2, resp.Usage.PromptTokens)
+	})
+
+	t.Run("Streams Whole Response", func(t *testing.T) {
+		fake := llm.NewFake("Be strong and courageous.")
+		stream, err := fake.ChatStream(ctx, req)
+		assert.NoError(t, err)
+
+		first, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "Be ", first)
+
+		rest, err := llm.ReadAll(stream)
+		assert.NoError(t, err)
+		assert.Equal(t, "Be strong and courageous.", first+rest)
+	})
+
+	t.Run("Respects Cancelled Context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := llm.NewFake("x").Chat(cancelled, req)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestProviderFromConfig(t *testing.T) {
+	defer func() {
+		viper.Set("llm.provider", "openai")
+		viper.Set("llm.base_url", "")
+		viper.Set("llm.model", "")
+	}()
+
+	viper.Set("llm.provider", "fake")
+	provider, err := llm.NewFromConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", provider.Name())
+
+	viper.Set("llm.provider", "local")
+	viper.Set("llm.base_url", "")
+	_, err = llm.NewFromConfig()
+	assert.Error(t, err)
+
+	viper.Set("llm.base_url", "http://localhost:11434/v1")
+	viper.Set("llm.model", "llama3")
+	provider, err = llm.NewFromConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "local", provider.Name())
+
+	viper.Set("llm.provider", "unknown")
+	_, err = llm.NewFromConfig()
+	assert.Error(t, err)
+}
+
+func TestEncouragementWithFakeProvider(t *testing.T) {
+	fake := llm.NewFake(`{"verse": "Joshua 1:9", "message": "The Lord is with you"}`)
+	service := openai.NewService(fake, builtinPrompts(t), nil, nil)
+
+	resp, err := service.GetEncouragement(context.Background(), "I'm afraid")
+	assert.NoError(t, err)
+	assert.Equal(t, "Joshua 1:9", resp.Verse)
+	assert.Equal(t, "The Lord is with you", resp.Message)
+
+	requests := fake.Requests()
+	assert.Len(t, requests, 1)
+	assert.True(t, requests[0].JSON)
+	assert.Contains(t, requests[0].Messages[0].Content, "I'm afraid")
+}
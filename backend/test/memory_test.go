@@ -0,0 +1,99 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"armourup/internal/domain/memory"
+	"armourup/internal/scripture"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryScheduling(t *testing.T) {
+	now := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("Successful Reviews Grow The Interval", func(t *testing.T) {
+		verse := &memory.MemoryVerse{EaseFactor: 2.5}
+
+		memory.Schedule(verse, 5, now)
+		assert.Equal(t, 1, verse.Repetitions)
+		assert.Equal(t, 1, verse.IntervalDays)
+		assert.Equal(t, now.AddDate(0, 0, 1), verse.DueAt)
+
+		memory.Schedule(verse, 4, now)
+		assert.Equal(t, 2, verse.Repetitions)
+		assert.Equal(t, 6, verse.IntervalDays)
+
+		memory.Schedule(verse, 4, now)
+		assert.Equal(t, 3, verse.Repetitions)
+		assert.Equal(t, 16, verse.IntervalDays)
+		assert.InDelta(t, 2.6, verse.EaseFactor, 0.001)
+	})
+
+	t.Run("Forgotten Verse Starts Over", func(t *testing.T) {
+		verse := &memory.MemoryVerse{EaseFactor: 2.5, Repetitions: 4, IntervalDays: 30}
+
+		memory.Schedule(verse, 1, now)
+		assert.Equal(t, 0, verse.Repetitions)
+		assert.Equal(t, 1, verse.IntervalDays)
+		assert.Equal(t, 1, verse.Lapses)
+		assert.Less(t, verse.EaseFactor, 2.5)
+	})
+
+	t.Run("Ease Factor Has A Floor", func(t *testing.T) {
+		verse := &memory.MemoryVerse{EaseFactor: 1.3}
+
+		memory.Schedule(verse, 0, now)
+		assert.Equal(t, 1.3, verse.EaseFactor)
+	})
+}
+
+func TestMemoryCloze(t *testing.T) {
+	text := "Trust in the LORD with all your heart, and lean not unto thine own understanding."
+
+	t.Run("Blanks Match The Hidden Words", func(t *testing.T) {
+		prompt, blanks := memory.GenerateCloze(text, 0, 42)
+		assert.NotEmpty(t, blanks)
+
+		words := strings.Fields(prompt)
+		original := strings.Fields(text)
+		for _, b := range blanks {
+			assert.Contains(t, words[b.Position], "_____")
+			assert.Contains(t, original[b.Position], b.Answer)
+		}
+	})
+
+	t.Run("Same Seed Gives Same Quiz", func(t *testing.T) {
+		p1, b1 := memory.GenerateCloze(text, 2, 7)
+		p2, b2 := memory.GenerateCloze(text, 2, 7)
+		assert.Equal(t, p1, p2)
+		assert.Equal(t, b1, b2)
+	})
+
+	t.Run("More Words Hidden As Verse Matures", func(t *testing.T) {
+		_, early := memory.GenerateCloze(text, 0, 1)
+		_, late := memory.GenerateCloze(text, 4, 1)
+		assert.Greater(t, len(late), len(early))
+	})
+}
+
+func TestScriptureSplitVerse(t *testing.T) {
+	cases := []struct {
+		verse     string
+		reference string
+		text      string
+	}{
+		{`"I can do all things through Christ who strengthens me." - Philippians 4:13`, "Philippians 4:13", "I can do all things through Christ who strengthens me."},
+		{"1 Peter 5:7 - Cast all your anxiety on him because he cares for you.", "1 Peter 5:7", "Cast all your anxiety on him because he cares for you."},
+		{"Love is patient, love is kind. (1 Corinthians 13:4-7)", "1 Corinthians 13:4-7", "Love is patient, love is kind."},
+		{"Just some text", "", "Just some text"},
+	}
+
+	for _, c := range cases {
+		reference, text := scripture.SplitVerse(c.verse)
+		assert.Equal(t, c.reference, reference, c.verse)
+		assert.Equal(t, c.text, text, c.verse)
+	}
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"testing"
+
+	"armourup/internal/domain/mood"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// practiceDays builds days with the given energy levels, praying on the
+// first prayed of them
+func practiceDays(prayed int, energy ...int) []mood.PracticeDay {
+	days := make([]mood.PracticeDay, len(energy))
+	for i, level := range energy {
+		days[i] = mood.PracticeDay{EnergyLevel: level, Prayer: i < prayed}
+	}
+	return days
+}
+
+func findCorrelation(t *testing.T, correlations []mood.Correlation, practice, measure string) mood.Correlation {
+	for _, c := range correlations {
+		if c.Practice == practice && c.Measure == measure {
+			return c
+		}
+	}
+	require.Failf(t, "correlation not found", "%s %s", practice, measure)
+	return mood.Correlation{}
+}
+
+func TestCorrelateDifference(t *testing.T) {
+	correlations := mood.Correlate(practiceDays(5, 8, 7, 8, 9, 8, 4, 5, 4, 3, 4))
+	require.Len(t, correlations, 8)
+
+	prayer := findCorrelation(t, correlations, mood.PracticePrayer, mood.MeasureEnergy)
+	assert.Equal(t, 5, prayer.DaysWith)
+	assert.Equal(t, 5, prayer.DaysWithout)
+	assert.Equal(t, 8.0, *prayer.MeanWith)
+	assert.Equal(t, 4.0, *prayer.MeanWithout)
+	assert.Equal(t, 4.0, *prayer.Difference)
+	assert.Equal(t, 5.66, *prayer.EffectSize)
+	// Welch's test has 8 degrees of freedom here, so t is 2.306
+	assert.Equal(t, 2.97, *prayer.CILow)
+	assert.Equal(t, 5.03, *prayer.CIHigh)
+	assert.Equal(t, mood.ConfidenceModerate, prayer.Confidence)
+}
+
+func TestCorrelateGuardsTinySamples(t *testing.T) {
+	correlations := mood.Correlate(practiceDays(4, 9, 9, 9, 9, 2, 2, 2, 2, 2, 2))
+
+	prayer := findCorrelation(t, correlations, mood.PracticePrayer, mood.MeasureEnergy)
+	assert.Equal(t, 4, prayer.DaysWith)
+	assert.Equal(t, mood.ConfidenceInsufficient, prayer.Confidence)
+	assert.Nil(t, prayer.MeanWith)
+	assert.Nil(t, prayer.Difference)
+	assert.Nil(t, prayer.EffectSize)
+
+	// Nobody journaled, so there is nothing to compare with
+	journal := findCorrelation(t, correlations, mood.PracticeJournal, mood.MeasureEnergy)
+	assert.Equal(t, 0, journal.DaysWith)
+	assert.Equal(t, 10, journal.DaysWithout)
+	assert.Equal(t, mood.ConfidenceInsufficient, journal.Confidence)
+}
+
+func TestCorrelateNoClearDifference(t *testing.T) {
+	correlations := mood.Correlate(practiceDays(5, 5, 6, 7, 5, 6, 6, 5, 7, 6, 5))
+
+	prayer := findCorrelation(t, correlations, mood.PracticePrayer, mood.MeasureEnergy)
+	assert.Equal(t, 0.0, *prayer.Difference)
+	assert.Less(t, *prayer.CILow, 0.0)
+	assert.Greater(t, *prayer.CIHigh, 0.0)
+	assert.Equal(t, mood.ConfidenceLow, prayer.Confidence)
+}
+
+func TestCorrelateHighConfidence(t *testing.T) {
+	var energy []int
+	for i := 0; i < 20; i++ {
+		energy = append(energy, 7+i%2)
+	}
+	for i := 0; i < 20; i++ {
+		energy = append(energy, 5+i%2)
+	}
+
+	prayer := findCorrelation(t, mood.Correlate(practiceDays(20, energy...)), mood.PracticePrayer, mood.MeasureEnergy)
+	assert.Equal(t, 2.0, *prayer.Difference)
+	assert.Equal(t, mood.ConfidenceHigh, prayer.Confidence)
+}
+
+func TestCorrelateValenceSkipsUnscoredDays(t *testing.T) {
+	days := practiceDays(6, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5)
+	for i := range days {
+		valence := -0.5
+		if days[i].Prayer {
+			valence = 0.5
+		}
+		if i != 0 && i != 11 {
+			days[i].Valence = &valence
+		}
+	}
+
+	valence := findCorrelation(t, mood.Correlate(days), mood.PracticePrayer, mood.MeasureValence)
+	assert.Equal(t, 5, valence.DaysWith)
+	assert.Equal(t, 5, valence.DaysWithout)
+	assert.Equal(t, 1.0, *valence.Difference)
+	assert.Equal(t, mood.ConfidenceModerate, valence.Confidence)
+
+	// Without any spread the effect size is undefined, but shows in findings
+	assert.Nil(t, valence.EffectSize)
+	assert.Equal(t, []string{
+		"On days you prayed, your mood was more positive than on days you didn't (5 and 5 days).",
+	}, mood.StrongestFindings(mood.Correlate(days), mood.MaxCorrelationFindings))
+}
+
+func TestStrongestFindings(t *testing.T) {
+	small, large, negative := 0.6, 1.4, -1.1
+	diff, mean := 1.0, 6.5
+	correlations := []mood.Correlation{
+		{Practice: mood.PracticeJournal, Measure: mood.MeasureEnergy, DaysWith: 10, DaysWithout: 12, MeanWith: &mean, Difference: &diff, EffectSize: &small, Confidence: mood.ConfidenceModerate},
+		{Practice: mood.PracticeReading, Measure: mood.MeasureEnergy, DaysWith: 25, DaysWithout: 30, MeanWith: &mean, Difference: &negative, EffectSize: &negative, Confidence: mood.ConfidenceHigh},
+		{Practice: mood.PracticePrayer, Measure: mood.MeasureValence, DaysWith: 9, DaysWithout: 7, Difference: &diff, EffectSize: &large, Confidence: mood.ConfidenceLow},
+		{Practice: mood.PracticeGratitude, Measure: mood.MeasureEnergy, Confidence: mood.ConfidenceInsufficient},
+	}
+
+	assert.Equal(t, []string{
+		"On days you read the Bible, your energy averaged 6.5, 1.1 lower than on days you didn't (25 and 30 days).",
+		"On days you journaled, your energy averaged 6.5, 1.0 higher than on days you didn't (10 and 12 days).",
+	}, mood.StrongestFindings(correlations, 3))
+
+	strongest := mood.StrongestCorrelations(correlations, 1)
+	require.Len(t, strongest, 1)
+	assert.Equal(t, mood.PracticeReading, strongest[0].Practice)
+
+	assert.Equal(t, []string{}, mood.StrongestFindings(nil, 3))
+}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"testing"
+
+	"armourup/internal/domain/mood"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDayOfWeekPattern(t *testing.T) {
+	pattern := mood.DayOfWeekPattern(map[int]mood.DayOfWeekStats{
+		1: {Entries: 4, AvgEnergyLevel: 5.25},
+		7: {Entries: 2, AvgEnergyLevel: 8},
+	})
+
+	require.Len(t, pattern, 7)
+	assert.Equal(t, mood.DayOfWeekStats{Day: "Monday", Entries: 4, AvgEnergyLevel: 5.25}, pattern[0])
+	assert.Equal(t, mood.DayOfWeekStats{Day: "Wednesday"}, pattern[2])
+	assert.Equal(t, mood.DayOfWeekStats{Day: "Sunday", Entries: 2, AvgEnergyLevel: 8}, pattern[6])
+}
+
+func TestDayOfWeekPatternWithoutEntries(t *testing.T) {
+	pattern := mood.DayOfWeekPattern(nil)
+
+	require.Len(t, pattern, 7)
+	for _, day := range pattern {
+		assert.Zero(t, day.Entries)
+	}
+	assert.Equal(t, "Tuesday", pattern[1].Day)
+}
+
+func TestGetHeatmapRejectsInvalidYear(t *testing.T) {
+	service := mood.NewService(mood.NewRepository(nil))
+
+	_, err := service.GetHeatmap(1, 0)
+	assert.EqualError(t, err, "invalid year")
+	_, err = service.GetHeatmap(1, 10000)
+	assert.EqualError(t, err, "invalid year")
+}
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"testing"
+
+	"armourup/internal/config"
+	"armourup/internal/domain/mood"
+	"armourup/test/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vocabularyFixture() []mood.MoodTerm {
+	return []mood.MoodTerm{
+		{Kind: mood.KindEmotional, Key: "joyful", Label: "Joyful", Labels: map[string]string{"es": "Alegre"}, Synonyms: []string{"happy", "glad"}, Valence: 0.9, Arousal: 0.7},
+		{Kind: mood.KindEmotional, Key: "peaceful", Label: "Peaceful", Synonyms: []string{"calm"}, Valence: 0.8, Arousal: -0.6},
+		{Kind: mood.KindEmotional, Key: "overwhelmed", Label: "Overwhelmed", Synonyms: []string{"burned out", "peaceful"}, Valence: -0.7, Arousal: 0.5},
+		{Kind: mood.KindSpiritual, Key: "connected", Label: "Connected to God", Labels: map[string]string{"es": "Conectado con Dios", "pt-BR": "Conectado com Deus"}, Valence: 0.9},
+	}
+}
+
+func TestVocabularyResolve(t *testing.T) {
+	vocabulary := mood.NewVocabulary(vocabularyFixture())
+
+	for _, value := range []string{"joyful", "Joyful", "  HAPPY ", "glad", "alegre"} {
+		term, ok := vocabulary.Resolve(mood.KindEmotional, value)
+		require.True(t, ok, value)
+		assert.Equal(t, "joyful", term.Key, value)
+	}
+
+	term, ok := vocabulary.Resolve(mood.KindEmotional, "Burned_Out")
+	require.True(t, ok)
+	assert.Equal(t, "overwhelmed", term.Key)
+
+	term, ok = vocabulary.Resolve(mood.KindSpiritual, "connected to god")
+	require.True(t, ok)
+	assert.Equal(t, "connected", term.Key)
+
+	_, ok = vocabulary.Resolve(mood.KindEmotional, "connected")
+	assert.False(t, ok, "terms only resolve within their kind")
+	_, ok = vocabulary.Resolve(mood.KindEmotional, "bored")
+	assert.False(t, ok)
+}
+
+func TestVocabularyKeysWinOverSynonyms(t *testing.T) {
+	term, ok := mood.NewVocabulary(vocabularyFixture()).Resolve(mood.KindEmotional, "peaceful")
+	require.True(t, ok)
+	assert.Equal(t, "peaceful", term.Key)
+}
+
+func TestVocabularyHas(t *testing.T) {
+	vocabulary := mood.NewVocabulary(vocabularyFixture()[:3])
+	assert.True(t, vocabulary.Has(mood.KindEmotional))
+	assert.False(t, vocabulary.Has(mood.KindSpiritual))
+}
+
+func TestMoodTermLabelFor(t *testing.T) {
+	term := vocabularyFixture()[3]
+
+	assert.Equal(t, "Conectado con Dios", term.LabelFor("es"))
+	assert.Equal(t, "Conectado con Dios", term.LabelFor("es-MX"))
+	assert.Equal(t, "Conectado com Deus", term.LabelFor("pt_br"))
+	assert.Equal(t, "Connected to God", term.LabelFor("fr-FR"))
+	assert.Equal(t, "Connected to God", term.LabelFor(""))
+}
+
+func TestLocalize(t *testing.T) {
+	vocabulary := mood.Localize(vocabularyFixture(), "es-ES")
+
+	assert.Equal(t, "es-ES", vocabulary.Locale)
+	require.Len(t, vocabulary.Emotional, 3)
+	assert.Equal(t, mood.LocalizedTerm{Key: "joyful", Label: "Alegre", Valence: 0.9, Arousal: 0.7}, vocabulary.Emotional[0])
+	assert.Equal(t, "Peaceful", vocabulary.Emotional[1].Label)
+	require.Len(t, vocabulary.Spiritual, 1)
+	assert.Equal(t, "Conectado con Dios", vocabulary.Spiritual[0].Label)
+
+	empty := mood.Localize(nil, "")
+	assert.NotNil(t, empty.Emotional)
+	assert.NotNil(t, empty.Spiritual)
+}
+
+func TestPlanMapping(t *testing.T) {
+	vocabulary := mood.NewVocabulary(vocabularyFixture())
+
+	mapped, unmapped := mood.PlanMapping(vocabulary, mood.KindEmotional, map[string]int{
+		"joyful": 10,
+		"Happy":  3,
+		"happy":  2,
+		"Calm":   1,
+		"bored":  4,
+	})
+
+	assert.Equal(t, []mood.StateMapping{
+		{Kind: mood.KindEmotional, From: "Calm", To: "peaceful", Entries: 1},
+		{Kind: mood.KindEmotional, From: "Happy", To: "joyful", Entries: 3},
+		{Kind: mood.KindEmotional, From: "happy", To: "joyful", Entries: 2},
+	}, mapped)
+	assert.Equal(t, []mood.StateMapping{
+		{Kind: mood.KindEmotional, From: "bored", Entries: 4},
+	}, unmapped)
+}
+
+func TestNormaliseState(t *testing.T) {
+	assert.Equal(t, "burned out", mood.NormaliseState("  Burned--Out "))
+	assert.Equal(t, "close to god", mood.NormaliseState("close_to_God"))
+}
+
+func TestMoodTermKeyRename(t *testing.T) {
+	SetupTestConfig(t)
+	t.Cleanup(func() { TeardownTestConfig(t) })
+	require.NoError(t, config.LoadConfig())
+	db := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.TeardownTestDB(t, db) })
+
+	service := mood.NewService(mood.NewRepository(db))
+	require.NoError(t, service.SeedDefaultVocabulary())
+	terms, err := service.GetTerms()
+	require.NoError(t, err)
+	var spiritual string
+	for _, term := range terms {
+		if term.Kind == mood.KindSpiritual {
+			spiritual = term.Key
+			break
+		}
+	}
+	require.NotEmpty(t, spiritual)
+
+	term := &mood.MoodTerm{Kind: mood.KindEmotional, Key: "rename_from", Label: "Renamed"}
+	require.NoError(t, service.AddTerm(term))
+	t.Cleanup(func() { db.Unscoped().Delete(&mood.MoodTerm{}, term.ID) })
+
+	user := testutils.CreateTestUser(t, db, "moodrename@example.com", "password123")
+	t.Cleanup(func() { db.Unscoped().Where("user_id = ?", user.ID).Delete(&mood.MoodEntry{}) })
+	entry, err := service.CreateEntry(user.ID, mood.CreateMoodEntryRequest{EmotionalState: "rename_from", SpiritualState: spiritual, EnergyLevel: 5})
+	require.NoError(t, err)
+
+	updated, err := service.UpdateTerm(term.ID, &mood.MoodTerm{Kind: mood.KindEmotional, Key: "Rename To", Label: "Renamed"})
+	require.NoError(t, err)
+	assert.Equal(t, "rename_to", updated.Key)
+
+	// Entries follow the term to its new key
+	stored, err := service.GetEntry(entry.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "rename_to", stored.EmotionalState)
+	assert.Equal(t, spiritual, stored.SpiritualState)
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -13,8 +14,11 @@ import (
 	"armourup/test/testutils"
 
 	"armourup/internal/domain/openai"
+	"armourup/internal/llm"
+	"armourup/internal/prompts"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -31,6 +35,11 @@ func TestOpenAIEndpoints(t *testing.T) {
 	err := config.LoadConfig()
 	assert.NoError(t, err)
 
+	// Use the scripted provider so no real API calls are made
+	viper.Set("llm.provider", "fake")
+	viper.Set("llm.fake_responses", []string{fakeEncouragement})
+	defer viper.Set("llm.provider", "openai")
+
 	// Initialize test database
 	db := testutils.SetupTestDB(t)
 	defer testutils.TeardownTestDB(t, db)
@@ -40,7 +49,9 @@ func TestOpenAIEndpoints(t *testing.T) {
 
 	// Initialize server and set up routes
 	logger := zap.NewNop()
-	server.SetupRoutes(router, db, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.SetupRoutes(ctx, router, db, logger)
 
 	// First create a test user and get token
 	registerData := map[string]string{
@@ -154,13 +165,13 @@ func TestOpenAIEndpoints(t *testing.T) {
 	})
 }
 
+const fakeEncouragement = `{"verse": "Philippians 4:13", "message": "You can do this"}`
+
 func setupRouter() *gin.Engine {
+	registry, _ := prompts.Load("")
 	router := gin.Default()
-	service, err := openai.NewService()
-	if err != nil {
-		panic(err)
-	}
-	openaiController := openai.NewController(service)
+	service := openai.NewService(llm.NewFake(fakeEncouragement), registry, nil, nil)
+	openaiController := openai.NewController(service, nil, nil)
 	router.POST("/api/ai/encourage", openaiController.GetEncouragement)
 	return router
 }
@@ -6,6 +6,7 @@ import (
 	"armourup/internal/server"
 	"armourup/test/testutils"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -35,7 +36,9 @@ func TestPrayerTracking(t *testing.T) {
 
 	// Initialize server and set up routes
 	logger := zap.NewNop()
-	server.SetupRoutes(router, db, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.SetupRoutes(ctx, router, db, logger)
 
 	// Helper function to create a test user
 	createUser := func(email, username string) (string, uint) {
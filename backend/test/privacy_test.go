@@ -0,0 +1,101 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"armourup/internal/domain/insights"
+	"armourup/internal/domain/openai"
+	"armourup/internal/domain/privacy"
+	"armourup/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		count    int
+	}{
+		{"Email", "Write to me at jo.bloggs+test@example.co.uk please", "Write to me at [EMAIL] please", 1},
+		{"Phone", "Call +44 7700 900123 or (555) 123-4567", "Call [PHONE] or [PHONE]", 2},
+		{"Short Numbers Kept", "I read Psalm 23 for 10 minutes on 3 days", "I read Psalm 23 for 10 minutes on 3 days", 0},
+		{"Street Address", "We moved to 42 Station Road last year", "We moved to [ADDRESS] last year", 1},
+		{"Postcode", "Our church is in SW1A 1AA", "Our church is in [ADDRESS]", 1},
+		{"Relation Name", "My sister Anna Smith is unwell", "My sister [NAME] is unwell", 1},
+		{"Named", "I met a man called Tom at church", "I met a man called [NAME] at church", 1},
+		{"Faith Words Kept", "My Father God is faithful", "My Father God is faithful", 0},
+		{"Nothing To Redact", "Feeling anxious about exams", "Feeling anxious about exams", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted, count := privacy.Redact(tt.input)
+			assert.Equal(t, tt.expected, redacted)
+			assert.Equal(t, tt.count, count)
+		})
+	}
+}
+
+func TestValidSharing(t *testing.T) {
+	assert.True(t, privacy.ValidSharing(privacy.SharingNone))
+	assert.True(t, privacy.ValidSharing(privacy.SharingAggregates))
+	assert.True(t, privacy.ValidSharing(privacy.SharingFull))
+	assert.False(t, privacy.ValidSharing("everything"))
+}
+
+func TestDisclosure(t *testing.T) {
+	var service *privacy.Service
+	disclosure := service.Begin(1)
+	assert.Equal(t, privacy.SharingFull, disclosure.Sharing, "without a service nothing is restricted")
+
+	aggregates := &privacy.Disclosure{Sharing: privacy.SharingAggregates}
+	assert.True(t, aggregates.AllowsAggregates())
+	assert.False(t, aggregates.AllowsText())
+
+	none := &privacy.Disclosure{Sharing: privacy.SharingNone}
+	assert.False(t, none.AllowsAggregates())
+	assert.False(t, none.AllowsText())
+}
+
+func TestInsightPromptDataFollowsSharing(t *testing.T) {
+	data := &insights.InsightData{
+		Period:         "2025-01",
+		MoodEntries:    []insights.MoodSummary{{ID: 5, EmotionalState: "peaceful", Notes: "Lunch with my friend Sarah"}},
+		JournalEntries: []insights.JournalSummary{{ID: 12, Content: "Email me at me@example.com"}},
+	}
+
+	t.Run("Aggregates Only", func(t *testing.T) {
+		promptData := insights.BuildPromptData(data, &privacy.Disclosure{Sharing: privacy.SharingAggregates})
+		assert.Empty(t, promptData.JournalExcerpts)
+		assert.Empty(t, promptData.MoodNotes)
+	})
+
+	t.Run("Full Text Is Redacted", func(t *testing.T) {
+		promptData := insights.BuildPromptData(data, &privacy.Disclosure{Sharing: privacy.SharingFull})
+		assert.Equal(t, []string{"Email me at [EMAIL]"}, promptData.JournalExcerpts)
+		assert.Equal(t, []string{"Lunch with my friend [NAME]"}, promptData.MoodNotes)
+	})
+}
+
+func TestEncouragementInputIsRedacted(t *testing.T) {
+	fake := llm.NewFake(fakeEncouragement)
+	service := openai.NewService(fake, builtinPrompts(t), nil, nil)
+
+	_, err := service.GetEncouragement(context.Background(), "My boss Mark emailed mark@work.com again")
+	require.NoError(t, err)
+
+	requests := fake.Requests()
+	require.Len(t, requests, 1)
+	var sent strings.Builder
+	for _, m := range requests[0].Messages {
+		sent.WriteString(m.Content)
+	}
+	assert.NotContains(t, sent.String(), "mark@work.com")
+	assert.NotContains(t, sent.String(), "Mark")
+	assert.Contains(t, sent.String(), "My boss [NAME] emailed [EMAIL] again")
+}
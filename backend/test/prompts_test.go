@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"armourup/internal/domain/insights"
+	"armourup/internal/domain/openai"
+	"armourup/internal/llm"
+	"armourup/internal/prompts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// builtinPrompts loads the embedded prompt templates
+func builtinPrompts(t *testing.T) *prompts.Registry {
+	t.Helper()
+	registry, err := prompts.Load("")
+	require.NoError(t, err)
+	return registry
+}
+
+func TestBuiltinPrompts(t *testing.T) {
+	registry := builtinPrompts(t)
+
+	version, err := registry.Select("encouragement", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "encouragement@v1", version.ID())
+
+	req, err := version.Request(struct{ Input string }{Input: "anxious about exams"})
+	require.NoError(t, err)
+	require.Len(t, req.Messages, 1)
+	assert.Equal(t, llm.RoleUser, req.Messages[0].Role)
+	assert.Contains(t, req.Messages[0].Content, "Situation: anxious about exams")
+
+	version, err = registry.Get("insights", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4-turbo-preview", version.Model)
+	messages, err := version.Messages(struct {
+		*insights.InsightData
+		JournalExcerpts []string
+		MoodNotes       []string
+	}{
+		InsightData:     &insights.InsightData{Period: "2025-01", AvgEnergyLevel: 6.25},
+		JournalExcerpts: []string{"Learning to trust"},
+	})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, llm.RoleSystem, messages[0].Role)
+	assert.Contains(t, messages[1].Content, "summary for 2025-01")
+	assert.Contains(t, messages[1].Content, "Average energy level: 6.2/10")
+	assert.Contains(t, messages[1].Content, "- Learning to trust")
+	assert.NotContains(t, messages[1].Content, "Notable Mood Notes")
+
+	_, err = registry.Select("missing", 1)
+	assert.Error(t, err)
+}
+
+func TestPromptOverridesFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"encouragement": {"default": "v1", "versions": {
+		"v1": {"file": "encouragement.v1.tmpl", "weight": 0},
+		"v2": {"file": "encouragement.v2.tmpl", "model": "llama3", "temperature": 0.2, "weight": 1}
+	}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(manifest), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "encouragement.v2.tmpl"), []byte(`{{define "user"}}Help with: {{.Input}}{{end}}`), 0o644))
+
+	registry, err := prompts.Load(dir)
+	require.NoError(t, err)
+
+	// Only v2 has weight, so every user is assigned it
+	version, err := registry.Select("encouragement", 42)
+	require.NoError(t, err)
+	assert.Equal(t, "encouragement@v2", version.ID())
+	assert.Equal(t, "llama3", version.Model)
+	assert.Equal(t, float32(0.2), version.Temperature)
+	req, err := version.Request(struct{ Input string }{Input: "grief"})
+	require.NoError(t, err)
+	assert.Equal(t, "Help with: grief", req.Messages[0].Content)
+
+	// v1 still comes from the embedded template, and calls for no user use the default
+	version, err = registry.Select("encouragement", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "encouragement@v1", version.ID())
+
+	// Prompts not in the override manifest are kept
+	_, err = registry.Get("insights", "v1")
+	assert.NoError(t, err)
+}
+
+func TestPromptOverrideRequiresUserTemplate(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"encouragement": {"default": "v1", "versions": {"v1": {"file": "bad.tmpl"}}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(manifest), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.tmpl"), []byte(`{{define "system"}}Only a system prompt{{end}}`), 0o644))
+
+	_, err := prompts.Load(dir)
+	assert.Error(t, err)
+}
+
+func TestAssignPromptVersion(t *testing.T) {
+	weights := map[string]int{"v1": 50, "v2": 50}
+
+	counts := map[string]int{}
+	for userID := uint(1); userID <= 1000; userID++ {
+		version := prompts.Assign("encouragement", userID, "v1", weights)
+		// Assignment is stable for a user
+		assert.Equal(t, version, prompts.Assign("encouragement", userID, "v1", weights))
+		counts[version]++
+	}
+	assert.InDelta(t, 500, counts["v1"], 100)
+	assert.InDelta(t, 500, counts["v2"], 100)
+
+	assert.Equal(t, "v1", prompts.Assign("encouragement", 0, "v1", weights))
+	assert.Equal(t, "v3", prompts.Assign("encouragement", 5, "v3", map[string]int{"v1": 0}))
+}
+
+func TestEncouragementRecordsPromptVersion(t *testing.T) {
+	fake := llm.NewFake(`{"verse": "Matthew 11:28", "message": "Come and rest"}`)
+	service := openai.NewService(fake, builtinPrompts(t), nil, nil)
+
+	resp, err := service.GetEncouragement(llm.WithCaller(context.Background(), 3, "encouragement"), "tired")
+	require.NoError(t, err)
+	assert.Equal(t, "encouragement@v1", resp.PromptVersion)
+	assert.Equal(t, float32(0.7), fake.Requests()[0].Temperature)
+	assert.True(t, fake.Requests()[0].JSON)
+}
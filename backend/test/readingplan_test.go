@@ -0,0 +1,97 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"armourup/internal/domain/readingplan"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadingPlanDefinitions(t *testing.T) {
+	defs, err := readingplan.LoadBuiltinPlans()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, defs)
+
+	t.Run("Every Built-in Plan Expands", func(t *testing.T) {
+		for _, def := range defs {
+			days, err := def.ExpandDays()
+			assert.NoError(t, err, def.Slug)
+			assert.Len(t, days, def.Days, def.Slug)
+			for _, day := range days {
+				assert.NotEmpty(t, day.Passages, "%s day %d", def.Slug, day.DayNumber)
+			}
+		}
+	})
+
+	t.Run("Psalms Split Evenly", func(t *testing.T) {
+		def := readingplan.PlanDefinition{
+			Slug:  "psalms",
+			Days:  30,
+			Books: []readingplan.BookDefinition{{Name: "Psalms", Chapters: 150}},
+		}
+		days, err := def.ExpandDays()
+		assert.NoError(t, err)
+		assert.Equal(t, "Psalms 1-5", days[0].Passages)
+		assert.Equal(t, "Psalms 146-150", days[29].Passages)
+	})
+
+	t.Run("Readings Cross Book Boundaries", func(t *testing.T) {
+		def := readingplan.PlanDefinition{
+			Slug: "short",
+			Days: 2,
+			Books: []readingplan.BookDefinition{
+				{Name: "Jude", Chapters: 1},
+				{Name: "Philemon", Chapters: 1},
+				{Name: "3 John", Chapters: 1},
+			},
+		}
+		days, err := def.ExpandDays()
+		assert.NoError(t, err)
+		assert.Equal(t, "Jude 1", days[0].Passages)
+		assert.Equal(t, "Philemon 1; 3 John 1", days[1].Passages)
+	})
+
+	t.Run("Fewer Chapters Than Days Is Rejected", func(t *testing.T) {
+		def := readingplan.PlanDefinition{
+			Slug:  "too-long",
+			Days:  10,
+			Books: []readingplan.BookDefinition{{Name: "Jude", Chapters: 1}},
+		}
+		_, err := def.ExpandDays()
+		assert.Error(t, err)
+	})
+}
+
+func TestReadingPlanCatchUpSchedule(t *testing.T) {
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Missed Readings Are Spread Across Days", func(t *testing.T) {
+		missed := []int{2, 3, 5, 6}
+		done := map[int]bool{1: true, 4: true}
+
+		schedule := readingplan.BuildCatchUpSchedule(missed, 7, 30, done, from, 2)
+		assert.Len(t, schedule, 2)
+		assert.Equal(t, from, schedule[0].Date)
+		assert.Equal(t, []int{2, 3, 7}, schedule[0].DayNumbers)
+		assert.Equal(t, from.AddDate(0, 0, 1), schedule[1].Date)
+		assert.Equal(t, []int{5, 6, 8}, schedule[1].DayNumbers)
+	})
+
+	t.Run("Readings Already Done Are Skipped", func(t *testing.T) {
+		done := map[int]bool{1: true, 2: true, 3: true}
+
+		schedule := readingplan.BuildCatchUpSchedule(nil, 2, 30, done, from, 3)
+		assert.Len(t, schedule, 1)
+		assert.Equal(t, []int{4}, schedule[0].DayNumbers)
+		assert.Equal(t, from.AddDate(0, 0, 2), schedule[0].Date)
+	})
+
+	t.Run("Schedule Stops At End Of Plan", func(t *testing.T) {
+		missed := []int{28}
+		schedule := readingplan.BuildCatchUpSchedule(missed, 30, 30, map[int]bool{}, from, 3)
+		assert.Len(t, schedule, 1)
+		assert.Equal(t, []int{28, 30}, schedule[0].DayNumbers)
+	})
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"armourup/internal/domain/safety"
+	"armourup/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	high := safety.Classify("Some days I just want to die.")
+	assert.Equal(t, safety.RiskHigh, high.Risk)
+	assert.Equal(t, []string{safety.CategorySuicide}, high.Categories)
+	assert.Equal(t, "keywords", high.Detector)
+
+	medium := safety.Classify("I feel like there's no way out")
+	assert.Equal(t, safety.RiskMedium, medium.Risk)
+	assert.Equal(t, []string{safety.CategoryDespair}, medium.Categories)
+
+	none := safety.Classify("Grateful for a good walk with friends today")
+	assert.Equal(t, safety.RiskNone, none.Risk)
+	assert.Empty(t, none.Categories)
+	assert.Empty(t, none.Detector)
+}
+
+func TestClassifyNegation(t *testing.T) {
+	assert.Equal(t, safety.RiskLow, safety.Classify("I'm not suicidal, just tired").Risk)
+	assert.Equal(t, safety.RiskLow, safety.Classify("I would never hurt myself").Risk)
+	// A negation elsewhere in the sentence doesn't cancel the phrase
+	assert.Equal(t, safety.RiskHigh, safety.Classify("I can't sleep and I want to die").Risk)
+	// Only a negation directly before the phrase counts
+	assert.Equal(t, safety.RiskHigh, safety.Classify("I can no longer cope, suicide is all I think about").Risk)
+	assert.Equal(t, safety.RiskHigh, safety.Classify("I'm not really suicidal").Risk)
+	assert.Equal(t, safety.RiskLow, safety.Classify("I'm no longer suicidal").Risk)
+}
+
+func TestClassifyRepeatedPhrase(t *testing.T) {
+	// A denial doesn't hide a later mention of the same phrase
+	assert.Equal(t, safety.RiskHigh, safety.Classify("I told her I'm not suicidal, but honestly I am suicidal").Risk)
+	assert.Equal(t, safety.RiskHigh, safety.Classify("I don't want to die like this. I want to die.").Risk)
+	// Denying every mention keeps the risk low
+	assert.Equal(t, safety.RiskLow, safety.Classify("I'm not suicidal. Really, I'm not suicidal.").Risk)
+}
+
+func TestMaxRisk(t *testing.T) {
+	assert.Equal(t, safety.RiskHigh, safety.MaxRisk(safety.RiskLow, safety.RiskHigh))
+	assert.Equal(t, safety.RiskMedium, safety.MaxRisk(safety.RiskMedium, safety.RiskNone))
+}
+
+func TestForLocale(t *testing.T) {
+	directory, err := safety.LoadDirectory("")
+	require.NoError(t, err)
+
+	assert.Equal(t, directory["en-GB"], directory.ForLocale("en-GB"))
+	assert.Equal(t, directory["en-GB"], directory.ForLocale("en_gb"))
+	// Another language in a known region uses that region's resources
+	assert.Equal(t, directory["en-CA"], directory.ForLocale("fr-CA"))
+	assert.Equal(t, directory["default"], directory.ForLocale("de-DE"))
+	assert.Equal(t, directory["default"], directory.ForLocale(""))
+	assert.NotEmpty(t, directory.ForLocale("en-US").Resources)
+}
+
+func TestLocaleFromHeader(t *testing.T) {
+	assert.Equal(t, "en-GB", safety.LocaleFromHeader("en-GB,en;q=0.9"))
+	assert.Equal(t, "fr-CA", safety.LocaleFromHeader("fr-CA;q=0.8"))
+	assert.Equal(t, "", safety.LocaleFromHeader(""))
+}
+
+func TestSafetyCheck(t *testing.T) {
+	directory, err := safety.LoadDirectory("")
+	require.NoError(t, err)
+	service := safety.NewService(nil, nil, nil, directory, safety.Options{DefaultLocale: "en-GB"})
+
+	result := service.Check(context.Background(), 1, safety.SourceJournal, "I want to end my life", "")
+	assert.True(t, result.ShortCircuit)
+	require.True(t, result.Flagged())
+	assert.Equal(t, directory["en-GB"].Resources, result.Notice.Resources)
+
+	result = service.Check(context.Background(), 1, safety.SourceMood, "Nobody would miss me", "en-AU")
+	assert.False(t, result.ShortCircuit)
+	require.True(t, result.Flagged())
+	assert.Equal(t, directory["en-AU"].Resources, result.Notice.Resources)
+
+	// Low risk is noted but doesn't show crisis resources
+	result = service.Check(context.Background(), 1, safety.SourceMood, "Feeling hopeless about work", "")
+	assert.Equal(t, safety.RiskLow, result.Risk)
+	assert.False(t, result.Flagged())
+}
+
+func TestSafetyCheckLLMModeration(t *testing.T) {
+	directory, err := safety.LoadDirectory("")
+	require.NoError(t, err)
+	provider := llm.NewFake(`{"risk": "high", "categories": ["self_harm"]}`)
+	service := safety.NewService(nil, provider, nil, directory, safety.Options{LLMModeration: true})
+
+	result := service.Check(context.Background(), 1, safety.SourceStruggle, "I keep thinking about the razor", "")
+	assert.Equal(t, safety.RiskHigh, result.Risk)
+	assert.Equal(t, "llm", result.Detector)
+	assert.True(t, result.ShortCircuit)
+
+	// Moderation that fails to parse leaves the keyword result standing
+	service = safety.NewService(nil, llm.NewFake("not json"), nil, directory, safety.Options{LLMModeration: true})
+	result = service.Check(context.Background(), 1, safety.SourceStruggle, "no way out", "")
+	assert.Equal(t, safety.RiskMedium, result.Risk)
+	assert.Equal(t, "keywords", result.Detector)
+
+	// A nil service checks nothing
+	var disabled *safety.Service
+	assert.False(t, disabled.Check(context.Background(), 1, safety.SourceJournal, "I want to die", "").Flagged())
+}
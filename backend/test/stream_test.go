@@ -0,0 +1,123 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"armourup/internal/domain/openai"
+	"armourup/internal/domain/usage"
+	"armourup/internal/llm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStreamedEncouragement(t *testing.T) {
+	t.Run("Message Then Verse Line", func(t *testing.T) {
+		resp, err := openai.ParseStreamedEncouragement("You are not alone.\nGod walks with you.\nVerse: \"I am with you always\" - Matthew 28:20")
+		assert.NoError(t, err)
+		assert.Equal(t, "You are not alone.\nGod walks with you.", resp.Message)
+		assert.Equal(t, "\"I am with you always\" - Matthew 28:20", resp.Verse)
+	})
+
+	t.Run("JSON Response", func(t *testing.T) {
+		resp, err := openai.ParseStreamedEncouragement(`{"verse": "Psalm 23:1", "message": "He is your shepherd"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "He is your shepherd", resp.Message)
+		assert.Equal(t, "Psalm 23:1", resp.Verse)
+	})
+
+	t.Run("Missing Verse", func(t *testing.T) {
+		resp, err := openai.ParseStreamedEncouragement("Keep going.")
+		assert.NoError(t, err)
+		assert.Equal(t, "Keep going.", resp.Message)
+		assert.Empty(t, resp.Verse)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		_, err := openai.ParseStreamedEncouragement("  ")
+		assert.Error(t, err)
+	})
+}
+
+func TestStreamEncouragementEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(service *openai.Service) *gin.Engine {
+		router := gin.New()
+		router.POST("/api/ai/encourage/stream", openai.NewController(service, nil, nil).StreamEncouragement)
+		return router
+	}
+
+	t.Run("Streams Deltas Then Done", func(t *testing.T) {
+		fake := llm.NewFake("Take heart today.\nVerse: \"Be still, and know that I am God\" - Psalm 46:10")
+		router := newRouter(openai.NewService(fake, builtinPrompts(t), nil, nil))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/ai/encourage/stream", strings.NewReader(`{"input": "I'm overwhelmed"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		body := w.Body.String()
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Contains(t, body, "event:delta\ndata:{\"content\":\"Take \"}")
+		assert.Contains(t, body, "event:done\n")
+		assert.Contains(t, body, `"message":"Take heart today."`)
+		assert.Contains(t, body, `Psalm 46:10`)
+		assert.Less(t, strings.Index(body, "event:delta"), strings.Index(body, "event:done"))
+	})
+
+	t.Run("Client Disconnect Stops Stream", func(t *testing.T) {
+		router := newRouter(openai.NewService(llm.NewFake("Never sent"), builtinPrompts(t), nil, nil))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/ai/encourage/stream", strings.NewReader(`{"input": "hello"}`)).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.NotContains(t, w.Body.String(), "event:")
+	})
+
+	t.Run("Quota Exceeded Before Streaming", func(t *testing.T) {
+		meter := &recordingMeter{deny: &usage.QuotaError{Scope: usage.ScopeDaily, ResetsAt: time.Now().Add(time.Hour)}}
+		router := newRouter(openai.NewService(llm.NewMetered(llm.NewFake("Never sent"), meter), builtinPrompts(t), nil, nil))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/ai/encourage/stream", strings.NewReader(`{"input": "hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		assert.Contains(t, w.Body.String(), `"scope":"daily"`)
+	})
+
+	t.Run("Unavailable Without Provider Or Library", func(t *testing.T) {
+		router := newRouter(nil)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/ai/encourage/stream", strings.NewReader(`{"input": "hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Contains(t, w.Body.String(), "event:error")
+	})
+
+	t.Run("Invalid Request", func(t *testing.T) {
+		router := newRouter(nil)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/ai/encourage/stream", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"armourup/internal/domain/openai"
+	"armourup/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = llm.Schema{
+	Name: "test",
+	Fields: []llm.Field{
+		{Name: "verse", Type: llm.TypeString, Required: true, MaxLength: 20},
+		{Name: "tags", Type: llm.TypeStringList, MaxItems: 2},
+	},
+}
+
+func TestSchemaValidate(t *testing.T) {
+	cleaned, problems := testSchema.Validate("```json\n{\"verse\": \"John 3:16\"}\n```")
+	assert.Empty(t, problems)
+	assert.Equal(t, `{"verse": "John 3:16"}`, cleaned)
+
+	_, problems = testSchema.Validate(`{"verse": "", "tags": ["a", "b", "c"]}`)
+	assert.Equal(t, []string{`"tags" must have at most 2 items`, `"verse" must not be empty`}, problems)
+
+	_, problems = testSchema.Validate(`{"verse": "` + strings.Repeat("x", 21) + `"}`)
+	assert.Equal(t, []string{`"verse" must be at most 20 characters`}, problems)
+
+	_, problems = testSchema.Validate(`{"verse": ["John 3:16"]}`)
+	assert.Equal(t, []string{`"verse" must be a string`}, problems)
+
+	_, problems = testSchema.Validate(`Here is your verse`)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "not a JSON object")
+
+	_, problems = testSchema.Validate("")
+	assert.Equal(t, []string{"response was empty"}, problems)
+}
+
+func TestSchemaJSONSchema(t *testing.T) {
+	doc := testSchema.JSONSchema()
+	assert.Contains(t, doc, `"required":["verse"]`)
+	assert.Contains(t, doc, `"maxItems":2`)
+	assert.Contains(t, doc, `"maxLength":20`)
+}
+
+func TestChatJSONRepairsInvalidResponse(t *testing.T) {
+	fake := llm.NewScriptedFake(
+		llm.FakeResponse{Content: `{"verse": ""}`},
+		llm.FakeResponse{Content: `{"verse": "Psalm 23:1"}`},
+	)
+
+	var out struct {
+		Verse string `json:"verse"`
+	}
+	_, err := llm.ChatJSON(context.Background(), fake, llm.Request{Messages: []llm.Message{{Role: llm.RoleUser, Content: "verse please"}}}, testSchema, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "Psalm 23:1", out.Verse)
+
+	requests := fake.Requests()
+	require.Len(t, requests, 2)
+	assert.True(t, requests[0].JSON)
+	// The repair request shows the model its answer and what was wrong with it
+	repair := requests[1].Messages
+	require.Len(t, repair, 3)
+	assert.Equal(t, llm.RoleAssistant, repair[1].Role)
+	assert.Contains(t, repair[2].Content, `"verse" must not be empty`)
+}
+
+func TestChatJSONSchemaError(t *testing.T) {
+	fake := llm.NewFake("not json at all")
+
+	var out map[string]interface{}
+	_, err := llm.ChatJSON(context.Background(), fake, llm.Request{}, testSchema, &out)
+
+	var schemaErr *llm.SchemaError
+	require.True(t, errors.As(err, &schemaErr))
+	assert.Equal(t, "test", schemaErr.Schema)
+	assert.Equal(t, "not json at all", schemaErr.Content)
+	assert.Len(t, fake.Requests(), 2)
+}
+
+func TestChatJSONTransportError(t *testing.T) {
+	fake := llm.NewScriptedFake(llm.FakeResponse{Err: errors.New("connection refused")})
+
+	var out map[string]interface{}
+	_, err := llm.ChatJSON(context.Background(), fake, llm.Request{}, testSchema, &out)
+	assert.Error(t, err)
+	assert.False(t, llm.IsSchemaError(err))
+	assert.Len(t, fake.Requests(), 1)
+}
+
+func TestChatJSONRepairsEmptyResponse(t *testing.T) {
+	fake := llm.NewScriptedFake(
+		llm.FakeResponse{Err: llm.ErrEmptyResponse},
+		llm.FakeResponse{Content: `{"verse": "Isaiah 41:10"}`},
+	)
+
+	var out struct {
+		Verse string `json:"verse"`
+	}
+	_, err := llm.ChatJSON(context.Background(), fake, llm.Request{}, testSchema, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "Isaiah 41:10", out.Verse)
+}
+
+func TestEncouragementSchemaErrorIsNotRetried(t *testing.T) {
+	fake := llm.NewFake(`{"verse": "Romans 8:28"}`)
+	service := openai.NewService(fake, builtinPrompts(t), nil, nil)
+
+	_, err := service.GetEncouragement(context.Background(), "lost my job")
+	assert.True(t, llm.IsSchemaError(err))
+	// One request and one repair, with no transport retries
+	assert.Len(t, fake.Requests(), 2)
+}
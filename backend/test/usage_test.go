@@ -0,0 +1,235 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"armourup/internal/domain/usage"
+	"armourup/internal/llm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPricingCost(t *testing.T) {
+	pricing := usage.Pricing{
+		"gpt-4":       {PromptPer1K: 0.03, CompletionPer1K: 0.06},
+		"gpt-4-turbo": {PromptPer1K: 0.01, CompletionPer1K: 0.03},
+	}
+
+	assert.InDelta(t, 0.06, pricing.Cost("gpt-4", 1000, 500), 1e-9)
+	// The longest matching prefix wins
+	assert.InDelta(t, 0.025, pricing.Cost("gpt-4-turbo-preview", 1000, 500), 1e-9)
+	// Unpriced models, such as local ones, are free
+	assert.Zero(t, pricing.Cost("llama3", 1000, 500))
+}
+
+func TestLimitsCheckUser(t *testing.T) {
+	now := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	limits := usage.Limits{DailyTokens: 1000, MonthlyTokens: 5000}
+
+	assert.NoError(t, limits.CheckUser(999, 4999, now))
+
+	var quotaErr *usage.QuotaError
+	require.True(t, errors.As(limits.CheckUser(1000, 1000, now), &quotaErr))
+	assert.Equal(t, usage.ScopeDaily, quotaErr.Scope)
+	assert.Equal(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), quotaErr.ResetsAt)
+
+	require.True(t, errors.As(limits.CheckUser(10, 5000, now), &quotaErr))
+	assert.Equal(t, usage.ScopeMonthly, quotaErr.Scope)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), quotaErr.ResetsAt)
+
+	// Zero limits are unlimited
+	assert.NoError(t, usage.Limits{}.CheckUser(1_000_000, 1_000_000, now))
+}
+
+func TestLimitsCheckGlobal(t *testing.T) {
+	now := time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC)
+	limits := usage.Limits{GlobalMonthlyUSD: 50}
+
+	assert.NoError(t, limits.CheckGlobal(49.99, now))
+
+	var quotaErr *usage.QuotaError
+	require.True(t, errors.As(limits.CheckGlobal(50, now), &quotaErr))
+	assert.Equal(t, usage.ScopeGlobal, quotaErr.Scope)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), quotaErr.ResetsAt)
+}
+
+// recordingMeter is an llm.Meter that keeps what it records in memory
+type recordingMeter struct {
+	deny    error
+	callers []llm.Caller
+	usages  []llm.Usage
+	models  []string
+}
+
+func (m *recordingMeter) Allow(ctx context.Context, caller llm.Caller) error {
+	return m.deny
+}
+
+func (m *recordingMeter) Record(caller llm.Caller, provider, model string, u llm.Usage) {
+	m.callers = append(m.callers, caller)
+	m.usages = append(m.usages, u)
+	m.models = append(m.models, model)
+}
+
+func TestMeteredChat(t *testing.T) {
+	meter := &recordingMeter{}
+	provider := llm.NewMetered(llm.NewFake("one two three"), meter)
+
+	ctx := llm.WithCaller(context.Background(), 7, usage.FeatureEncouragement)
+	_, err := provider.Chat(ctx, llm.Request{Model: "gpt-4", Messages: []llm.Message{{Role: llm.RoleUser, Content: "hello there"}}})
+	require.NoError(t, err)
+
+	require.Len(t, meter.callers, 1)
+	assert.Equal(t, llm.Caller{UserID: 7, Feature: usage.FeatureEncouragement}, meter.callers[0])
+	assert.Equal(t, "gpt-4", meter.models[0])
+	assert.Equal(t, llm.Usage{PromptTokens: 2, CompletionTokens: 3, TotalTokens: 5}, meter.usages[0])
+}
+
+func TestMeteredChatDenied(t *testing.T) {
+	fake := llm.NewFake("never sent")
+	meter := &recordingMeter{deny: &usage.QuotaError{Scope: usage.ScopeDaily}}
+	provider := llm.NewMetered(fake, meter)
+
+	_, err := provider.Chat(context.Background(), llm.Request{})
+	var quotaErr *usage.QuotaError
+	assert.True(t, errors.As(err, &quotaErr))
+	assert.Empty(t, fake.Requests())
+	assert.Empty(t, meter.callers)
+}
+
+func TestMeteredStreamRecordsOnce(t *testing.T) {
+	meter := &recordingMeter{}
+	provider := llm.NewMetered(llm.NewFake("a streamed reply"), meter)
+
+	stream, err := provider.ChatStream(context.Background(), llm.Request{Messages: []llm.Message{{Role: llm.RoleUser, Content: "hi"}}})
+	require.NoError(t, err)
+	content, err := llm.ReadAll(stream)
+	require.NoError(t, err)
+
+	assert.Equal(t, "a streamed reply", content)
+	require.Len(t, meter.usages, 1)
+	assert.Equal(t, llm.EstimateTokens("a streamed reply"), meter.usages[0].CompletionTokens)
+	// The model the stream reports is recorded, not the empty requested one
+	assert.Equal(t, "fake", meter.models[0])
+}
+
+// defaultModelProvider has a default model but streams that don't report one
+type defaultModelProvider struct {
+	*llm.Fake
+}
+
+func (p defaultModelProvider) DefaultModel() string {
+	return "gpt-4o-mini"
+}
+
+func (p defaultModelProvider) ChatStream(ctx context.Context, req llm.Request) (llm.Stream, error) {
+	stream, err := p.Fake.ChatStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ llm.Stream }{stream}, nil
+}
+
+func TestMeteredStreamDefaultModel(t *testing.T) {
+	meter := &recordingMeter{}
+	breaker := llm.NewBreaker(defaultModelProvider{llm.NewFake("a reply")}, llm.BreakerOptions{})
+	provider := llm.NewMetered(breaker, meter)
+
+	stream, err := provider.ChatStream(context.Background(), llm.Request{})
+	require.NoError(t, err)
+	_, err = llm.ReadAll(stream)
+	require.NoError(t, err)
+
+	// Without a model from the stream or the request, the provider's default is recorded
+	require.Len(t, meter.models, 1)
+	assert.Equal(t, "gpt-4o-mini", meter.models[0])
+}
+
+func TestRespondIfQuotaExceeded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+
+	err := &usage.QuotaError{Scope: usage.ScopeDaily, ResetsAt: time.Now().Add(time.Hour)}
+	assert.True(t, usage.RespondIfQuotaExceeded(ctx, errors.Join(errors.New("wrapped"), err)))
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), `"scope":"daily"`)
+
+	assert.False(t, usage.RespondIfQuotaExceeded(ctx, errors.New("something else")))
+}
+
+func TestUsageEndpoints(t *testing.T) {
+	useFakeProvider(t, "Peace be with you.")
+	viper.Set("ai_usage.daily_token_limit", 50)
+	defer viper.Set("ai_usage.daily_token_limit", 20000)
+	router, db := setupEndpointTest(t, "ai_usage_records", "conversation_messages", "conversations")
+	_, token := createUserToken(t, db, "metered", "user")
+	_, otherToken := createUserToken(t, db, "unmetered", "user")
+
+	t.Run("Requires Authentication", func(t *testing.T) {
+		w := doRequest(router, "GET", "/api/ai/usage", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Nothing Used", func(t *testing.T) {
+		w := doRequest(router, "GET", "/api/ai/usage", token, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		summary := decodeJSON(t, w)
+		daily := summary["daily"].(map[string]interface{})
+		assert.Equal(t, float64(0), daily["used"])
+		assert.Equal(t, float64(50), daily["limit"])
+		assert.NotEmpty(t, daily["resets_at"])
+		monthly := summary["monthly"].(map[string]interface{})
+		assert.Equal(t, float64(0), monthly["used"])
+		assert.Equal(t, float64(300000), monthly["limit"])
+	})
+
+	w := doRequest(router, "POST", "/api/ai/conversations", token, map[string]interface{}{})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	messages := fmt.Sprintf("/api/ai/conversations/%v/messages", decodeJSON(t, w)["id"])
+
+	t.Run("Calls Are Metered By Feature", func(t *testing.T) {
+		w := doRequest(router, "POST", messages, token, map[string]string{"content": "Please pray for my exams"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		w = doRequest(router, "GET", "/api/ai/usage", token, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		summary := decodeJSON(t, w)
+		used := summary["daily"].(map[string]interface{})["used"].(float64)
+		assert.Greater(t, used, float64(0))
+		assert.Equal(t, used, summary["monthly"].(map[string]interface{})["used"])
+
+		thisMonth := summary["this_month"].([]interface{})
+		require.Len(t, thisMonth, 1)
+		feature := thisMonth[0].(map[string]interface{})
+		assert.Equal(t, usage.FeatureCompanion, feature["feature"])
+		assert.Equal(t, "fake", feature["model"])
+		assert.Equal(t, float64(1), feature["calls"])
+		assert.Equal(t, used, feature["prompt_tokens"].(float64)+feature["completion_tokens"].(float64))
+
+		// Each user only sees their own usage
+		w = doRequest(router, "GET", "/api/ai/usage", otherToken, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, float64(0), decodeJSON(t, w)["daily"].(map[string]interface{})["used"])
+	})
+
+	t.Run("Exhausted Budget", func(t *testing.T) {
+		w := doRequest(router, "GET", "/api/ai/usage", token, nil)
+		require.GreaterOrEqual(t, decodeJSON(t, w)["daily"].(map[string]interface{})["used"], float64(50))
+
+		w = doRequest(router, "POST", messages, token, map[string]string{"content": "And for my family"})
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		assert.Equal(t, usage.ScopeDaily, decodeJSON(t, w)["scope"])
+	})
+}
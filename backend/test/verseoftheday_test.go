@@ -0,0 +1,93 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"armourup/internal/domain/mood"
+	"armourup/internal/domain/verseoftheday"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiturgicalSeason(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	assert.Equal(t, date(2024, time.March, 31).Truncate(24*time.Hour), verseoftheday.EasterSunday(2024))
+	assert.Equal(t, date(2025, time.April, 20).Truncate(24*time.Hour), verseoftheday.EasterSunday(2025))
+
+	cases := []struct {
+		date   time.Time
+		season string
+	}{
+		{date(2024, time.December, 1), verseoftheday.SeasonAdvent},
+		{date(2024, time.November, 30), verseoftheday.SeasonOrdinary},
+		{date(2022, time.November, 27), verseoftheday.SeasonAdvent}, // Christmas on a Sunday
+		{date(2024, time.December, 25), verseoftheday.SeasonChristmas},
+		{date(2025, time.January, 5), verseoftheday.SeasonChristmas},
+		{date(2025, time.January, 6), verseoftheday.SeasonEpiphany},
+		{date(2025, time.March, 5), verseoftheday.SeasonLent}, // Ash Wednesday
+		{date(2025, time.April, 20), verseoftheday.SeasonEaster},
+		{date(2025, time.June, 8), verseoftheday.SeasonPentecost},
+		{date(2025, time.July, 14), verseoftheday.SeasonOrdinary},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.season, verseoftheday.LiturgicalSeason(c.date), c.date.Format("2006-01-02"))
+	}
+}
+
+func TestSelectVerse(t *testing.T) {
+	pool := []verseoftheday.PoolVerse{
+		{ID: 1, Reference: "Isaiah 26:3", Themes: "peace"},
+		{ID: 2, Reference: "Psalm 34:18", Themes: "comfort"},
+		{ID: 3, Reference: "Matthew 28:6", Themes: "joy", Seasons: "easter"},
+		{ID: 4, Reference: "Psalm 118:24", Themes: "joy"},
+	}
+
+	t.Run("Same Day Gives Same Verse", func(t *testing.T) {
+		a := verseoftheday.SelectVerse(pool, nil, verseoftheday.SeasonOrdinary, "", "2025-07-14:0")
+		b := verseoftheday.SelectVerse(pool, nil, verseoftheday.SeasonOrdinary, "", "2025-07-14:0")
+		assert.Equal(t, a.ID, b.ID)
+	})
+
+	t.Run("Seasonal Verses Only In Season", func(t *testing.T) {
+		for _, seed := range []string{"a", "b", "c", "d", "e", "f"} {
+			v := verseoftheday.SelectVerse(pool, nil, verseoftheday.SeasonOrdinary, "", seed)
+			assert.NotEqual(t, uint(3), v.ID)
+		}
+		v := verseoftheday.SelectVerse(pool, nil, verseoftheday.SeasonEaster, "", "a")
+		assert.Equal(t, uint(3), v.ID)
+	})
+
+	t.Run("Theme Is Preferred", func(t *testing.T) {
+		v := verseoftheday.SelectVerse(pool, nil, verseoftheday.SeasonOrdinary, "comfort", "x")
+		assert.Equal(t, uint(2), v.ID)
+	})
+
+	t.Run("Recent Verses Are Not Repeated", func(t *testing.T) {
+		recent := map[uint]bool{2: true}
+		v := verseoftheday.SelectVerse(pool, recent, verseoftheday.SeasonOrdinary, "comfort", "x")
+		assert.NotEqual(t, uint(2), v.ID)
+	})
+
+	t.Run("Window Is Relaxed When Everything Was Used", func(t *testing.T) {
+		recent := map[uint]bool{1: true, 2: true, 3: true, 4: true}
+		v := verseoftheday.SelectVerse(pool, recent, verseoftheday.SeasonOrdinary, "", "x")
+		assert.NotNil(t, v)
+	})
+}
+
+func TestThemeForMood(t *testing.T) {
+	assert.Equal(t, "", verseoftheday.ThemeForMood(&mood.MoodTrendStats{}))
+	assert.Equal(t, "strength", verseoftheday.ThemeForMood(&mood.MoodTrendStats{
+		TotalEntries: 3, AvgEnergyLevel: 2.5, EmotionalStates: map[string]int{"Happy": 3},
+	}))
+	assert.Equal(t, "peace", verseoftheday.ThemeForMood(&mood.MoodTrendStats{
+		TotalEntries: 3, AvgEnergyLevel: 6, EmotionalStates: map[string]int{"Anxious": 2, "Happy": 1},
+	}))
+	assert.Equal(t, "hope", verseoftheday.ThemeForMood(&mood.MoodTrendStats{
+		TotalEntries: 1, AvgEnergyLevel: 6, EmotionalStates: map[string]int{"Curious": 1},
+	}))
+}
@@ -15,7 +15,7 @@ export async function GET(request: Request) {
     }
 
     // Make request to backend API
-    const backendUrl = `${process.env.BACKEND_URL || 'http://localhost:8080'}/api/encourage`;
+    const backendUrl = `${process.env.BACKEND_URL || 'http://localhost:8080'}/api/encourage/struggles`;
     
     const response = await fetch(backendUrl, {
       method: 'GET',